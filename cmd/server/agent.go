@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"monitor/internal/agent"
+	"monitor/internal/config"
+	"monitor/internal/maintenance"
+	"monitor/internal/scheduler"
+)
+
+// runAgent 以探测节点模式运行：只执行探测并将结果上报到中心节点（不初始化存储、不提供 API）
+func runAgent(loader *config.Loader, configFile string, cfg *config.AppConfig) {
+	log.Printf("✅ 探测节点模式，地域: %s，中心节点: %s", cfg.Region, cfg.Agent.ServerURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pusher := agent.NewPusher(cfg.Agent)
+	pusher.Start(ctx)
+
+	interval := cfg.IntervalDuration
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	sched := scheduler.NewScheduler(pusher, interval)
+
+	// 探测节点只使用配置文件中的维护窗口（API 创建的窗口保存在中心节点）
+	calendar := maintenance.NewCalendar(nil)
+	calendar.UpdateConfig(cfg)
+	sched.SetMaintenance(calendar)
+	sched.Start(ctx, cfg)
+
+	// 配置热更新（监控项、调度与重试策略、维护窗口；agent 配置修改后需重启）
+	watcher, err := config.NewWatcher(loader, configFile, func(newCfg *config.AppConfig) {
+		calendar.UpdateConfig(newCfg)
+		sched.UpdateConfig(newCfg)
+		sched.TriggerNow()
+	})
+	if err != nil {
+		log.Printf("⚠️  配置监听器创建失败: %v (热更新功能不可用)", err)
+	} else if err := watcher.Start(ctx); err != nil {
+		log.Printf("⚠️  配置监听器启动失败: %v (热更新功能不可用)", err)
+	} else {
+		log.Printf("✅ 配置热更新已启用")
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+	log.Println("\n⚠️  收到关闭信号，正在优雅退出...")
+
+	cancel()
+	sched.Stop()
+
+	// 退出前上报剩余的探测结果
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer flushCancel()
+	if err := pusher.Flush(flushCtx); err != nil {
+		log.Printf("⚠️  退出前上报失败，丢弃 %d 条记录: %v", pusher.Pending(), err)
+	}
+
+	log.Println("👋 探测节点已安全退出")
+}
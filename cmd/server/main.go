@@ -8,10 +8,18 @@ import (
 	"syscall"
 	"time"
 
+	"monitor/internal/alert"
 	"monitor/internal/api"
 	"monitor/internal/buildinfo"
+	"monitor/internal/catalog"
 	"monitor/internal/config"
+	"monitor/internal/election"
+	"monitor/internal/incident"
+	"monitor/internal/maintenance"
+	"monitor/internal/metrics"
+	"monitor/internal/retention"
 	"monitor/internal/scheduler"
+	"monitor/internal/secret"
 	"monitor/internal/storage"
 )
 
@@ -43,6 +51,11 @@ func buildChannelMigrationMappings(monitors []config.ServiceConfig) []storage.Ch
 }
 
 func main() {
+	// 子命令：加密 API Key 等敏感值（不启动服务）
+	if len(os.Args) > 1 && os.Args[1] == encryptSecretCommand {
+		os.Exit(runEncryptSecret())
+	}
+
 	// 打印版本信息
 	log.Printf("🚀 Relay Pulse Monitor")
 	log.Printf("📦 Version: %s", buildinfo.GetVersion())
@@ -66,6 +79,12 @@ func main() {
 
 	log.Printf("✅ 已加载 %d 个监控任务", len(cfg.Monitors))
 
+	// 探测节点模式：只探测并上报到中心节点
+	if cfg.Agent.Enabled {
+		runAgent(loader, configFile, cfg)
+		return
+	}
+
 	// 初始化存储（支持 SQLite 和 PostgreSQL）
 	store, err := storage.New(&cfg.Storage)
 	if err != nil {
@@ -77,6 +96,26 @@ func main() {
 		log.Fatalf("❌ 初始化数据库失败: %v", err)
 	}
 
+	// 数据库中的监控项：表为空时导入配置文件中的 monitors，之后监控项只来自数据库
+	var monitorCatalog *catalog.Catalog
+	if cfg.MonitorStore.Enabled {
+		key, err := secret.KeyFromEnv()
+		if err != nil {
+			log.Fatalf("❌ 开启 monitor_store 需要主密钥: %v", err)
+		}
+		monitorCatalog = catalog.New(store, key, loader, configFile)
+		if n, err := monitorCatalog.Seed(); err != nil {
+			log.Fatalf("❌ 导入监控项失败: %v", err)
+		} else if n > 0 {
+			log.Printf("✅ 已将配置文件中的 %d 个监控项导入数据库", n)
+		}
+		loader.SetMonitorSource(monitorCatalog.Definitions)
+		if cfg, err = loader.Load(configFile); err != nil {
+			log.Fatalf("❌ 无法加载数据库中的监控项: %v", err)
+		}
+		log.Printf("✅ 已从数据库加载 %d 个监控任务", len(cfg.Monitors))
+	}
+
 	// 自动迁移旧数据的 channel
 	if err := store.MigrateChannelData(buildChannelMigrationMappings(cfg.Monitors)); err != nil {
 		log.Printf("⚠️ channel 数据迁移失败: %v", err)
@@ -98,16 +137,73 @@ func main() {
 		interval = time.Minute
 	}
 	sched := scheduler.NewScheduler(store, interval)
+
+	// 计划维护日历（配置文件中的窗口 + 通过 API 创建的窗口，定期重新加载以同步其他副本上的修改）
+	calendar := maintenance.NewCalendar(store)
+	calendar.UpdateConfig(cfg)
+	if err := calendar.Reload(); err != nil {
+		log.Printf("⚠️  加载维护窗口失败: %v", err)
+	}
+	calendar.Start(ctx, time.Minute)
+	sched.SetMaintenance(calendar)
+
+	// 告警管理器（根据探测结果推送状态变化通知）
+	alertMgr := alert.NewManager(cfg)
+	sched.AddObserver(alertMgr)
+
+	// 故障事件跟踪（根据探测结果自动开启/关闭）
+	tracker, err := incident.NewTracker(store)
+	if err != nil {
+		log.Fatalf("❌ 初始化故障事件跟踪失败: %v", err)
+	}
+	sched.AddObserver(tracker)
+
+	// Prometheus 指标收集器
+	collector := metrics.NewCollector()
+	collector.UpdateConfig(cfg)
+	sched.AddObserver(collector)
+	sched.SetMetrics(collector)
+
+	// 数据清理任务（启动时执行一次，之后按 retention.interval 定期执行）
+	cleaner := retention.NewCleaner(store)
+
+	// 多副本主节点选举：只有主节点执行探测和清理，所有副本都提供 API 服务
+	var elector *election.Elector
+	if cfg.Election.Enabled {
+		elector = election.NewElector(store, cfg.Election.ID, cfg.Election.LeaseTTLDuration)
+		elector.OnChange(func(leader bool) {
+			collector.SetLeader(leader)
+			if leader {
+				// 接续原主节点开启的故障事件
+				if err := tracker.Reload(); err != nil {
+					log.Printf("⚠️  %v", err)
+				}
+			}
+		})
+		collector.SetLeader(false)
+		sched.SetElector(elector)
+		cleaner.SetElector(elector)
+		elector.Start(ctx)
+		log.Printf("✅ 主节点选举已启用，副本标识: %s", elector.ID())
+	}
+
 	sched.Start(ctx, cfg)
+	cleaner.Start(ctx, cfg)
 
 	// 创建API服务器
-	server := api.NewServer(store, cfg, "8080")
+	server := api.NewServer(store, cfg, "8080", collector, calendar)
+	// 探测节点上报的结果同样通知告警、故障事件和指标
+	server.SetIngestObserver(sched)
 
 	// 启动配置监听器（热更新）
 	watcher, err := config.NewWatcher(loader, configFile, func(newCfg *config.AppConfig) {
 		// 配置热更新回调
+		calendar.UpdateConfig(newCfg)
 		sched.UpdateConfig(newCfg)
 		server.UpdateConfig(newCfg)
+		alertMgr.UpdateConfig(newCfg)
+		collector.UpdateConfig(newCfg)
+		cleaner.UpdateConfig(newCfg)
 		// 重新运行 channel 迁移（支持运行时添加 channel）
 		if err := store.MigrateChannelData(buildChannelMigrationMappings(newCfg.Monitors)); err != nil {
 			log.Printf("⚠️ 热更新时 channel 迁移失败: %v", err)
@@ -126,22 +222,23 @@ func main() {
 		}
 	}
 
-	// 启动定期清理任务（保留30天数据）
-	go func() {
-		ticker := time.NewTicker(24 * time.Hour)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				if err := store.CleanOldRecords(30); err != nil {
-					log.Printf("⚠️  清理旧记录失败: %v", err)
-				}
-			}
+	// 管理 API 的运行时操作
+	adminHooks := api.AdminHooks{Scheduler: sched, Cleanup: cleaner.RunOnce}
+	if watcher != nil {
+		adminHooks.Reload = watcher.Reload
+	}
+	if monitorCatalog != nil {
+		// 监控项修改经配置重载生效，并定期同步其他副本上的修改
+		if watcher != nil {
+			monitorCatalog.OnChange(watcher.Reload)
 		}
-	}()
+		monitorCatalog.Start(ctx, cfg.MonitorStore.SyncIntervalDuration)
+		adminHooks.Catalog = monitorCatalog
+	}
+	if elector != nil {
+		adminHooks.Leader = elector.Leader
+	}
+	server.SetAdmin(adminHooks)
 
 	// 监听中断信号（优雅关闭）
 	sigChan := make(chan os.Signal, 1)
@@ -167,6 +264,14 @@ func main() {
 	// 停止调度器
 	sched.Stop()
 
+	// 释放主节点租约，其他副本无需等待租约过期即可接管
+	if elector != nil {
+		elector.Release()
+	}
+
+	// 等待进行中的告警通知发送完成
+	alertMgr.Close(5 * time.Second)
+
 	// 停止HTTP服务器
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
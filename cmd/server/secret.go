@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"monitor/internal/secret"
+)
+
+// encryptSecretCommand 生成加密值的子命令名（monitor encrypt-secret < key.txt）
+const encryptSecretCommand = "encrypt-secret"
+
+// runEncryptSecret 用 MONITOR_MASTER_KEY 加密标准输入中的明文，输出可直接写入 api_key 的 enc:v1: 值
+func runEncryptSecret() int {
+	key, err := secret.KeyFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 读取标准输入失败: %v\n", err)
+		return 1
+	}
+	plaintext := strings.TrimSpace(string(data))
+	if plaintext == "" {
+		fmt.Fprintln(os.Stderr, "❌ 标准输入为空，请通过管道传入需要加密的值")
+		return 1
+	}
+
+	sealed, err := secret.Encrypt(key, plaintext)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Println(sealed)
+	return 0
+}
@@ -54,12 +54,24 @@ export function StatusCard({ item, timeRange, onBlockHover, onBlockLeave }: Stat
           </div>
         </div>
         <div className="flex flex-col items-end gap-1.5">
-          <div className="flex items-center gap-2 px-3 py-1 rounded-full bg-slate-800 border border-slate-700">
+          <div
+            className="flex items-center gap-2 px-3 py-1 rounded-full bg-slate-800 border border-slate-700"
+            title={item.maintenance?.active ? item.maintenance.reason : undefined}
+          >
             <StatusDot status={item.currentStatus} />
             <span className={`text-xs font-bold ${STATUS[item.currentStatus].text}`}>
               {STATUS[item.currentStatus].label}
             </span>
           </div>
+          {/* 最近的探测状态频繁变化 */}
+          {item.flapping && (
+            <span
+              className="px-2 py-0.5 rounded-full text-[10px] font-mono border border-fuchsia-500/30 text-fuchsia-300 bg-fuchsia-500/10"
+              title={`最近探测的状态变化频率 ${Math.round(item.flappingScore * 100)}%`}
+            >
+              震荡
+            </span>
+          )}
           {item.lastCheckTimestamp && (
             <div className="text-[10px] text-slate-500 font-mono flex flex-col items-end gap-0.5">
               <span>{new Date(item.lastCheckTimestamp * 1000).toLocaleString('zh-CN', { month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit' })}</span>
@@ -68,6 +80,21 @@ export function StatusCard({ item, timeRange, onBlockHover, onBlockLeave }: Stat
               )}
             </div>
           )}
+          {/* 多地域探测时展示各地域最新状态 */}
+          {item.regions.length > 1 && (
+            <div className="flex flex-wrap justify-end gap-1.5 text-[10px] font-mono">
+              {item.regions.map((r) => (
+                <span
+                  key={r.region}
+                  className={`flex items-center gap-1 ${STATUS[r.status].text}`}
+                  title={`${r.region} · ${r.latency}ms`}
+                >
+                  <StatusDot status={r.status} size="sm" />
+                  {r.region}
+                </span>
+              ))}
+            </div>
+          )}
         </div>
       </div>
 
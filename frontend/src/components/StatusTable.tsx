@@ -142,11 +142,19 @@ export function StatusTable({
                 {item.channel || '-'}
               </td>
               <td className="p-4">
-                <div className="flex items-center gap-2">
+                <div className="flex items-center gap-2" title={item.maintenance?.active ? item.maintenance.reason : undefined}>
                   <StatusDot status={item.currentStatus} size="sm" />
                   <span className={STATUS[item.currentStatus].text}>
                     {STATUS[item.currentStatus].label}
                   </span>
+                  {item.flapping && (
+                    <span
+                      className="px-1.5 py-0.5 rounded text-[10px] border border-fuchsia-500/30 text-fuchsia-300 bg-fuchsia-500/10"
+                      title={`最近探测的状态变化频率 ${Math.round(item.flappingScore * 100)}%`}
+                    >
+                      震荡
+                    </span>
+                  )}
                 </div>
               </td>
               <td className="p-4 font-mono font-bold">
@@ -14,6 +14,7 @@ export function Tooltip({ tooltip }: TooltipProps) {
     degraded: 0,
     unavailable: 0,
     missing: 0,
+    maintenance: 0,
     slow_latency: 0,
     rate_limit: 0,
     server_error: 0,
@@ -29,7 +30,15 @@ export function Tooltip({ tooltip }: TooltipProps) {
     { key: 'available', emoji: '🟢', label: '可用', value: counts.available },
     { key: 'degraded', emoji: '🟡', label: '波动', value: counts.degraded },
     { key: 'unavailable', emoji: '🔴', label: '不可用', value: counts.unavailable },
-  ];
+    { key: 'maintenance', emoji: '🔧', label: '维护中', value: counts.maintenance },
+  ].filter(item => item.key !== 'maintenance' || item.value > 0);
+
+  // 维护标注（同一时段只显示一次）
+  const maintenanceNotes = (tooltip.data.maintenance ?? []).filter(
+    (note, i, notes) => notes.findIndex(n => n.start === note.start && n.reason === note.reason) === i
+  );
+  const formatTime = (ts: number) =>
+    new Date(ts * 1000).toLocaleString('zh-CN', { month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit' });
 
   // 黄色波动细分
   const degradedSubstatus = [
@@ -47,6 +56,19 @@ export function Tooltip({ tooltip }: TooltipProps) {
     { key: 'content_mismatch', label: '内容校验失败', value: counts.content_mismatch },
   ].filter(item => item.value > 0);
 
+  // 分阶段耗时（复用连接时 DNS/连接/TLS 为 0，不显示）
+  const phases = tooltip.data.phases;
+  const phaseItems = phases
+    ? [
+        { key: 'dns', label: 'DNS 解析', value: phases.dns },
+        { key: 'connect', label: 'TCP 连接', value: phases.connect },
+        { key: 'tls', label: 'TLS 握手', value: phases.tls },
+        { key: 'firstByte', label: '首字节', value: phases.firstByte },
+        { key: 'firstToken', label: '首 token', value: phases.firstToken },
+        { key: 'bodyRead', label: '读取响应体', value: phases.bodyRead },
+      ].filter(item => item.value > 0)
+    : [];
+
   return (
     <div
       className="fixed z-50 pointer-events-none transition-opacity duration-200"
@@ -112,6 +134,32 @@ export function Tooltip({ tooltip }: TooltipProps) {
           </div>
         )}
 
+        {/* 计划维护 */}
+        {maintenanceNotes.length > 0 && (
+          <div className="flex flex-col gap-1 pt-2 border-t border-slate-700/50">
+            <div className="text-[10px] text-sky-400 mb-0.5">🔧 计划维护（不计入可用率）</div>
+            {maintenanceNotes.map((note) => (
+              <div key={`${note.start}-${note.reason}`} className="text-[10px] pl-2 text-slate-400">
+                • {formatTime(note.start)} - {formatTime(note.end)}
+                {note.reason && <span className="text-slate-200"> {note.reason}</span>}
+              </div>
+            ))}
+          </div>
+        )}
+
+        {/* 分阶段耗时 */}
+        {phaseItems.length > 0 && (
+          <div className="flex flex-col gap-1 pt-2 border-t border-slate-700/50">
+            <div className="text-[10px] text-slate-400 mb-0.5">⏱ 耗时分解（平均）</div>
+            {phaseItems.map((item) => (
+              <div key={item.key} className="flex justify-between items-center gap-3 text-[10px] pl-2">
+                <span className="text-slate-400">• {item.label}</span>
+                <span className="text-slate-200 tabular-nums">{item.value}ms</span>
+              </div>
+            ))}
+          </div>
+        )}
+
         {/* 小三角箭头 */}
         <div className="absolute -bottom-1.5 left-1/2 -translate-x-1/2 w-3 h-3 bg-slate-900 border-r border-b border-slate-700 transform rotate-45"></div>
       </div>
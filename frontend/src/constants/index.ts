@@ -31,6 +31,13 @@ export const STATUS: Record<string, StatusConfig> = {
     label: '波动',
     weight: 2,
   },
+  MAINTENANCE: {
+    color: 'bg-sky-500',
+    text: 'text-sky-400',
+    glow: 'shadow-[0_0_10px_rgba(14,165,233,0.6)]',
+    label: '维护中',
+    weight: 2,
+  },
   MISSING: {
     color: 'bg-slate-400',
     text: 'text-slate-400',
@@ -5,6 +5,8 @@ import type {
   SortConfig,
   StatusKey,
   StatusCounts,
+  TimePoint,
+  PhaseTimings,
 } from '../types';
 import { API_BASE_URL, STATUS, USE_MOCK_DATA } from '../constants';
 import { fetchMockMonitorData } from '../utils/mockMonitor';
@@ -28,6 +30,7 @@ const statusMap: Record<number, StatusKey> = {
   2: 'DEGRADED',
   0: 'UNAVAILABLE',
   3: 'MISSING',  // 未配置/认证失败
+  4: 'MAINTENANCE',  // 计划维护
   '-1': 'MISSING',  // 缺失数据
 };
 
@@ -37,6 +40,7 @@ const mapStatusCounts = (counts?: StatusCounts): StatusCounts => ({
   degraded: counts?.degraded ?? 0,
   unavailable: counts?.unavailable ?? 0,
   missing: counts?.missing ?? 0,
+  maintenance: counts?.maintenance ?? 0,
   slow_latency: counts?.slow_latency ?? 0,
   rate_limit: counts?.rate_limit ?? 0,
   server_error: counts?.server_error ?? 0,
@@ -47,6 +51,16 @@ const mapStatusCounts = (counts?: StatusCounts): StatusCounts => ({
   content_mismatch: counts?.content_mismatch ?? 0,
 });
 
+// 映射分阶段耗时，缺失字段按 0（无数据）处理
+const mapPhaseTimings = (point: TimePoint): PhaseTimings => ({
+  dns: point.dns_duration ?? 0,
+  connect: point.connect_duration ?? 0,
+  tls: point.tls_duration ?? 0,
+  firstByte: point.first_byte_latency ?? 0,
+  firstToken: point.first_token_latency ?? 0,
+  bodyRead: point.body_read_duration ?? 0,
+});
+
 interface UseMonitorDataOptions {
   timeRange: string;
   filterService: string;
@@ -115,11 +129,16 @@ export function useMonitorData({
               latency: point.latency,
               availability: point.availability,  // 可用率百分比
               statusCounts: mapStatusCounts(point.status_counts), // 映射状态计数
+              phases: mapPhaseTimings(point),
+              maintenance: point.maintenance ?? [],
             }));
 
-            const currentStatus = item.current_status
-              ? statusMap[item.current_status.status] || 'UNAVAILABLE'
-              : 'UNAVAILABLE';
+            // 处于维护窗口时（含跳过探测的窗口）优先显示维护中
+            const currentStatus: StatusKey = item.maintenance?.active
+              ? 'MAINTENANCE'
+              : item.current_status
+                ? statusMap[item.current_status.status] || 'UNAVAILABLE'
+                : 'UNAVAILABLE';
 
             // 计算可用率（取每个块的 availability 平均值）
             // 负数（无数据）当作100%可用，避免刚开始监控时可用率过低
@@ -146,6 +165,15 @@ export function useMonitorData({
               uptime,
               lastCheckTimestamp: item.current_status?.timestamp,
               lastCheckLatency: item.current_status?.latency,
+              maintenance: item.maintenance ?? null,
+              flapping: item.flapping ?? false,
+              flappingScore: item.flapping_score ?? 0,
+              regions: (item.regions || []).map((r) => ({
+                region: r.region,
+                status: statusMap[r.status] || 'UNAVAILABLE',
+                latency: r.latency,
+                timestamp: r.timestamp,
+              })),
             };
           });
         }
@@ -2,10 +2,37 @@
 export interface TimePoint {
   time: string;         // 格式化时间标签（如 "15:04" 或 "2006-01-02"）
   timestamp: number;    // Unix 时间戳（秒）
-  status: number;       // 1=可用, 0=不可用, 2=波动, -1=缺失（bucket内最后一条）
+  status: number;       // 1=可用, 0=不可用, 2=波动, 4=维护中, -1=缺失（bucket内最后一条）
   latency: number;      // 平均延迟(ms)
   availability: number; // 可用率百分比(0-100)，缺失时为 -1
   status_counts?: StatusCounts; // 各状态计数（可选，向后兼容）
+
+  // 平均分阶段耗时(ms)，无数据时为 0（可选，向后兼容）
+  first_byte_latency?: number;  // 首字节
+  first_token_latency?: number; // 首 token（仅流式探测）
+  dns_duration?: number;        // DNS 解析
+  connect_duration?: number;    // TCP 连接
+  tls_duration?: number;        // TLS 握手
+  body_read_duration?: number;  // 读取响应体
+
+  maintenance?: MaintenanceNote[]; // 与该 bucket 有交集的维护时段（可选）
+}
+
+// 时间轴上的维护标注
+export interface MaintenanceNote {
+  reason: string;
+  start: number; // 本次维护开始时间（Unix 秒）
+  end: number;   // 本次维护结束时间（Unix 秒）
+}
+
+// 分阶段耗时（ms），0 表示无数据
+export interface PhaseTimings {
+  dns: number;
+  connect: number;
+  tls: number;
+  firstByte: number;
+  firstToken: number;
+  bodyRead: number;
 }
 
 export interface StatusCounts {
@@ -13,6 +40,7 @@ export interface StatusCounts {
   degraded: number;    // 黄色（波动/降级）次数
   unavailable: number; // 红色（不可用）次数
   missing: number;     // 灰色（无数据/未配置）次数
+  maintenance: number; // 维护中次数（不计入可用率）
 
   // 黄色波动细分
   slow_latency: number; // 响应慢次数
@@ -28,11 +56,44 @@ export interface StatusCounts {
 }
 
 export interface CurrentStatus {
+  status: number;       // 当前状态（开启滞回时为稳定状态）
+  raw_status?: number;  // 最新一次探测的状态
+  latency: number;
+  timestamp: number;
+  first_byte_latency?: number;  // 分阶段耗时(ms)，0 表示未发生（如复用连接）
+  first_token_latency?: number;
+  dns_duration?: number;
+  connect_duration?: number;
+  tls_duration?: number;
+  body_read_duration?: number;
+}
+
+// 单个探测地域的最新状态
+export interface RegionStatus {
+  region: string;
   status: number;
+  sub_status: string;
   latency: number;
   timestamp: number;
 }
 
+// 监控项当前所处的维护窗口
+export interface MaintenanceStatus {
+  id: number;                 // API 创建的窗口 ID（配置文件中的窗口为 0）
+  source: 'config' | 'api';
+  provider: string;
+  service: string;
+  channel: string;
+  schedule?: string;          // 周期性窗口的 cron 表达式
+  duration?: string;          // 周期性窗口每次的持续时间
+  timezone?: string;
+  mode: 'record' | 'skip';
+  reason: string;
+  active: boolean;
+  start: number;              // 本次维护开始时间（Unix 秒）
+  end: number;                // 本次维护结束时间（Unix 秒）
+}
+
 export interface MonitorResult {
   provider: string;
   provider_url?: string;               // 服务商官网链接
@@ -42,6 +103,10 @@ export interface MonitorResult {
   sponsor_url?: string;                // 赞助者链接
   channel: string;                     // 业务通道标识
   current_status: CurrentStatus | null;
+  regions?: RegionStatus[];            // 各探测地域的最新状态
+  maintenance?: MaintenanceStatus | null; // 当前所处的维护窗口
+  flapping?: boolean;                  // 最近的探测状态是否频繁变化
+  flapping_score?: number;             // 状态变化频率（0-1）
   timeline: TimePoint[];
 }
 
@@ -54,7 +119,7 @@ export interface ApiResponse {
 }
 
 // 前端状态枚举
-export type StatusKey = 'AVAILABLE' | 'DEGRADED' | 'UNAVAILABLE' | 'MISSING';
+export type StatusKey = 'AVAILABLE' | 'DEGRADED' | 'UNAVAILABLE' | 'MAINTENANCE' | 'MISSING';
 
 export interface StatusConfig {
   color: string;
@@ -68,6 +133,7 @@ export const STATUS_MAP: Record<number, StatusKey> = {
   1: 'AVAILABLE',
   2: 'DEGRADED',
   0: 'UNAVAILABLE',
+  4: 'MAINTENANCE',
   '-1': 'MISSING',  // 缺失数据
 };
 
@@ -90,11 +156,22 @@ export interface ProcessedMonitorData {
     latency: number;
     availability: number;     // 可用率百分比(0-100)，缺失时为 -1
     statusCounts: StatusCounts; // 各状态计数
+    phases: PhaseTimings;       // 分阶段耗时
+    maintenance: MaintenanceNote[]; // 维护标注
   }>;
   currentStatus: StatusKey;
   uptime: number;             // 可用率百分比
   lastCheckTimestamp?: number; // 最后检测时间（Unix 时间戳，秒）
   lastCheckLatency?: number;   // 最后检测延迟（毫秒）
+  maintenance?: MaintenanceStatus | null; // 当前所处的维护窗口
+  flapping: boolean;           // 最近的探测状态是否频繁变化
+  flappingScore: number;       // 状态变化频率（0-1）
+  regions: Array<{             // 各探测地域的最新状态
+    region: string;
+    status: StatusKey;
+    latency: number;
+    timestamp: number;
+  }>;
 }
 
 // 时间范围配置
@@ -131,6 +208,8 @@ export interface TooltipState {
     latency: number;
     availability: number;  // 可用率百分比(0-100)，缺失时为 -1
     statusCounts: StatusCounts; // 各状态计数
+    phases?: PhaseTimings;      // 分阶段耗时
+    maintenance?: MaintenanceNote[]; // 维护标注
   } | null;
 }
 
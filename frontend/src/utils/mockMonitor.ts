@@ -55,6 +55,7 @@ export function fetchMockMonitorData(timeRangeId: string): Promise<ProcessedMoni
               degraded: statusKey === 'DEGRADED' ? 1 : 0,
               unavailable: statusKey === 'UNAVAILABLE' ? 1 : 0,
               missing: statusKey === 'MISSING' ? 1 : 0,
+              maintenance: 0,
               slow_latency: 0,
               rate_limit: 0,
               server_error: 0,
@@ -93,6 +94,15 @@ export function fetchMockMonitorData(timeRangeId: string): Promise<ProcessedMoni
               latency,
               availability,
               statusCounts,
+              phases: {
+                dns: Math.random() > 0.7 ? Math.floor(Math.random() * 30) + 1 : 0,
+                connect: Math.floor(Math.random() * 40) + 10,
+                tls: Math.floor(Math.random() * 80) + 20,
+                firstByte: Math.floor(latency * 0.6),
+                firstToken: 0,
+                bodyRead: Math.floor(latency * 0.2),
+              },
+              maintenance: [],
             };
           });
 
@@ -134,7 +144,12 @@ export function fetchMockMonitorData(timeRangeId: string): Promise<ProcessedMoni
             currentStatus,
             uptime,
             lastCheckTimestamp,
-            lastCheckLatency
+            lastCheckLatency,
+            flapping: false,
+            flappingScore: 0,
+            regions: [
+              { region: 'local', status: currentStatus, latency: lastCheckLatency, timestamp: lastCheckTimestamp },
+            ],
           });
         });
       });
@@ -0,0 +1,253 @@
+// Package agent 探测节点：将本地探测结果缓存并批量上报到中心节点
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// IngestPath 中心节点接收上报的路径
+const IngestPath = "/api/ingest"
+
+// MaxBatchSize 单次上报的最大记录数（中心节点拒绝超过该数量的请求）
+const MaxBatchSize = 500
+
+// Record 上报的探测记录（地域由中心节点根据凭证确定，不在记录中传递）
+type Record struct {
+	Provider  string            `json:"provider"`
+	Service   string            `json:"service"`
+	Channel   string            `json:"channel"`
+	Status    int               `json:"status"`
+	SubStatus storage.SubStatus `json:"sub_status"`
+	Latency   int               `json:"latency"`
+	Timestamp int64             `json:"timestamp"`
+	Attempts  int               `json:"attempts"`
+
+	FirstByteLatency  int `json:"first_byte_latency"`
+	FirstTokenLatency int `json:"first_token_latency"`
+	StreamDuration    int `json:"stream_duration"`
+
+	DNSDuration      int `json:"dns_duration"`
+	ConnectDuration  int `json:"connect_duration"`
+	TLSDuration      int `json:"tls_duration"`
+	BodyReadDuration int `json:"body_read_duration"`
+
+	HTTPStatus      int    `json:"http_status"`
+	ErrorMessage    string `json:"error_message"`
+	ResponseSnippet string `json:"response_snippet"`
+}
+
+// Batch 一次上报的请求体
+type Batch struct {
+	Records []Record `json:"records"`
+}
+
+// IngestResult 中心节点的上报响应
+type IngestResult struct {
+	Accepted int `json:"accepted"` // 已保存的记录数
+	Rejected int `json:"rejected"` // 因监控项未配置或数据无效被丢弃的记录数
+}
+
+// NewRecord 由探测记录生成上报记录
+func NewRecord(r *storage.ProbeRecord) Record {
+	return Record{
+		Provider:          r.Provider,
+		Service:           r.Service,
+		Channel:           r.Channel,
+		Status:            r.Status,
+		SubStatus:         r.SubStatus,
+		Latency:           r.Latency,
+		Timestamp:         r.Timestamp,
+		Attempts:          r.Attempts,
+		FirstByteLatency:  r.FirstByteLatency,
+		FirstTokenLatency: r.FirstTokenLatency,
+		StreamDuration:    r.StreamDuration,
+		DNSDuration:       r.DNSDuration,
+		ConnectDuration:   r.ConnectDuration,
+		TLSDuration:       r.TLSDuration,
+		BodyReadDuration:  r.BodyReadDuration,
+		HTTPStatus:        r.HTTPStatus,
+		ErrorMessage:      r.ErrorMessage,
+		ResponseSnippet:   r.ResponseSnippet,
+	}
+}
+
+// ProbeRecord 转换为指定地域的探测记录
+func (r Record) ProbeRecord(region string) *storage.ProbeRecord {
+	return &storage.ProbeRecord{
+		Provider:          r.Provider,
+		Service:           r.Service,
+		Channel:           r.Channel,
+		Status:            r.Status,
+		SubStatus:         r.SubStatus,
+		Latency:           r.Latency,
+		Timestamp:         r.Timestamp,
+		Attempts:          r.Attempts,
+		FirstByteLatency:  r.FirstByteLatency,
+		FirstTokenLatency: r.FirstTokenLatency,
+		StreamDuration:    r.StreamDuration,
+		DNSDuration:       r.DNSDuration,
+		ConnectDuration:   r.ConnectDuration,
+		TLSDuration:       r.TLSDuration,
+		BodyReadDuration:  r.BodyReadDuration,
+		HTTPStatus:        r.HTTPStatus,
+		ErrorMessage:      r.ErrorMessage,
+		ResponseSnippet:   r.ResponseSnippet,
+		Region:            region,
+	}
+}
+
+// Pusher 探测结果上报器（实现 monitor.ResultSink）
+// 探测结果先写入内存缓存，按 flush_interval 批量上报；中心节点不可用时保留在缓存中，下次继续上报
+type Pusher struct {
+	endpoint string
+	token    string
+	interval time.Duration
+	client   *http.Client
+
+	mu       sync.Mutex
+	buffer   []Record
+	head     uint64 // buffer[0] 的序号（累计从缓存头部移除的记录数，包括已上报和被丢弃的）
+	capacity int
+	dropped  int // 缓存已满时丢弃的记录数（下次上报成功时输出日志后清零）
+
+	// 串行化上报（定时上报与退出前的最后一次上报不会并发发送同一批记录）
+	flushMu sync.Mutex
+}
+
+// NewPusher 创建上报器
+func NewPusher(cfg config.AgentConfig) *Pusher {
+	return &Pusher{
+		endpoint: cfg.ServerURL + IngestPath,
+		token:    cfg.Token,
+		interval: cfg.FlushIntervalDuration,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		capacity: cfg.BufferSize,
+	}
+}
+
+// SaveRecord 缓存一条探测记录（缓存已满时丢弃最早的记录）
+func (p *Pusher) SaveRecord(record *storage.ProbeRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if over := len(p.buffer) + 1 - p.capacity; over > 0 {
+		p.buffer = p.buffer[over:]
+		p.head += uint64(over)
+		p.dropped += over
+	}
+	p.buffer = append(p.buffer, NewRecord(record))
+	return nil
+}
+
+// SaveCertificate 证书信息不上报（中心节点只展示自身观察到的证书）
+func (p *Pusher) SaveCertificate(*storage.Certificate) error {
+	return nil
+}
+
+// Pending 尚未上报的记录数
+func (p *Pusher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.buffer)
+}
+
+// Start 启动定时上报循环，直到 ctx 取消
+func (p *Pusher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Flush(ctx); err != nil {
+					log.Printf("⚠️  [Agent] 上报失败（%d 条记录待重试）: %v", p.Pending(), err)
+				}
+			}
+		}
+	}()
+	log.Printf("[Agent] 探测结果将每 %v 上报到 %s", p.interval, p.endpoint)
+}
+
+// Flush 上报缓存中的全部记录（分批发送，遇到失败时停止，未发送的记录保留在缓存中）
+func (p *Pusher) Flush(ctx context.Context) error {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	for {
+		p.mu.Lock()
+		batch := append([]Record(nil), p.buffer[:min(len(p.buffer), MaxBatchSize)]...)
+		end := p.head + uint64(len(batch))
+		p.mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		result, err := p.send(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		// 发送期间缓存满时最早的记录可能已被丢弃，只移除仍在缓存中的已发送记录
+		if end > p.head {
+			p.buffer = p.buffer[end-p.head:]
+			p.head = end
+		}
+		dropped := p.dropped
+		p.dropped = 0
+		p.mu.Unlock()
+
+		if result.Rejected > 0 {
+			log.Printf("⚠️  [Agent] 中心节点拒绝了 %d 条记录（监控项未在中心节点配置或数据无效）", result.Rejected)
+		}
+		if dropped > 0 {
+			log.Printf("⚠️  [Agent] 中心节点不可用期间缓存已满，丢弃了 %d 条最早的记录", dropped)
+		}
+	}
+}
+
+// send 发送一批记录
+func (p *Pusher) send(ctx context.Context, records []Record) (*IngestResult, error) {
+	body, err := json.Marshal(Batch{Records: records})
+	if err != nil {
+		return nil, fmt.Errorf("序列化上报数据失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建上报请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送上报请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("中心节点返回 HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var result IngestResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析上报响应失败: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+func TestPusherRetriesAndDropsOldest(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	var received []int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != IngestPath || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if !healthy.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var batch Batch
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, rec := range batch.Records {
+			received = append(received, rec.Timestamp)
+		}
+		json.NewEncoder(w).Encode(IngestResult{Accepted: len(batch.Records)})
+	}))
+	defer srv.Close()
+
+	p := NewPusher(config.AgentConfig{ServerURL: srv.URL, Token: "token", BufferSize: 3})
+	for ts := int64(1); ts <= 2; ts++ {
+		p.SaveRecord(&storage.ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Timestamp: ts})
+	}
+
+	// 中心节点不可用：记录保留在缓存中
+	if err := p.Flush(t.Context()); err == nil {
+		t.Fatalf("expected flush to fail while the server is unavailable")
+	}
+	if p.Pending() != 2 {
+		t.Fatalf("expected 2 pending records, got %d", p.Pending())
+	}
+
+	// 缓存已满时丢弃最早的记录
+	for ts := int64(3); ts <= 4; ts++ {
+		p.SaveRecord(&storage.ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Timestamp: ts})
+	}
+
+	healthy.Store(true)
+	if err := p.Flush(t.Context()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if p.Pending() != 0 || len(received) != 3 || received[0] != 2 || received[2] != 4 {
+		t.Fatalf("unexpected delivery: pending=%d received=%v", p.Pending(), received)
+	}
+}
@@ -0,0 +1,197 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+func newTestManager(t *testing.T, alerting config.AlertingConfig) *Manager {
+	t.Helper()
+
+	alerting.Enabled = true
+	if err := alerting.Normalize(); err != nil {
+		t.Fatalf("normalize alerting config: %v", err)
+	}
+	m := NewManager(&config.AppConfig{DegradedWeight: 0.7, Alerting: alerting})
+	t.Cleanup(func() { m.Close(time.Second) })
+	return m
+}
+
+func probe(status int, ts int64) *monitor.ProbeResult {
+	sub := storage.SubStatusNone
+	if status == 0 {
+		sub = storage.SubStatusServerError
+	}
+	return &monitor.ProbeResult{Provider: "demo", Service: "cc", Status: status, SubStatus: sub, Timestamp: ts}
+}
+
+func TestEvaluateDownAndRecovered(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager(t, config.AlertingConfig{ConsecutiveFailures: 2})
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+
+	var got []string
+	for i, status := range []int{1, 0, 0, 0, 1} {
+		for _, e := range m.evaluate(cfg, probe(status, int64(i))) {
+			got = append(got, e.Type)
+		}
+	}
+
+	want := []string{config.AlertEventDown, config.AlertEventRecovered}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+}
+
+func TestUpdateConfigPrunesRemovedMonitors(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager(t, config.AlertingConfig{ConsecutiveFailures: 2})
+	kept := config.ServiceConfig{Provider: "demo", Service: "cc"}
+	removed := &config.ServiceConfig{Provider: "demo", Service: "old"}
+
+	regional := probe(0, 1)
+	regional.Region = "eu"
+	m.evaluate(&kept, probe(0, 1))
+	m.evaluate(&kept, regional)
+	m.evaluate(removed, probe(0, 1))
+
+	m.UpdateConfig(&config.AppConfig{Alerting: m.cfg, Monitors: []config.ServiceConfig{kept}})
+	if len(m.states) != 2 || m.states["demo/cc/"] == nil || m.states["demo/cc/@eu"] == nil {
+		t.Fatalf("expected only states of remaining monitors, got %v", m.states)
+	}
+
+	m.UpdateConfig(&config.AppConfig{Alerting: m.cfg})
+	if len(m.states) != 0 {
+		t.Fatalf("expected all states to be pruned, got %v", m.states)
+	}
+}
+
+func TestEvaluateAvailabilityThreshold(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager(t, config.AlertingConfig{
+		ConsecutiveFailures:    100,
+		AvailabilityThreshold:  80,
+		AvailabilityMinSamples: 4,
+		AvailabilityWindow:     "1h",
+	})
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+
+	var got []string
+	for i, status := range []int{1, 0, 1, 0, 1, 1, 1, 1, 1, 1} {
+		for _, e := range m.evaluate(cfg, probe(status, int64(i*60))) {
+			got = append(got, e.Type)
+		}
+	}
+
+	want := []string{config.AlertEventAvailabilityLow, config.AlertEventAvailabilityRecovered}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+}
+
+func TestEvaluateSuppressesFlapping(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager(t, config.AlertingConfig{ConsecutiveFailures: 1})
+	m.flapping = config.FlappingConfig{Window: 6, Threshold: 0.5, Hysteresis: 1}
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+
+	var got []*Event
+	for i, status := range []int{1, 0, 1, 0, 1, 0, 1, 1, 1, 1, 1, 1} {
+		got = append(got, m.evaluate(cfg, probe(status, int64(i*60)))...)
+	}
+
+	want := []string{
+		config.AlertEventDown, config.AlertEventRecovered, config.AlertEventDown,
+		config.AlertEventFlapping, config.AlertEventFlappingStopped,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(got))
+	}
+	for i, e := range got {
+		if e.Type != want[i] {
+			t.Fatalf("event %d: expected %s, got %s", i, want[i], e.Type)
+		}
+	}
+	if stopped := got[len(got)-1]; stopped.State != StateOK || stopped.FlappingScore >= 0.5 {
+		t.Fatalf("unexpected flapping_stopped event: state=%s score=%.2f", stopped.State, stopped.FlappingScore)
+	}
+}
+
+func TestWebhookDeliveryWithRetry(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	var mu sync.Mutex
+	var received Event
+	done := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		mu.Lock()
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		close(done)
+	}))
+	defer srv.Close()
+
+	m := newTestManager(t, config.AlertingConfig{
+		ConsecutiveFailures: 1,
+		Webhooks: []config.WebhookConfig{
+			{Name: "test", URL: srv.URL, RetryBackoff: "10ms"},
+		},
+	})
+
+	m.OnProbeResult(&config.ServiceConfig{Provider: "demo", Service: "cc"}, probe(0, time.Now().Unix()))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook was not delivered")
+	}
+
+	if attempts.Load() != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts.Load())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if received.Type != config.AlertEventDown || received.Provider != "demo" {
+		t.Fatalf("unexpected payload: %+v", received)
+	}
+}
+
+func TestWebhookDoesNotRetryClientError(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	w := config.WebhookConfig{Name: "test", URL: srv.URL}
+	n := NewWebhookNotifier(w, srv.Client())
+	if err := sendWithRetry(t.Context(), n, &Event{Type: config.AlertEventDown}, 3, time.Millisecond); err == nil {
+		t.Fatalf("expected error for 401 response")
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected no retry on 4xx, got %d attempts", attempts.Load())
+	}
+}
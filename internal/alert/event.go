@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// 告警状态
+const (
+	StateOK       = "ok"       // 正常
+	StateDegraded = "degraded" // 降级（连续黄色）
+	StateDown     = "down"     // 不可用（连续红色）
+)
+
+// Event 告警事件（即 Webhook 推送的 JSON 负载）
+type Event struct {
+	Type          string  `json:"event"`          // down / degraded / recovered / availability_low / availability_recovered / flapping / flapping_stopped
+	Provider      string  `json:"provider"`       // 服务商
+	Service       string  `json:"service"`        // 服务类型
+	Channel       string  `json:"channel"`        // 业务通道
+	Category      string  `json:"category"`       // 分类
+	PreviousState string  `json:"previous_state"` // 事件前的告警状态
+	State         string  `json:"state"`          // 事件后的告警状态
+	Status        int     `json:"status"`         // 触发事件的探测状态（1=绿, 0=红, 2=黄）
+	SubStatus     string  `json:"sub_status"`     // 触发事件的细分状态
+	Latency       int     `json:"latency"`        // 触发事件的探测延迟（毫秒）
+	Consecutive   int     `json:"consecutive"`    // 连续次数（失败/降级/恢复）
+	Availability  float64 `json:"availability"`   // 统计窗口内的可用率（0-100），样本不足时为 -1
+	Error         string  `json:"error,omitempty"`
+	Timestamp     int64   `json:"timestamp"` // 探测时间（Unix 秒）
+	Message       string  `json:"message"`   // 可读的告警摘要
+
+	FlappingScore float64 `json:"flapping_score,omitempty"` // 最近探测的状态变化频率（0-1，仅 flapping / flapping_stopped 事件）
+
+	Region string `json:"region,omitempty"` // 探测节点上报结果的地域（本地探测为空）
+
+	HTTPStatus   int    `json:"http_status,omitempty"`   // 触发事件的 HTTP 状态码（仅非绿色探测，未收到响应时为 0）
+	DashboardURL string `json:"dashboard_url,omitempty"` // 监控面板地址
+}
+
+// Title 告警标题（用于 IM 卡片等）
+func (e *Event) Title() string {
+	name := e.Provider + "/" + e.Service
+	if e.Channel != "" {
+		name += "/" + e.Channel
+	}
+	if e.Region != "" {
+		name += " [" + e.Region + "]"
+	}
+
+	switch e.Type {
+	case config.AlertEventDown:
+		return fmt.Sprintf("🔴 %s 不可用", name)
+	case config.AlertEventDegraded:
+		return fmt.Sprintf("🟡 %s 服务降级", name)
+	case config.AlertEventRecovered:
+		return fmt.Sprintf("🟢 %s 已恢复", name)
+	case config.AlertEventAvailabilityLow:
+		return fmt.Sprintf("🟠 %s 可用率过低", name)
+	case config.AlertEventAvailabilityRecovered:
+		return fmt.Sprintf("🟢 %s 可用率已恢复", name)
+	case config.AlertEventFlapping:
+		return fmt.Sprintf("🟣 %s 状态震荡", name)
+	case config.AlertEventFlappingStopped:
+		return fmt.Sprintf("🟢 %s 状态已稳定", name)
+	default:
+		return fmt.Sprintf("%s %s", name, e.Type)
+	}
+}
+
+// buildMessage 生成可读的告警摘要
+func (e *Event) buildMessage() string {
+	var parts []string
+	switch e.Type {
+	case config.AlertEventDown:
+		parts = append(parts, fmt.Sprintf("连续 %d 次探测失败", e.Consecutive))
+	case config.AlertEventDegraded:
+		parts = append(parts, fmt.Sprintf("连续 %d 次探测降级", e.Consecutive))
+	case config.AlertEventRecovered:
+		parts = append(parts, fmt.Sprintf("连续 %d 次探测正常（之前状态: %s）", e.Consecutive, e.PreviousState))
+	case config.AlertEventAvailabilityLow, config.AlertEventAvailabilityRecovered:
+		parts = append(parts, fmt.Sprintf("窗口可用率 %.2f%%", e.Availability))
+	case config.AlertEventFlapping:
+		parts = append(parts, fmt.Sprintf("状态变化频率 %.0f%%，震荡期间暂停推送不可用/降级/恢复通知", e.FlappingScore*100))
+	case config.AlertEventFlappingStopped:
+		parts = append(parts, fmt.Sprintf("状态变化频率降至 %.0f%%，当前状态: %s", e.FlappingScore*100, e.State))
+	}
+	if e.SubStatus != "" {
+		parts = append(parts, "原因: "+e.SubStatus)
+	}
+	parts = append(parts, fmt.Sprintf("延迟: %dms", e.Latency))
+	parts = append(parts, "时间: "+time.Unix(e.Timestamp, 0).Format("2006-01-02 15:04:05"))
+	return e.Title() + "，" + strings.Join(parts, "，")
+}
@@ -0,0 +1,274 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// 各 IM 机器人的限流错误码（可重试），其余业务错误码视为配置错误不再重试
+const (
+	dingTalkErrRateLimit = 130101 // 钉钉：发送速度太快
+	feishuErrRateLimit   = 11232  // 飞书：请求频率超限
+	weComErrRateLimit    = 45009  // 企业微信：接口调用超过限制
+)
+
+// eventField 卡片中的一行字段
+type eventField struct {
+	label string
+	value string
+}
+
+// eventFields 生成 IM 卡片展示的字段（服务商、服务、通道、细分状态、延迟等）
+func eventFields(e *Event) []eventField {
+	fields := []eventField{
+		{"服务商", e.Provider},
+		{"服务", e.Service},
+	}
+	if e.Channel != "" {
+		fields = append(fields, eventField{"通道", e.Channel})
+	}
+	if e.Category != "" {
+		fields = append(fields, eventField{"分类", e.Category})
+	}
+	if e.SubStatus != "" {
+		fields = append(fields, eventField{"细分状态", e.SubStatus})
+	}
+	if e.HTTPStatus > 0 {
+		fields = append(fields, eventField{"HTTP 状态码", strconv.Itoa(e.HTTPStatus)})
+	}
+	fields = append(fields, eventField{"延迟", fmt.Sprintf("%dms", e.Latency)})
+	if e.Availability >= 0 && (e.Type == config.AlertEventAvailabilityLow || e.Type == config.AlertEventAvailabilityRecovered) {
+		fields = append(fields, eventField{"窗口可用率", fmt.Sprintf("%.2f%%", e.Availability)})
+	}
+	if e.Type == config.AlertEventFlapping || e.Type == config.AlertEventFlappingStopped {
+		fields = append(fields, eventField{"状态变化频率", fmt.Sprintf("%.0f%%", e.FlappingScore*100)})
+	}
+	if e.Consecutive > 0 {
+		fields = append(fields, eventField{"连续次数", strconv.Itoa(e.Consecutive)})
+	}
+	fields = append(fields, eventField{"时间", time.Unix(e.Timestamp, 0).Format("2006-01-02 15:04:05")})
+	if e.Error != "" {
+		fields = append(fields, eventField{"错误", e.Error})
+	}
+	return fields
+}
+
+// renderMarkdown 生成钉钉/企业微信通用的 Markdown 正文
+func renderMarkdown(e *Event) string {
+	var b strings.Builder
+	b.WriteString("### " + e.Title() + "\n\n")
+	for _, f := range eventFields(e) {
+		fmt.Fprintf(&b, "- **%s**: %s\n", f.label, f.value)
+	}
+	if e.DashboardURL != "" {
+		fmt.Fprintf(&b, "\n[查看监控面板](%s)\n", e.DashboardURL)
+	}
+	return b.String()
+}
+
+// sign 计算 HMAC-SHA256 签名并进行 Base64 编码
+func sign(key, message string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkErrCode 解析机器人返回的业务错误码，非 0 视为失败（限流错误可重试）
+func checkErrCode(body []byte, codeField string, rateLimitCode int) error {
+	var resp map[string]any
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil // 非 JSON 响应：HTTP 2xx 即视为成功
+	}
+	code, ok := resp[codeField].(float64)
+	if !ok || code == 0 {
+		return nil
+	}
+
+	msg, _ := resp["errmsg"].(string)
+	if msg == "" {
+		msg, _ = resp["msg"].(string)
+	}
+	err := fmt.Errorf("机器人返回错误 %d: %s", int(code), msg)
+	if int(code) == rateLimitCode {
+		return err
+	}
+	return &permanentError{err}
+}
+
+// imNotifier IM 机器人通用部分
+type imNotifier struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+	now    func() time.Time // 签名时间戳来源（测试可替换）
+}
+
+// Name 渠道名称
+func (n *imNotifier) Name() string {
+	return n.cfg.Name
+}
+
+// Subscribes 是否订阅该事件类型
+func (n *imNotifier) Subscribes(event string) bool {
+	return n.cfg.Subscribes(event)
+}
+
+// post 发送消息并检查业务错误码
+func (n *imNotifier) post(ctx context.Context, target string, msg any, codeField string, rateLimitCode int) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return &permanentError{fmt.Errorf("序列化告警消息失败: %w", err)}
+	}
+	body, err := postJSON(ctx, n.client, target, n.cfg.Headers, n.cfg.TimeoutDuration, payload)
+	if err != nil {
+		return err
+	}
+	return checkErrCode(body, codeField, rateLimitCode)
+}
+
+// DingTalkNotifier 钉钉自定义机器人（支持"加签"安全设置）
+type DingTalkNotifier struct {
+	imNotifier
+}
+
+// NewDingTalkNotifier 创建钉钉机器人通知渠道
+func NewDingTalkNotifier(cfg config.WebhookConfig, client *http.Client) *DingTalkNotifier {
+	return &DingTalkNotifier{imNotifier{cfg: cfg, client: client, now: time.Now}}
+}
+
+// Send 发送 Markdown 消息
+func (d *DingTalkNotifier) Send(ctx context.Context, event *Event) error {
+	target := d.cfg.URL
+	if d.cfg.Secret != "" {
+		// 加签：HmacSHA256(timestamp + "\n" + secret)，密钥为 secret，时间戳为毫秒
+		ts := strconv.FormatInt(d.now().UnixMilli(), 10)
+		u, err := url.Parse(target)
+		if err != nil {
+			return &permanentError{fmt.Errorf("解析钉钉 Webhook 地址失败: %w", err)}
+		}
+		q := u.Query()
+		q.Set("timestamp", ts)
+		q.Set("sign", sign(d.cfg.Secret, ts+"\n"+d.cfg.Secret))
+		u.RawQuery = q.Encode()
+		target = u.String()
+	}
+
+	msg := map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": event.Title(),
+			"text":  renderMarkdown(event),
+		},
+	}
+	return d.post(ctx, target, msg, "errcode", dingTalkErrRateLimit)
+}
+
+// FeishuNotifier 飞书/Lark 自定义机器人（支持"签名校验"安全设置）
+type FeishuNotifier struct {
+	imNotifier
+}
+
+// NewFeishuNotifier 创建飞书机器人通知渠道
+func NewFeishuNotifier(cfg config.WebhookConfig, client *http.Client) *FeishuNotifier {
+	return &FeishuNotifier{imNotifier{cfg: cfg, client: client, now: time.Now}}
+}
+
+// Send 发送消息卡片
+func (f *FeishuNotifier) Send(ctx context.Context, event *Event) error {
+	msg := map[string]any{
+		"msg_type": "interactive",
+		"card":     feishuCard(event),
+	}
+	if f.cfg.Secret != "" {
+		// 签名校验：以 timestamp + "\n" + secret 为密钥对空串做 HmacSHA256，时间戳为秒
+		ts := strconv.FormatInt(f.now().Unix(), 10)
+		msg["timestamp"] = ts
+		msg["sign"] = sign(ts+"\n"+f.cfg.Secret, "")
+	}
+	return f.post(ctx, f.cfg.URL, msg, "code", feishuErrRateLimit)
+}
+
+// feishuCard 生成飞书消息卡片
+func feishuCard(e *Event) map[string]any {
+	fields := make([]map[string]any, 0)
+	for _, f := range eventFields(e) {
+		fields = append(fields, map[string]any{
+			"is_short": f.label != "错误",
+			"text": map[string]string{
+				"tag":     "lark_md",
+				"content": fmt.Sprintf("**%s**\n%s", f.label, f.value),
+			},
+		})
+	}
+
+	elements := []map[string]any{
+		{"tag": "div", "fields": fields},
+	}
+	if e.DashboardURL != "" {
+		elements = append(elements, map[string]any{
+			"tag": "action",
+			"actions": []map[string]any{{
+				"tag":  "button",
+				"text": map[string]string{"tag": "plain_text", "content": "查看监控面板"},
+				"type": "primary",
+				"url":  e.DashboardURL,
+			}},
+		})
+	}
+
+	return map[string]any{
+		"config": map[string]any{"wide_screen_mode": true},
+		"header": map[string]any{
+			"title":    map[string]string{"tag": "plain_text", "content": e.Title()},
+			"template": feishuTemplate(e.Type),
+		},
+		"elements": elements,
+	}
+}
+
+// feishuTemplate 根据事件类型选择卡片标题颜色
+func feishuTemplate(eventType string) string {
+	switch eventType {
+	case config.AlertEventDown:
+		return "red"
+	case config.AlertEventDegraded:
+		return "yellow"
+	case config.AlertEventAvailabilityLow:
+		return "orange"
+	case config.AlertEventFlapping:
+		return "purple"
+	default:
+		return "green"
+	}
+}
+
+// WeComNotifier 企业微信群机器人
+type WeComNotifier struct {
+	imNotifier
+}
+
+// NewWeComNotifier 创建企业微信机器人通知渠道
+func NewWeComNotifier(cfg config.WebhookConfig, client *http.Client) *WeComNotifier {
+	return &WeComNotifier{imNotifier{cfg: cfg, client: client, now: time.Now}}
+}
+
+// Send 发送 Markdown 消息
+func (w *WeComNotifier) Send(ctx context.Context, event *Event) error {
+	msg := map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": renderMarkdown(event),
+		},
+	}
+	return w.post(ctx, w.cfg.URL, msg, "errcode", weComErrRateLimit)
+}
@@ -0,0 +1,163 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+func testEvent() *Event {
+	return &Event{
+		Type:         config.AlertEventDown,
+		Provider:     "demo",
+		Service:      "cc",
+		Channel:      "vip",
+		SubStatus:    "server_error",
+		Latency:      1234,
+		Consecutive:  3,
+		Availability: -1,
+		Timestamp:    1700000000,
+		DashboardURL: "https://status.example.com",
+	}
+}
+
+// captureServer 本地机器人替身，记录请求并返回指定响应体
+func captureServer(t *testing.T, response string, captured *http.Request, body *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*captured = *r.Clone(r.Context())
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("decode request body: %v", err)
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDingTalkNotifierSign(t *testing.T) {
+	t.Parallel()
+
+	var req http.Request
+	var body map[string]any
+	srv := captureServer(t, `{"errcode":0,"errmsg":"ok"}`, &req, &body)
+
+	n := NewDingTalkNotifier(config.WebhookConfig{Name: "ding", URL: srv.URL + "/robot/send?access_token=abc", Secret: "SEC123"}, srv.Client())
+	n.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	if err := n.Send(t.Context(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	q := req.URL.Query()
+	if q.Get("access_token") != "abc" || q.Get("timestamp") != "1700000000000" {
+		t.Fatalf("unexpected query: %s", req.URL.RawQuery)
+	}
+	if want := sign("SEC123", "1700000000000\nSEC123"); q.Get("sign") != want {
+		t.Fatalf("expected sign %s, got %s", want, q.Get("sign"))
+	}
+
+	if body["msgtype"] != "markdown" {
+		t.Fatalf("unexpected msgtype: %v", body["msgtype"])
+	}
+	text := body["markdown"].(map[string]any)["text"].(string)
+	for _, want := range []string{"demo", "cc", "vip", "server_error", "1234ms", "https://status.example.com"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("markdown missing %q: %s", want, text)
+		}
+	}
+}
+
+func TestFeishuNotifierSign(t *testing.T) {
+	t.Parallel()
+
+	var req http.Request
+	var body map[string]any
+	srv := captureServer(t, `{"code":0,"msg":"success"}`, &req, &body)
+
+	n := NewFeishuNotifier(config.WebhookConfig{Name: "feishu", URL: srv.URL, Secret: "SEC123"}, srv.Client())
+	n.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	if err := n.Send(t.Context(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if body["timestamp"] != "1700000000" {
+		t.Fatalf("unexpected timestamp: %v", body["timestamp"])
+	}
+	if want := sign("1700000000\nSEC123", ""); body["sign"] != want {
+		t.Fatalf("expected sign %s, got %v", want, body["sign"])
+	}
+	if body["msg_type"] != "interactive" {
+		t.Fatalf("unexpected msg_type: %v", body["msg_type"])
+	}
+
+	raw, _ := json.Marshal(body["card"])
+	for _, want := range []string{"demo", "vip", "server_error", "1234ms", "https://status.example.com", `"template":"red"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Fatalf("card missing %q: %s", want, raw)
+		}
+	}
+}
+
+func TestWeComNotifierPayload(t *testing.T) {
+	t.Parallel()
+
+	var req http.Request
+	var body map[string]any
+	srv := captureServer(t, `{"errcode":0,"errmsg":"ok"}`, &req, &body)
+
+	n := NewWeComNotifier(config.WebhookConfig{Name: "wecom", URL: srv.URL + "/cgi-bin/webhook/send?key=k"}, srv.Client())
+	if err := n.Send(t.Context(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if req.URL.Query().Get("key") != "k" {
+		t.Fatalf("unexpected query: %s", req.URL.RawQuery)
+	}
+	content := body["markdown"].(map[string]any)["content"].(string)
+	if !strings.Contains(content, "server_error") || !strings.Contains(content, "[查看监控面板](https://status.example.com)") {
+		t.Fatalf("unexpected content: %s", content)
+	}
+}
+
+func TestIMNotifierErrCode(t *testing.T) {
+	t.Parallel()
+
+	var req http.Request
+	var body map[string]any
+	srv := captureServer(t, `{"errcode":310000,"errmsg":"sign not match"}`, &req, &body)
+
+	n := NewDingTalkNotifier(config.WebhookConfig{Name: "ding", URL: srv.URL}, srv.Client())
+	err := n.Send(t.Context(), testEvent())
+	if err == nil || !isPermanent(err) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+
+	if err := checkErrCode([]byte(`{"errcode":130101,"errmsg":"send too fast"}`), "errcode", dingTalkErrRateLimit); err == nil || isPermanent(err) {
+		t.Fatalf("expected retryable error for rate limit, got %v", err)
+	}
+}
+
+func TestNewNotifierByType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		config.NotifierWebhook:  "*alert.WebhookNotifier",
+		config.NotifierDingTalk: "*alert.DingTalkNotifier",
+		config.NotifierFeishu:   "*alert.FeishuNotifier",
+		config.NotifierWeCom:    "*alert.WeComNotifier",
+	}
+	for typ, want := range cases {
+		n := newNotifier(config.WebhookConfig{Type: typ}, http.DefaultClient)
+		if got := fmt.Sprintf("%T", n); got != want {
+			t.Fatalf("type %s: expected %s, got %s", typ, want, got)
+		}
+	}
+}
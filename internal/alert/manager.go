@@ -0,0 +1,317 @@
+package alert
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/flapping"
+	"monitor/internal/monitor"
+)
+
+// sample 可用率窗口内的一次探测
+type sample struct {
+	timestamp int64
+	status    int
+}
+
+// monitorState 单个监控项的告警状态
+type monitorState struct {
+	state               string
+	consecutiveFailures int // 连续红色次数
+	consecutiveDegraded int // 连续黄色次数
+	consecutiveUp       int // 连续非红色次数
+	consecutiveGreen    int // 连续绿色次数
+	window              []sample
+	lowAvailability     bool
+	recent              []int // 最近的有效探测状态（用于震荡检测）
+	flapping            bool
+}
+
+// channel 通知渠道及其重试策略
+type channel struct {
+	notifier Notifier
+	retries  int
+	backoff  time.Duration
+}
+
+// Manager 告警管理器：根据探测结果检测状态变化并推送通知
+type Manager struct {
+	mu             sync.Mutex
+	cfg            config.AlertingConfig
+	degradedWeight float64
+	flapping       config.FlappingConfig
+	channels       []channel
+	states         map[string]*monitorState
+
+	client *http.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建告警管理器
+func NewManager(cfg *config.AppConfig) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		states: make(map[string]*monitorState),
+		client: &http.Client{},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	m.UpdateConfig(cfg)
+	return m
+}
+
+// UpdateConfig 更新告警配置（热更新时调用），已有的监控项告警状态保持不变，已删除监控项的状态（含各地域）被清理
+func (m *Manager) UpdateConfig(cfg *config.AppConfig) {
+	active := make(map[string]bool, len(cfg.Monitors))
+	for _, mon := range cfg.Monitors {
+		active[mon.Provider+"/"+mon.Service+"/"+mon.Channel] = true
+	}
+
+	channels := make([]channel, 0, len(cfg.Alerting.Webhooks))
+	for _, w := range cfg.Alerting.Webhooks {
+		channels = append(channels, channel{
+			notifier: newNotifier(w, m.client),
+			retries:  w.Retries,
+			backoff:  w.RetryBackoffDuration,
+		})
+	}
+
+	m.mu.Lock()
+	m.cfg = cfg.Alerting
+	m.degradedWeight = cfg.DegradedWeight
+	m.flapping = cfg.Flapping
+	m.channels = channels
+	for key := range m.states {
+		monitorKey := key
+		if i := strings.LastIndex(key, "@"); i >= 0 && !active[key] {
+			monitorKey = key[:i] // 去掉 @region 后缀
+		}
+		if !active[monitorKey] {
+			delete(m.states, key)
+		}
+	}
+	m.mu.Unlock()
+
+	if cfg.Alerting.Enabled {
+		log.Printf("[Alert] 告警已启用，%d 个通知渠道", len(channels))
+	}
+}
+
+// newNotifier 根据配置类型创建通知渠道
+func newNotifier(cfg config.WebhookConfig, client *http.Client) Notifier {
+	switch cfg.Type {
+	case config.NotifierDingTalk:
+		return NewDingTalkNotifier(cfg, client)
+	case config.NotifierFeishu:
+		return NewFeishuNotifier(cfg, client)
+	case config.NotifierWeCom:
+		return NewWeComNotifier(cfg, client)
+	default:
+		return NewWebhookNotifier(cfg, client)
+	}
+}
+
+// OnProbeResult 处理一次探测结果（实现 monitor.ResultObserver）
+func (m *Manager) OnProbeResult(cfg *config.ServiceConfig, result *monitor.ProbeResult) {
+	m.mu.Lock()
+	if !m.cfg.Enabled {
+		m.mu.Unlock()
+		return
+	}
+	events := m.evaluate(cfg, result)
+	channels := m.channels
+	m.mu.Unlock()
+
+	for _, e := range events {
+		log.Printf("[Alert] %s", e.Message)
+		m.dispatch(channels, e)
+	}
+}
+
+// evaluate 更新监控项状态并返回需要推送的事件（调用方需持有锁）
+func (m *Manager) evaluate(cfg *config.ServiceConfig, result *monitor.ProbeResult) []*Event {
+	// 探测节点上报的结果按地域分别判定，互不影响
+	key := cfg.Provider + "/" + cfg.Service + "/" + cfg.Channel
+	if result.Region != "" {
+		key += "@" + result.Region
+	}
+	st, ok := m.states[key]
+	if !ok {
+		st = &monitorState{state: StateOK}
+		m.states[key] = st
+	}
+
+	switch result.Status {
+	case 0:
+		st.consecutiveFailures++
+		st.consecutiveDegraded = 0
+		st.consecutiveUp = 0
+		st.consecutiveGreen = 0
+	case 2:
+		st.consecutiveDegraded++
+		st.consecutiveFailures = 0
+		st.consecutiveUp++
+		st.consecutiveGreen = 0
+	case 1:
+		st.consecutiveFailures = 0
+		st.consecutiveDegraded = 0
+		st.consecutiveUp++
+		st.consecutiveGreen++
+	default:
+		// 其他状态（如灰色、维护中）不参与告警判定
+		return nil
+	}
+
+	availability := m.updateWindow(st, result)
+	flap := m.detectFlapping(st, result.Status)
+	wasFlapping := st.flapping
+	st.flapping = flap.Flapping
+
+	newEvent := func(eventType, newState string, consecutive int) *Event {
+		e := &Event{
+			Type:          eventType,
+			Provider:      cfg.Provider,
+			Service:       cfg.Service,
+			Channel:       cfg.Channel,
+			Region:        result.Region,
+			Category:      cfg.Category,
+			PreviousState: st.state,
+			State:         newState,
+			Status:        result.Status,
+			SubStatus:     string(result.SubStatus),
+			Latency:       result.Latency,
+			Consecutive:   consecutive,
+			Availability:  availability,
+			Timestamp:     result.Timestamp,
+			DashboardURL:  m.cfg.DashboardURL,
+		}
+		if result.Status != 1 {
+			e.HTTPStatus = result.HTTPStatus
+		}
+		if result.Error != nil {
+			e.Error = monitor.SanitizeDiagnostic(result.Error.Error(), cfg, 0)
+		}
+		e.Message = e.buildMessage()
+		st.state = newState
+		return e
+	}
+
+	var events []*Event
+	switch {
+	case st.state != StateDown && st.consecutiveFailures >= m.cfg.ConsecutiveFailures:
+		events = append(events, newEvent(config.AlertEventDown, StateDown, st.consecutiveFailures))
+	case st.state == StateOK && m.cfg.ConsecutiveDegraded > 0 && st.consecutiveDegraded >= m.cfg.ConsecutiveDegraded:
+		events = append(events, newEvent(config.AlertEventDegraded, StateDegraded, st.consecutiveDegraded))
+	case st.state == StateDown && st.consecutiveUp >= m.cfg.RecoverySuccesses:
+		events = append(events, newEvent(config.AlertEventRecovered, StateOK, st.consecutiveUp))
+	case st.state == StateDegraded && st.consecutiveGreen >= m.cfg.RecoverySuccesses:
+		events = append(events, newEvent(config.AlertEventRecovered, StateOK, st.consecutiveGreen))
+	}
+
+	// 震荡期间照常更新告警状态，但不推送状态变化通知；震荡开始/结束时各推送一次
+	if st.flapping {
+		events = nil
+	}
+	if st.flapping != wasFlapping {
+		eventType := config.AlertEventFlappingStopped
+		if st.flapping {
+			eventType = config.AlertEventFlapping
+		}
+		e := newEvent(eventType, st.state, 0)
+		e.FlappingScore = flap.Score
+		e.Message = e.buildMessage()
+		events = append(events, e)
+	}
+
+	// 窗口可用率阈值
+	if m.cfg.AvailabilityThreshold > 0 && availability >= 0 {
+		if !st.lowAvailability && availability < m.cfg.AvailabilityThreshold {
+			st.lowAvailability = true
+			events = append(events, newEvent(config.AlertEventAvailabilityLow, st.state, 0))
+		} else if st.lowAvailability && availability >= m.cfg.AvailabilityThreshold {
+			st.lowAvailability = false
+			events = append(events, newEvent(config.AlertEventAvailabilityRecovered, st.state, 0))
+		}
+	}
+
+	return events
+}
+
+// updateWindow 更新可用率窗口并返回当前可用率（样本不足时返回 -1）
+func (m *Manager) updateWindow(st *monitorState, result *monitor.ProbeResult) float64 {
+	st.window = append(st.window, sample{timestamp: result.Timestamp, status: result.Status})
+
+	cutoff := result.Timestamp - int64(m.cfg.AvailabilityWindowDuration/time.Second)
+	drop := 0
+	for drop < len(st.window) && st.window[drop].timestamp < cutoff {
+		drop++
+	}
+	st.window = st.window[drop:]
+
+	if len(st.window) < m.cfg.AvailabilityMinSamples || len(st.window) == 0 {
+		return -1
+	}
+
+	var weighted float64
+	for _, s := range st.window {
+		switch s.status {
+		case 1:
+			weighted += 1
+		case 2:
+			weighted += m.degradedWeight
+		}
+	}
+	return weighted / float64(len(st.window)) * 100
+}
+
+// detectFlapping 记录最新的有效探测状态并计算震荡评分（未开启震荡检测时返回零值）
+func (m *Manager) detectFlapping(st *monitorState, status int) flapping.Result {
+	if !m.flapping.Enabled() {
+		st.recent = nil
+		return flapping.Result{}
+	}
+	st.recent = append(st.recent, status)
+	if drop := len(st.recent) - m.flapping.Window; drop > 0 {
+		st.recent = st.recent[drop:]
+	}
+	return flapping.Detect(st.recent, m.flapping)
+}
+
+// dispatch 异步推送事件到所有订阅的渠道
+func (m *Manager) dispatch(channels []channel, event *Event) {
+	for _, ch := range channels {
+		if !ch.notifier.Subscribes(event.Type) {
+			continue
+		}
+		m.wg.Add(1)
+		go func(ch channel) {
+			defer m.wg.Done()
+			if err := sendWithRetry(m.ctx, ch.notifier, event, ch.retries, ch.backoff); err != nil {
+				log.Printf("[Alert] 通知 %s 发送失败: %v", ch.notifier.Name(), err)
+			}
+		}(ch)
+	}
+}
+
+// Close 停止告警管理器，等待进行中的通知完成（最多等待 timeout）
+func (m *Manager) Close(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("[Alert] 等待通知发送超时，放弃剩余通知")
+	}
+	m.cancel()
+}
@@ -0,0 +1,133 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// maxResponseBodySize 读取通知响应体的最大长度
+const maxResponseBodySize = 4096
+
+// Notifier 告警通知渠道
+type Notifier interface {
+	// Name 渠道名称（用于日志）
+	Name() string
+
+	// Subscribes 是否订阅该事件类型
+	Subscribes(event string) bool
+
+	// Send 发送一次通知（不含重试）
+	Send(ctx context.Context, event *Event) error
+}
+
+// permanentError 不可重试的错误（如 4xx 配置错误）
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// isPermanent 判断错误是否不可重试
+func isPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// WebhookNotifier 通用 Webhook 通知（POST JSON 事件）
+type WebhookNotifier struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建通用 Webhook 通知渠道
+func NewWebhookNotifier(cfg config.WebhookConfig, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{cfg: cfg, client: client}
+}
+
+// Name 渠道名称
+func (w *WebhookNotifier) Name() string {
+	return w.cfg.Name
+}
+
+// Subscribes 是否订阅该事件类型
+func (w *WebhookNotifier) Subscribes(event string) bool {
+	return w.cfg.Subscribes(event)
+}
+
+// Send 发送 JSON 事件
+func (w *WebhookNotifier) Send(ctx context.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return &permanentError{fmt.Errorf("序列化告警事件失败: %w", err)}
+	}
+	_, err = postJSON(ctx, w.client, w.cfg.URL, w.cfg.Headers, w.cfg.TimeoutDuration, payload)
+	return err
+}
+
+// postJSON 发送 JSON 请求并返回响应体，2xx 视为成功；4xx（429 除外）视为不可重试
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, timeout time.Duration, payload []byte) ([]byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, &permanentError{fmt.Errorf("创建通知请求失败: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "relay-pulse-alert")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送通知失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, nil
+	}
+
+	err = fmt.Errorf("通知返回 HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, &permanentError{err}
+	}
+	return nil, err
+}
+
+// sendWithRetry 发送通知，失败时按指数退避重试
+func sendWithRetry(ctx context.Context, n Notifier, event *Event, retries int, backoff time.Duration) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if err = n.Send(ctx, event); err == nil {
+			return nil
+		}
+		if isPermanent(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("重试 %d 次后仍失败: %w", retries, err)
+}
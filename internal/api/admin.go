@@ -0,0 +1,439 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+
+	"monitor/internal/catalog"
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/retention"
+)
+
+// ProbeControl 管理 API 对调度器的运行时控制
+type ProbeControl interface {
+	// SetPaused 暂停或恢复监控项的探测，监控项不存在时返回 false
+	SetPaused(m *config.ServiceConfig, paused bool) bool
+
+	// Paused 监控项是否已暂停
+	Paused(m *config.ServiceConfig) bool
+
+	// TriggerNow 立即探测指定监控项，返回实际触发的数量（从节点返回 0）
+	TriggerNow(monitors ...*config.ServiceConfig) int
+
+	// IsLeader 当前副本是否执行探测（未开启选举时始终为 true）
+	IsLeader() bool
+}
+
+// AdminHooks 管理 API 依赖的运行时操作（由 main 注入，未设置的操作返回 503）
+type AdminHooks struct {
+	Scheduler ProbeControl
+	Reload    func() error                     // 重新加载配置文件
+	Cleanup   func() (retention.Result, error) // 按保留策略执行一次数据清理
+	Catalog   *catalog.Catalog                 // 数据库中的监控项（未开启 monitor_store 时为 nil）
+	Leader    func() (string, error)           // 当前主节点的副本标识（开启选举时用于提示从节点上的请求）
+}
+
+// 审计日志在 gin.Context 中使用的键
+const (
+	auditActorKey  = "admin_actor"
+	auditDetailKey = "admin_detail"
+)
+
+// AdminMonitor 管理 API 返回的监控项（完整配置，去除密钥）
+type AdminMonitor struct {
+	config.ServiceConfig
+	Transport *config.TransportConfig `json:"transport,omitempty"` // 生效的传输配置（代理凭据与私钥路径已隐藏）
+	HasAPIKey bool                    `json:"has_api_key"`
+	Paused    bool                    `json:"paused"`
+}
+
+// newAdminMonitor 生成管理 API 返回的脱敏监控项
+func newAdminMonitor(m *config.ServiceConfig) AdminMonitor {
+	redacted := monitor.RedactServiceConfig(m)
+	return AdminMonitor{ServiceConfig: redacted, Transport: redacted.Transport, HasAPIKey: m.APIKey != ""}
+}
+
+// adminAuth 校验管理 API 凭证并在请求结束后记录审计日志
+func (h *Handler) adminAuth(c *gin.Context) {
+	token := bearerToken(c)
+
+	h.cfgMu.RLock()
+	enabled := h.config.Admin.Enabled()
+	actor := ""
+	for _, t := range h.config.Admin.Tokens {
+		if tokenEqual(token, t.Token) {
+			actor = t.Name
+		}
+	}
+	h.cfgMu.RUnlock()
+
+	switch {
+	case !enabled:
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "未配置 admin.tokens，管理 API 未开放"})
+	case actor == "":
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "无效的管理凭证"})
+	default:
+		c.Set(auditActorKey, actor)
+		c.Next()
+	}
+	logAudit(c, actor)
+}
+
+// logAudit 记录一条管理操作审计日志（actor 为空表示认证失败）
+func logAudit(c *gin.Context, actor string) {
+	if actor == "" {
+		actor = "-"
+	}
+	detail := c.GetString(auditDetailKey)
+	if detail == "" {
+		detail = "-"
+	}
+	log.Printf("[Audit] actor=%s ip=%s %s %s status=%d detail=%s",
+		actor, c.ClientIP(), c.Request.Method, c.Request.URL.Path, c.Writer.Status(), detail)
+}
+
+// audit 补充本次管理操作的审计信息
+func audit(c *gin.Context, format string, args ...any) {
+	c.Set(auditDetailKey, fmt.Sprintf(format, args...))
+}
+
+// AdminListMonitors 列出全部监控项的配置（GET /api/admin/monitors）
+func (h *Handler) AdminListMonitors(c *gin.Context) {
+	h.cfgMu.RLock()
+	monitors := h.config.Monitors
+	h.cfgMu.RUnlock()
+
+	response := make([]AdminMonitor, 0, len(monitors))
+	for i := range monitors {
+		m := &monitors[i]
+		item := newAdminMonitor(m)
+		if h.admin.Scheduler != nil {
+			item.Paused = h.admin.Scheduler.Paused(m)
+		}
+		response = append(response, item)
+	}
+
+	source := "config"
+	if h.admin.Catalog != nil {
+		source = "database"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{"count": len(response), "source": source},
+		"data": response,
+	})
+}
+
+// AdminCreateMonitor 新建监控项（POST /api/admin/monitors，需开启 monitor_store）
+// 请求体字段与配置文件中 monitors 条目相同
+func (h *Handler) AdminCreateMonitor(c *gin.Context) {
+	if !h.requireCatalog(c) {
+		return
+	}
+	m, _, ok := bindMonitor(c)
+	if !ok {
+		return
+	}
+	audit(c, "create %s", monitorKey(m))
+
+	if err := h.admin.Catalog.Create(m); err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	h.respondMonitor(c, http.StatusCreated, m)
+}
+
+// AdminUpdateMonitor 覆盖监控项定义（PUT /api/admin/monitors?provider=&service=&channel=，需开启 monitor_store）
+// 请求体未包含 api_key 时沿用已保存的 API Key
+func (h *Handler) AdminUpdateMonitor(c *gin.Context) {
+	if !h.requireCatalog(c) {
+		return
+	}
+	provider, service, channel, ok := monitorTarget(c)
+	if !ok {
+		return
+	}
+	m, fields, ok := bindMonitor(c)
+	if !ok {
+		return
+	}
+	audit(c, "update %s/%s/%s", provider, service, channel)
+
+	// 定位字段取自查询参数，请求体中可以省略，但不能修改
+	changed := func(name, value, target string) bool {
+		_, present := fields[name]
+		return present && value != target
+	}
+	if changed("provider", m.Provider, provider) || changed("service", m.Service, service) || changed("channel", m.Channel, channel) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持修改 provider/service/channel，请删除后重新创建"})
+		return
+	}
+	m.Provider, m.Service, m.Channel = provider, service, channel
+
+	_, hasKey := fields["api_key"]
+	if err := h.admin.Catalog.Update(m, !hasKey); err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	h.respondMonitor(c, http.StatusOK, m)
+}
+
+// AdminDeleteMonitor 删除监控项（DELETE /api/admin/monitors?provider=&service=&channel=，需开启 monitor_store）
+func (h *Handler) AdminDeleteMonitor(c *gin.Context) {
+	if !h.requireCatalog(c) {
+		return
+	}
+	provider, service, channel, ok := monitorTarget(c)
+	if !ok {
+		return
+	}
+	audit(c, "delete %s/%s/%s", provider, service, channel)
+
+	if err := h.admin.Catalog.Delete(provider, service, channel); err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AdminImportMonitors 导入配置文件中数据库尚不存在的监控项（POST /api/admin/monitors/import，需开启 monitor_store）
+func (h *Handler) AdminImportMonitors(c *gin.Context) {
+	if !h.requireCatalog(c) {
+		return
+	}
+	audit(c, "import monitors")
+
+	n, err := h.admin.Catalog.Import()
+	if err != nil {
+		writeCatalogError(c, err)
+		return
+	}
+	audit(c, "import monitors: %d imported", n)
+	c.JSON(http.StatusOK, gin.H{"imported": n})
+}
+
+// requireCatalog 未开启 monitor_store 时写入错误响应
+func (h *Handler) requireCatalog(c *gin.Context) bool {
+	if h.admin.Catalog == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "未开启 monitor_store，监控项只能在配置文件中修改"})
+		return false
+	}
+	return true
+}
+
+// respondMonitor 返回修改后已生效的监控项（去除密钥）
+func (h *Handler) respondMonitor(c *gin.Context, status int, m *config.ServiceConfig) {
+	current, ok := h.findMonitor(m.Provider, m.Service, m.Channel)
+	if !ok {
+		c.JSON(status, gin.H{"provider": m.Provider, "service": m.Service, "channel": m.Channel})
+		return
+	}
+	item := newAdminMonitor(current)
+	if h.admin.Scheduler != nil {
+		item.Paused = h.admin.Scheduler.Paused(current)
+	}
+	c.JSON(status, item)
+}
+
+// bindMonitor 解析 JSON 请求体中的监控项定义，同时返回请求体包含的字段
+func bindMonitor(c *gin.Context) (*config.ServiceConfig, map[string]any, bool) {
+	var fields map[string]any
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("解析监控项失败: %v", err)})
+		return nil, nil, false
+	}
+	// 经 YAML 转换后按配置文件的字段名解析（JSON 标签不包含 api_key、transport 等字段）
+	data, err := yaml.Marshal(fields)
+	if err == nil {
+		var m *config.ServiceConfig
+		if m, err = catalog.ParseDefinition(data); err == nil {
+			return m, fields, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	return nil, nil, false
+}
+
+// writeCatalogError 按错误类型写入监控项修改失败的响应
+func writeCatalogError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, catalog.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, catalog.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, catalog.ErrExists):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// AdminPauseMonitor 暂停监控项的探测（POST /api/admin/monitors/pause?provider=&service=&channel=）
+func (h *Handler) AdminPauseMonitor(c *gin.Context) {
+	h.setMonitorPaused(c, true)
+}
+
+// AdminResumeMonitor 恢复监控项的探测（POST /api/admin/monitors/resume?provider=&service=&channel=）
+func (h *Handler) AdminResumeMonitor(c *gin.Context) {
+	h.setMonitorPaused(c, false)
+}
+
+// setMonitorPaused 暂停或恢复监控项
+func (h *Handler) setMonitorPaused(c *gin.Context, paused bool) {
+	m, ok := h.adminMonitor(c)
+	if !ok {
+		return
+	}
+	action := "resume"
+	if paused {
+		action = "pause"
+	}
+	audit(c, "%s %s", action, monitorKey(m))
+
+	if h.admin.Scheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "调度器不可用"})
+		return
+	}
+	// 暂停状态只保存在执行探测的主节点内存中，从节点上修改不会生效
+	if !h.admin.Scheduler.IsLeader() {
+		c.JSON(http.StatusConflict, h.followerError("当前副本不是主节点，请向主节点发送暂停/恢复请求"))
+		return
+	}
+	if !h.admin.Scheduler.SetPaused(m, paused) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "调度器尚未加载该监控项"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"provider": m.Provider, "service": m.Service, "channel": m.Channel, "paused": paused})
+}
+
+// AdminProbeMonitor 立即探测单个监控项（POST /api/admin/monitors/probe?provider=&service=&channel=）
+func (h *Handler) AdminProbeMonitor(c *gin.Context) {
+	m, ok := h.adminMonitor(c)
+	if !ok {
+		return
+	}
+	audit(c, "probe %s", monitorKey(m))
+
+	switch {
+	case h.admin.Scheduler == nil:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "调度器不可用"})
+	case h.admin.Scheduler.Paused(m):
+		c.JSON(http.StatusConflict, gin.H{"error": "监控项已暂停，请先恢复"})
+	case !h.admin.Scheduler.IsLeader():
+		c.JSON(http.StatusConflict, h.followerError("当前副本不是主节点，探测由主节点执行"))
+	case h.admin.Scheduler.TriggerNow(m) == 0:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "调度器未运行或尚未加载该监控项"})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"provider": m.Provider, "service": m.Service, "channel": m.Channel, "triggered": true})
+	}
+}
+
+// followerError 从节点拒绝请求时的响应（附带当前主节点标识，便于转发到主节点）
+func (h *Handler) followerError(msg string) gin.H {
+	resp := gin.H{"error": msg}
+	if h.admin.Leader == nil {
+		return resp
+	}
+	leader, err := h.admin.Leader()
+	if err != nil {
+		log.Printf("⚠️  [Admin] 查询主节点失败: %v", err)
+	}
+	if leader != "" {
+		resp["leader"] = leader
+	}
+	return resp
+}
+
+// AdminReload 立即重新加载配置文件（POST /api/admin/reload）
+func (h *Handler) AdminReload(c *gin.Context) {
+	audit(c, "reload config")
+	if h.admin.Reload == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "配置热更新不可用"})
+		return
+	}
+	if err := h.admin.Reload(); err != nil {
+		audit(c, "reload config failed: %v", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.cfgMu.RLock()
+	count := len(h.config.Monitors)
+	h.cfgMu.RUnlock()
+	c.JSON(http.StatusOK, gin.H{"reloaded": true, "monitors": count})
+}
+
+// AdminRunRetention 按当前保留策略立即执行一次数据清理（POST /api/admin/retention）
+func (h *Handler) AdminRunRetention(c *gin.Context) {
+	audit(c, "run retention")
+	if h.admin.Cleanup == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "数据清理不可用"})
+		return
+	}
+
+	result, err := h.admin.Cleanup()
+	response := gin.H{
+		"raw":         result.Raw,
+		"hourly":      result.Hourly,
+		"daily":       result.Daily,
+		"duration_ms": result.Duration.Milliseconds(),
+	}
+	audit(c, "run retention: deleted %d rows", result.Total())
+	if err != nil {
+		response["error"] = err.Error()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// adminMonitor 按 provider/service/channel 参数查找监控项，找不到时写入错误响应
+func (h *Handler) adminMonitor(c *gin.Context) (*config.ServiceConfig, bool) {
+	provider, service, channel, ok := monitorTarget(c)
+	if !ok {
+		return nil, false
+	}
+	m, ok := h.findMonitor(provider, service, channel)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("监控项不存在: provider=%s, service=%s, channel=%s", provider, service, channel),
+		})
+	}
+	return m, ok
+}
+
+// monitorTarget 读取 provider/service/channel 查询参数，缺少必填参数时写入错误响应
+func monitorTarget(c *gin.Context) (provider, service, channel string, ok bool) {
+	provider = strings.TrimSpace(c.Query("provider"))
+	service = strings.TrimSpace(c.Query("service"))
+	channel = strings.TrimSpace(c.Query("channel"))
+	if provider == "" || service == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider 和 service 不能为空"})
+		return "", "", "", false
+	}
+	return provider, service, channel, true
+}
+
+// findMonitor 在当前配置中查找监控项（返回副本）
+func (h *Handler) findMonitor(provider, service, channel string) (*config.ServiceConfig, bool) {
+	h.cfgMu.RLock()
+	defer h.cfgMu.RUnlock()
+	for i := range h.config.Monitors {
+		m := h.config.Monitors[i]
+		if m.Provider == provider && m.Service == service && m.Channel == channel {
+			return &m, true
+		}
+	}
+	return nil, false
+}
+
+// monitorKey 监控项在日志中的标识
+func monitorKey(m *config.ServiceConfig) string {
+	return m.Provider + "/" + m.Service + "/" + m.Channel
+}
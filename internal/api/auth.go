@@ -0,0 +1,22 @@
+package api
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bearerToken 读取 Authorization: Bearer <token> 中的凭证（缺失时返回空）
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
+
+// tokenEqual 恒定时间比较凭证（expected 为空时不匹配任何凭证）
+func tokenEqual(token, expected string) bool {
+	return token != "" && expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"math"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// CertificateStatus API 返回的 TLS 证书信息
+type CertificateStatus struct {
+	Subject       string `json:"subject"`
+	Issuer        string `json:"issuer"`
+	NotBefore     int64  `json:"not_before"`     // 生效时间（Unix 秒）
+	NotAfter      int64  `json:"not_after"`      // 证书链中最早的过期时间（Unix 秒）
+	TLSVersion    string `json:"tls_version"`    // 协商的 TLS 版本（握手失败时为空）
+	CheckedAt     int64  `json:"checked_at"`     // 最近一次观察到该证书的时间（Unix 秒）
+	DaysRemaining int    `json:"days_remaining"` // 剩余有效天数（向下取整，已过期时为负数）
+	Expired       bool   `json:"expired"`
+	ExpiringSoon  bool   `json:"expiring_soon"` // 剩余有效期不足 cert_expiry_warning_days
+}
+
+// newCertificateStatus 由证书记录生成 API 返回结构（无记录时返回 nil）
+func newCertificateStatus(cert *storage.Certificate, warningDays int, now time.Time) *CertificateStatus {
+	if cert == nil {
+		return nil
+	}
+	remaining := time.Duration(cert.ExpiresIn(now.Unix())) * time.Second
+	return &CertificateStatus{
+		Subject:       cert.Subject,
+		Issuer:        cert.Issuer,
+		NotBefore:     cert.NotBefore,
+		NotAfter:      cert.NotAfter,
+		TLSVersion:    cert.TLSVersion,
+		CheckedAt:     cert.CheckedAt,
+		DaysRemaining: int(math.Floor(remaining.Hours() / 24)),
+		Expired:       remaining <= 0,
+		ExpiringSoon:  warningDays >= 0 && remaining <= time.Duration(warningDays)*24*time.Hour,
+	}
+}
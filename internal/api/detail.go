@@ -0,0 +1,158 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/storage"
+)
+
+// 失败记录查询数量限制
+const (
+	defaultFailureLimit = 50
+	maxFailureLimit     = 500
+)
+
+// FailureResult API 返回的非绿色探测记录及诊断信息
+type FailureResult struct {
+	Timestamp       int64             `json:"timestamp"`
+	Status          int               `json:"status"`
+	SubStatus       storage.SubStatus `json:"sub_status"`
+	Latency         int               `json:"latency"`
+	HTTPStatus      int               `json:"http_status"`      // HTTP 状态码，未收到响应时为 0
+	ErrorMessage    string            `json:"error_message"`    // 脱敏后的错误信息
+	ResponseSnippet string            `json:"response_snippet"` // 脱敏并截断的响应体片段
+	Attempts        int               `json:"attempts"`         // 尝试次数（含重试）
+	Region          string            `json:"region"`           // 探测地域
+}
+
+// MonitorDetail 单个监控项详情
+type MonitorDetail struct {
+	Provider    string             `json:"provider"`
+	ProviderURL string             `json:"provider_url"`
+	Service     string             `json:"service"`
+	Category    string             `json:"category"`
+	Sponsor     string             `json:"sponsor"`
+	SponsorURL  string             `json:"sponsor_url"`
+	Channel     string             `json:"channel"`
+	Current     *CurrentStatus     `json:"current_status"`
+	Certificate *CertificateStatus `json:"certificate"`  // TLS 证书信息（非 HTTPS 或尚未探测时为 null）
+	LastFailure *FailureResult     `json:"last_failure"` // 时间范围内最近一次非绿色探测
+	Failures    []FailureResult    `json:"failures"`     // 时间范围内的非绿色探测（按时间倒序）
+}
+
+// GetDetail 查询单个监控项的详情及失败诊断信息
+// 参数：provider/service（必填）、channel（默认空）、period（默认 24h）或 from/to（Unix 秒或 RFC3339）、
+// limit（默认 50，最大 500）、region（默认空，表示全部地域）
+func (h *Handler) GetDetail(c *gin.Context) {
+	qProvider := c.Query("provider")
+	qService := c.Query("service")
+	qChannel := c.Query("channel")
+	qRegion := c.Query("region")
+	if qProvider == "" || qService == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider 和 service 不能为空"})
+		return
+	}
+
+	tr, err := parseTimeRange(c, "24h")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := defaultFailureLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("无效的 limit: %s", v)})
+			return
+		}
+		limit = min(n, maxFailureLimit)
+	}
+
+	h.cfgMu.RLock()
+	var detail *MonitorDetail
+	var warningDays int
+	for _, task := range h.config.Monitors {
+		if task.Provider == qProvider && task.Service == qService && task.Channel == qChannel {
+			detail = &MonitorDetail{
+				Provider:    task.Provider,
+				ProviderURL: task.ProviderURL,
+				Service:     task.Service,
+				Category:    task.Category,
+				Sponsor:     task.Sponsor,
+				SponsorURL:  task.SponsorURL,
+				Channel:     task.Channel,
+			}
+			warningDays = task.CertExpiryWarningDays
+			break
+		}
+	}
+	h.cfgMu.RUnlock()
+
+	if detail == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("监控项不存在: provider=%s, service=%s, channel=%s", qProvider, qService, qChannel),
+		})
+		return
+	}
+
+	latest, err := h.storage.GetLatest(qProvider, qService, qChannel, qRegion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("查询最新记录失败: %v", err),
+		})
+		return
+	}
+	detail.Current = newCurrentStatus(latest)
+
+	cert, err := h.storage.GetCertificate(qProvider, qService, qChannel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("查询证书信息失败: %v", err),
+		})
+		return
+	}
+	detail.Certificate = newCertificateStatus(cert, warningDays, time.Now())
+
+	failures, err := h.storage.GetFailures(qProvider, qService, qChannel, qRegion, tr.from, tr.to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("查询诊断记录失败: %v", err),
+		})
+		return
+	}
+
+	detail.Failures = make([]FailureResult, 0, len(failures))
+	for _, r := range failures {
+		detail.Failures = append(detail.Failures, FailureResult{
+			Timestamp:       r.Timestamp,
+			Status:          r.Status,
+			SubStatus:       r.SubStatus,
+			Latency:         r.Latency,
+			HTTPStatus:      r.HTTPStatus,
+			ErrorMessage:    r.ErrorMessage,
+			ResponseSnippet: r.ResponseSnippet,
+			Attempts:        r.Attempts,
+			Region:          r.Region,
+		})
+	}
+	if len(detail.Failures) > 0 {
+		detail.LastFailure = &detail.Failures[0]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{
+			"period": tr.period,
+			"from":   tr.from.Unix(),
+			"to":     tr.to.Unix(),
+			"region": qRegion,
+			"count":  len(detail.Failures),
+		},
+		"data": detail,
+	})
+}
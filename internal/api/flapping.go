@@ -0,0 +1,32 @@
+package api
+
+import (
+	"monitor/internal/config"
+	"monitor/internal/flapping"
+)
+
+// detectFlapping 根据指定地域最近的探测记录计算震荡评分与稳定状态
+func (h *Handler) detectFlapping(task *config.ServiceConfig, region string, cfg config.FlappingConfig) (flapping.Result, error) {
+	limit := cfg.SampleSize()
+	if limit == 0 {
+		return flapping.Result{Stable: -1}, nil
+	}
+
+	records, err := h.storage.GetRecent(task.Provider, task.Service, task.Channel, region, limit)
+	if err != nil {
+		return flapping.Result{}, err
+	}
+	statuses := make([]int, len(records))
+	for i, r := range records {
+		statuses[i] = r.Status
+	}
+	return flapping.Detect(statuses, cfg), nil
+}
+
+// applyHysteresis 开启滞回时以稳定状态替换当前状态（最新探测为灰色或维护中时保持原样）
+func applyHysteresis(current *CurrentStatus, flap flapping.Result, cfg config.FlappingConfig) {
+	if current == nil || cfg.Hysteresis <= 1 || flap.Stable < 0 || !flapping.Counted(current.RawStatus) {
+		return
+	}
+	current.Status = flap.Stable
+}
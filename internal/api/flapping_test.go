@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+func TestStatusReportsFlappingAndStableStatus(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+
+	cfg := &config.AppConfig{
+		Region: "local",
+		Monitors: []config.ServiceConfig{
+			{Provider: "demo", Service: "cc"},
+			{Provider: "demo", Service: "cx"},
+		},
+		Flapping: config.FlappingConfig{Window: 10, Threshold: 0.5, Hysteresis: 3},
+	}
+	h := NewHandler(store, cfg, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/status", h.GetStatus)
+
+	// cc 绿红交替后连续 3 次绿色、最新一次红色；cx 由绿转红后连续失败
+	now := time.Now().Unix()
+	statuses := map[string][]int{
+		"cc": {1, 0, 1, 0, 1, 1, 1, 0},
+		"cx": {1, 1, 1, 1, 1, 0, 0, 0},
+	}
+	for service, list := range statuses {
+		for i, status := range list {
+			record := &storage.ProbeRecord{Provider: "demo", Service: service, Status: status, Timestamp: now - int64(len(list)-i)*60}
+			if err := store.SaveRecord(record); err != nil {
+				t.Fatalf("save record: %v", err)
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/status?period=1h", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Data []MonitorResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Data) != 2 {
+		t.Fatalf("decode status: %v (%d results)", err, len(body.Data))
+	}
+
+	cc, cx := body.Data[0], body.Data[1]
+	if !cc.Flapping || math.Abs(cc.FlappingScore-5.0/7) > 1e-9 {
+		t.Fatalf("cc: expected flapping with score 5/7, got %v %.2f", cc.Flapping, cc.FlappingScore)
+	}
+	if cc.Current.Status != 1 || cc.Current.RawStatus != 0 {
+		t.Fatalf("cc: expected stable status 1 and raw status 0, got %+v", cc.Current)
+	}
+	if cx.Flapping {
+		t.Fatalf("cx: expected not flapping, score %.2f", cx.FlappingScore)
+	}
+	if cx.Current.Status != 0 || cx.Current.RawStatus != 0 {
+		t.Fatalf("cx: expected status 0 after 3 consecutive failures, got %+v", cx.Current)
+	}
+}
@@ -9,65 +9,127 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"monitor/internal/config"
+	"monitor/internal/maintenance"
+	"monitor/internal/monitor"
 	"monitor/internal/storage"
 )
 
 // Handler API处理器
 type Handler struct {
-	storage storage.Storage
-	config  *config.AppConfig
-	cfgMu   sync.RWMutex // 保护config的并发访问
+	storage     storage.Storage
+	config      *config.AppConfig
+	cfgMu       sync.RWMutex          // 保护config的并发访问
+	maintenance *maintenance.Calendar // 计划维护日历（为 nil 时不标注维护）
+	admin       AdminHooks            // 管理 API 的运行时操作
+
+	ingestObserver monitor.ResultObserver // 探测节点上报结果的观察者（为 nil 时只保存）
 }
 
 // NewHandler 创建处理器
-func NewHandler(store storage.Storage, cfg *config.AppConfig) *Handler {
+func NewHandler(store storage.Storage, cfg *config.AppConfig, calendar *maintenance.Calendar) *Handler {
 	return &Handler{
-		storage: store,
-		config:  cfg,
+		storage:     store,
+		config:      cfg,
+		maintenance: calendar,
 	}
 }
 
 // CurrentStatus API返回的当前状态（不暴露数据库主键）
 type CurrentStatus struct {
-	Status    int   `json:"status"`
+	Status    int   `json:"status"`     // 当前状态（开启 flapping.hysteresis 时为滞回后的稳定状态）
+	RawStatus int   `json:"raw_status"` // 最新一次探测的状态
 	Latency   int   `json:"latency"`
 	Timestamp int64 `json:"timestamp"`
+
+	FirstByteLatency  int `json:"first_byte_latency"`  // 首字节延迟（毫秒）
+	FirstTokenLatency int `json:"first_token_latency"` // 首 token 延迟（毫秒，仅流式探测）
+	StreamDuration    int `json:"stream_duration"`     // 流式总耗时（毫秒，仅流式探测）
+
+	// 网络阶段耗时（毫秒，复用已有连接时 DNS/连接/TLS 为 0）
+	DNSDuration      int `json:"dns_duration"`
+	ConnectDuration  int `json:"connect_duration"`
+	TLSDuration      int `json:"tls_duration"`
+	BodyReadDuration int `json:"body_read_duration"`
+
+	Attempts int `json:"attempts"` // 尝试次数（含重试）
+}
+
+// newCurrentStatus 由最新探测记录生成当前状态（无记录时返回 nil）
+func newCurrentStatus(latest *storage.ProbeRecord) *CurrentStatus {
+	if latest == nil {
+		return nil
+	}
+	return &CurrentStatus{
+		Status:            latest.Status,
+		RawStatus:         latest.Status,
+		Latency:           latest.Latency,
+		Timestamp:         latest.Timestamp,
+		FirstByteLatency:  latest.FirstByteLatency,
+		FirstTokenLatency: latest.FirstTokenLatency,
+		StreamDuration:    latest.StreamDuration,
+		DNSDuration:       latest.DNSDuration,
+		ConnectDuration:   latest.ConnectDuration,
+		TLSDuration:       latest.TLSDuration,
+		BodyReadDuration:  latest.BodyReadDuration,
+		Attempts:          latest.Attempts,
+	}
 }
 
 // MonitorResult API返回结构
 type MonitorResult struct {
-	Provider    string              `json:"provider"`
-	ProviderURL string              `json:"provider_url"` // 服务商官网链接
-	Service     string              `json:"service"`
-	Category    string              `json:"category"` // 分类：commercial（推广站）或 public（公益站）
-	Sponsor     string              `json:"sponsor"`  // 赞助者
-	SponsorURL  string              `json:"sponsor_url"` // 赞助者链接
-	Channel     string              `json:"channel"`  // 业务通道标识
-	Current     *CurrentStatus      `json:"current_status"`
-	Timeline    []storage.TimePoint `json:"timeline"`
+	Provider      string              `json:"provider"`
+	ProviderURL   string              `json:"provider_url"` // 服务商官网链接
+	Service       string              `json:"service"`
+	Category      string              `json:"category"`    // 分类：commercial（推广站）或 public（公益站）
+	Sponsor       string              `json:"sponsor"`     // 赞助者
+	SponsorURL    string              `json:"sponsor_url"` // 赞助者链接
+	Channel       string              `json:"channel"`     // 业务通道标识
+	Current       *CurrentStatus      `json:"current_status"`
+	Certificate   *CertificateStatus  `json:"certificate"`    // TLS 证书信息（非 HTTPS 或尚未探测时为 null）
+	Regions       []RegionStatus      `json:"regions"`        // 时间范围内各探测地域的最新状态
+	Maintenance   *MaintenanceStatus  `json:"maintenance"`    // 正在进行的计划维护（不在维护中时为 null）
+	Flapping      bool                `json:"flapping"`       // 最近的探测状态是否频繁变化
+	FlappingScore float64             `json:"flapping_score"` // 最近探测的状态变化频率（0-1）
+	Timeline      []storage.TimePoint `json:"timeline"`
 }
 
 // GetStatus 获取监控状态
 func (h *Handler) GetStatus(c *gin.Context) {
 	// 参数解析
-	period := c.DefaultQuery("period", "24h")
 	qProvider := c.DefaultQuery("provider", "all")
 	qService := c.DefaultQuery("service", "all")
+	qRegion := c.Query("region") // 为空时汇总全部地域
 
-	// 解析时间范围
-	since, err := h.parsePeriod(period)
+	// 解析时间范围（period 或 from/to）及 bucket 粒度
+	tr, err := parseTimeRange(c, "24h")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("无效的时间范围: %s", period),
+			"error": err.Error(),
 		})
 		return
 	}
 
+	// 7 天及以上、粒度为整小时/整天时读取预聚合数据，并将时间轴对齐到聚合边界
+	granularity, useRollup := tr.rollupGranularity()
+	if qRegion != "" {
+		useRollup = false // 聚合数据不区分地域，按地域过滤时读取原始记录
+	}
+	source := "raw"
+	if useRollup {
+		tr = tr.alignTo(granularity)
+		source = "rollup_" + string(granularity)
+	}
+
 	// 获取配置副本（线程安全）
 	h.cfgMu.RLock()
 	monitors := h.config.Monitors
 	degradedWeight := h.config.DegradedWeight
+	flappingCfg := h.config.Flapping
+	flappingRegion := h.config.Region
 	h.cfgMu.RUnlock()
+	if qRegion != "" {
+		flappingRegion = qRegion
+	}
 
 	var response []MonitorResult
 
@@ -90,7 +152,7 @@ func (h *Handler) GetStatus(c *gin.Context) {
 		seen[key] = true
 
 		// 获取最新记录
-		latest, err := h.storage.GetLatest(task.Provider, task.Service, task.Channel)
+		latest, err := h.storage.GetLatest(task.Provider, task.Service, task.Channel, qRegion)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": fmt.Sprintf("查询失败: %v", err),
@@ -98,170 +160,281 @@ func (h *Handler) GetStatus(c *gin.Context) {
 			return
 		}
 
-		// 获取历史记录
-		history, err := h.storage.GetHistory(task.Provider, task.Service, task.Channel, since)
+		regions, err := h.storage.GetLatestByRegion(task.Provider, task.Service, task.Channel, tr.from)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("查询历史失败: %v", err),
+				"error": fmt.Sprintf("查询各地域状态失败: %v", err),
 			})
 			return
 		}
 
-		// 转换为时间轴数据
-		timeline := h.buildTimeline(history, period, degradedWeight)
+		cert, err := h.storage.GetCertificate(task.Provider, task.Service, task.Channel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("查询证书信息失败: %v", err),
+			})
+			return
+		}
 
-		// 转换为API响应格式（不暴露数据库主键）
-		var current *CurrentStatus
-		if latest != nil {
-			current = &CurrentStatus{
-				Status:    latest.Status,
-				Latency:   latest.Latency,
-				Timestamp: latest.Timestamp,
+		// 转换为时间轴数据（整小时/整天粒度读取聚合表，其余读取原始记录）
+		var timeline []storage.TimePoint
+		if useRollup {
+			rollups, err := h.storage.GetRollups(task.Provider, task.Service, task.Channel, granularity, tr.from, tr.to)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": fmt.Sprintf("查询聚合数据失败: %v", err),
+				})
+				return
 			}
+			timeline = h.buildRollupTimeline(rollups, tr, degradedWeight)
+		} else {
+			history, err := h.storage.GetHistory(task.Provider, task.Service, task.Channel, qRegion, tr.from, tr.to)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": fmt.Sprintf("查询历史失败: %v", err),
+				})
+				return
+			}
+			timeline = h.buildTimeline(history, tr, degradedWeight)
+		}
+		h.annotateMaintenance(timeline, tr, &task)
+
+		// 震荡检测（多地域记录交替出现会被误判为震荡，默认只使用本实例地域的记录）
+		flap, err := h.detectFlapping(&task, flappingRegion, flappingCfg)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("查询最近记录失败: %v", err),
+			})
+			return
 		}
 
+		// 转换为API响应格式（不暴露数据库主键）
+		current := newCurrentStatus(latest)
+		applyHysteresis(current, flap, flappingCfg)
+
 		response = append(response, MonitorResult{
-			Provider:    task.Provider,
-			ProviderURL: task.ProviderURL,
-			Service:     task.Service,
-			Category:    task.Category,
-			Sponsor:     task.Sponsor,
-			SponsorURL:  task.SponsorURL,
-			Channel:     task.Channel,
-			Current:     current,
-			Timeline:    timeline,
+			Provider:      task.Provider,
+			ProviderURL:   task.ProviderURL,
+			Service:       task.Service,
+			Category:      task.Category,
+			Sponsor:       task.Sponsor,
+			SponsorURL:    task.SponsorURL,
+			Channel:       task.Channel,
+			Current:       current,
+			Certificate:   newCertificateStatus(cert, task.CertExpiryWarningDays, time.Now()),
+			Regions:       newRegionStatuses(regions),
+			Maintenance:   h.activeMaintenance(&task, time.Now()),
+			Flapping:      flap.Flapping,
+			FlappingScore: flap.Score,
+			Timeline:      timeline,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"meta": gin.H{
-			"period": period,
+			"period": tr.period,
+			"from":   tr.from.Unix(),
+			"to":     tr.to.Unix(),
+			"bucket": tr.bucket.String(),
+			"source": source,
+			"region": qRegion,
 			"count":  len(response),
 		},
 		"data": response,
 	})
 }
 
-// parsePeriod 解析时间范围
-func (h *Handler) parsePeriod(period string) (time.Time, error) {
-	now := time.Now()
-
-	switch period {
-	case "24h", "1d":
-		return now.Add(-24 * time.Hour), nil
-	case "7d":
-		return now.AddDate(0, 0, -7), nil
-	case "30d":
-		return now.AddDate(0, 0, -30), nil
-	default:
-		return time.Time{}, fmt.Errorf("不支持的时间范围")
-	}
-}
-
 // bucketStats 用于聚合每个 bucket 内的探测数据
 type bucketStats struct {
 	total           int                  // 总探测次数
+	maintenance     int                  // 维护期间的探测次数（不计入可用率）
 	weightedSuccess float64              // 累积成功权重（绿=1.0, 黄=degraded_weight, 红=0.0）
 	latencySum      int64                // 延迟总和
-	last            *storage.ProbeRecord // 最新一条记录
+	latencyMin      int                  // 最小延迟
+	latencyMax      int                  // 最大延迟
+	lastStatus      int                  // 最新一次探测的状态
+	lastTimestamp   int64                // 最新一次探测的时间
 	statusCounts    storage.StatusCounts // 各状态计数
+
+	// 分阶段延迟（仅统计有值的记录）
+	firstByte  latencyAvg // 首字节延迟
+	firstToken latencyAvg // 首 token 延迟
+	streamDur  latencyAvg // 流式总耗时
+	dns        latencyAvg // DNS 解析
+	connect    latencyAvg // TCP 连接
+	tls        latencyAvg // TLS 握手
+	bodyRead   latencyAvg // 读取响应体
 }
 
-// buildTimeline 构建固定长度的时间轴，计算每个 bucket 的可用率和平均延迟
-func (h *Handler) buildTimeline(records []*storage.ProbeRecord, period string, degradedWeight float64) []storage.TimePoint {
-	// 根据 period 确定 bucket 策略
-	bucketCount, bucketWindow, format := h.determineBucketStrategy(period)
+// addRecord 累加一条原始探测记录
+func (s *bucketStats) addRecord(record *storage.ProbeRecord, degradedWeight float64) {
+	s.addLatencyRange(record.Latency, record.Latency)
+	s.total++
+	if record.Status == 4 {
+		s.maintenance++
+	}
+	s.weightedSuccess += availabilityWeight(record.Status, degradedWeight)
+	s.latencySum += int64(record.Latency)
+	addStatusCount(&s.statusCounts, record.Status, record.SubStatus, 1)
+	s.firstByte.add(record.FirstByteLatency)
+	s.firstToken.add(record.FirstTokenLatency)
+	s.streamDur.add(record.StreamDuration)
+	s.dns.add(record.DNSDuration)
+	s.connect.add(record.ConnectDuration)
+	s.tls.add(record.TLSDuration)
+	s.bodyRead.add(record.BodyReadDuration)
+	s.updateLast(record.Status, record.Timestamp)
+}
 
-	now := time.Now()
+// addRollup 累加一条聚合记录
+func (s *bucketStats) addRollup(r *storage.RollupRecord, degradedWeight float64) {
+	if r.Count <= 0 {
+		return
+	}
+	s.addLatencyRange(r.LatencyMin, r.LatencyMax)
+	s.total += r.Count
+	if r.Status == 4 {
+		s.maintenance += r.Count
+	}
+	s.weightedSuccess += availabilityWeight(r.Status, degradedWeight) * float64(r.Count)
+	s.latencySum += r.LatencySum
+	addStatusCount(&s.statusCounts, r.Status, r.SubStatus, r.Count)
+	s.firstByte.addPhase(r.FirstByte)
+	s.firstToken.addPhase(r.FirstToken)
+	s.streamDur.addPhase(r.StreamDuration)
+	s.dns.addPhase(r.DNS)
+	s.connect.addPhase(r.Connect)
+	s.tls.addPhase(r.TLS)
+	s.bodyRead.addPhase(r.BodyRead)
+	s.updateLast(r.Status, r.LastTimestamp)
+}
 
-	// 初始化 buckets 和统计数据
-	buckets := make([]storage.TimePoint, bucketCount)
-	stats := make([]bucketStats, bucketCount)
+func (s *bucketStats) addLatencyRange(lo, hi int) {
+	if s.total == 0 || lo < s.latencyMin {
+		s.latencyMin = lo
+	}
+	if s.total == 0 || hi > s.latencyMax {
+		s.latencyMax = hi
+	}
+}
 
-	for i := 0; i < bucketCount; i++ {
-		bucketTime := now.Add(-time.Duration(bucketCount-i) * bucketWindow)
-		buckets[i] = storage.TimePoint{
-			Time:         bucketTime.Format(format),
-			Timestamp:    bucketTime.Unix(),
-			Status:       -1,  // 缺失标记
-			Latency:      0,
-			Availability: -1,  // 缺失标记
-		}
+func (s *bucketStats) updateLast(status int, timestamp int64) {
+	if s.lastTimestamp == 0 || timestamp > s.lastTimestamp {
+		s.lastStatus = status
+		s.lastTimestamp = timestamp
 	}
+}
 
-	// 聚合每个 bucket 的探测结果
-	for _, record := range records {
-		t := time.Unix(record.Timestamp, 0)
-		timeDiff := now.Sub(t)
+// latencyAvg 累加非零延迟样本并计算平均值
+type latencyAvg struct {
+	sum   int64
+	count int
+}
 
-		// 计算该记录属于哪个 bucket（从后往前）
-		bucketIndex := int(timeDiff / bucketWindow)
-		if bucketIndex < 0 {
-			bucketIndex = 0
-		}
-		if bucketIndex >= bucketCount {
+// add 累加一个样本（<=0 视为未采集，忽略）
+func (a *latencyAvg) add(v int) {
+	if v <= 0 {
+		return
+	}
+	a.sum += int64(v)
+	a.count++
+}
+
+// addPhase 累加聚合表中的样本总和与数量
+func (a *latencyAvg) addPhase(p storage.PhaseSum) {
+	a.sum += p.Sum
+	a.count += p.Count
+}
+
+// avg 返回四舍五入后的平均值，无样本时返回 0
+func (a *latencyAvg) avg() int {
+	if a.count == 0 {
+		return 0
+	}
+	return int(float64(a.sum)/float64(a.count) + 0.5)
+}
+
+// buildTimeline 构建固定长度的时间轴（以 tr.to 为终点向前划分 bucket），计算每个 bucket 的可用率和平均延迟
+func (h *Handler) buildTimeline(records []*storage.ProbeRecord, tr timeRange, degradedWeight float64) []storage.TimePoint {
+	bucketCount := tr.bucketCount()
+	stats := make([]bucketStats, bucketCount)
+
+	// 聚合每个 bucket 的探测结果（bucket 区间为左闭右开）
+	for _, record := range records {
+		idx := tr.bucketIndex(time.Unix(record.Timestamp, 0))
+		if idx < 0 || idx >= bucketCount {
 			continue // 超出范围，忽略
 		}
+		stats[idx].addRecord(record, degradedWeight)
+	}
+
+	return finalizeTimeline(stats, tr)
+}
 
-		// 从前往后的索引
-		actualIndex := bucketCount - 1 - bucketIndex
-		if actualIndex < 0 || actualIndex >= bucketCount {
+// buildRollupTimeline 基于聚合记录构建时间轴（tr 需已通过 alignTo 对齐到聚合边界）
+func (h *Handler) buildRollupTimeline(rollups []*storage.RollupRecord, tr timeRange, degradedWeight float64) []storage.TimePoint {
+	bucketCount := tr.bucketCount()
+	stats := make([]bucketStats, bucketCount)
+
+	for _, r := range rollups {
+		idx := tr.bucketIndex(time.Unix(r.BucketStart, 0))
+		if idx < 0 || idx >= bucketCount {
 			continue
 		}
+		stats[idx].addRollup(r, degradedWeight)
+	}
+
+	return finalizeTimeline(stats, tr)
+}
 
-		// 聚合统计
-		stat := &stats[actualIndex]
-		stat.total++
-		stat.weightedSuccess += availabilityWeight(record.Status, degradedWeight)
-		stat.latencySum += int64(record.Latency)
-		incrementStatusCount(&stat.statusCounts, record.Status, record.SubStatus)
+// finalizeTimeline 根据各 bucket 的聚合结果计算可用率和平均延迟
+func finalizeTimeline(stats []bucketStats, tr timeRange) []storage.TimePoint {
+	format := tr.format()
+	buckets := make([]storage.TimePoint, len(stats))
 
-		// 保留最新记录
-		if stat.last == nil || record.Timestamp > stat.last.Timestamp {
-			stat.last = record
+	for i := range stats {
+		bucketTime := tr.bucketStart(i)
+		buckets[i] = storage.TimePoint{
+			Time:         bucketTime.Format(format),
+			Timestamp:    bucketTime.Unix(),
+			Status:       -1, // 缺失标记
+			Latency:      0,
+			Availability: -1, // 缺失标记
 		}
-	}
 
-	// 根据聚合结果计算可用率和平均延迟
-	for i := 0; i < bucketCount; i++ {
 		stat := &stats[i]
 		buckets[i].StatusCounts = stat.statusCounts
 		if stat.total == 0 {
 			continue
 		}
 
-		// 计算可用率（使用权重）
-		buckets[i].Availability = (stat.weightedSuccess / float64(stat.total)) * 100
+		// 计算可用率（使用权重，维护期间的探测不计入；全部处于维护时视为缺失）
+		if counted := stat.total - stat.maintenance; counted > 0 {
+			buckets[i].Availability = (stat.weightedSuccess / float64(counted)) * 100
+		}
 
 		// 计算平均延迟（四舍五入）
 		avgLatency := float64(stat.latencySum) / float64(stat.total)
 		buckets[i].Latency = int(avgLatency + 0.5)
+		buckets[i].LatencyMin = stat.latencyMin
+		buckets[i].LatencyMax = stat.latencyMax
+		buckets[i].FirstByteLatency = stat.firstByte.avg()
+		buckets[i].FirstTokenLatency = stat.firstToken.avg()
+		buckets[i].StreamDuration = stat.streamDur.avg()
+		buckets[i].DNSDuration = stat.dns.avg()
+		buckets[i].ConnectDuration = stat.connect.avg()
+		buckets[i].TLSDuration = stat.tls.avg()
+		buckets[i].BodyReadDuration = stat.bodyRead.avg()
 
 		// 使用最新记录的状态和时间
-		if stat.last != nil {
-			buckets[i].Status = stat.last.Status
-			buckets[i].Timestamp = stat.last.Timestamp
-			buckets[i].Time = time.Unix(stat.last.Timestamp, 0).Format(format)
-		}
+		buckets[i].Status = stat.lastStatus
+		buckets[i].Timestamp = stat.lastTimestamp
+		buckets[i].Time = time.Unix(stat.lastTimestamp, 0).Format(format)
 	}
 
 	return buckets
 }
 
-// determineBucketStrategy 根据 period 确定 bucket 数量、窗口大小和时间格式
-func (h *Handler) determineBucketStrategy(period string) (count int, window time.Duration, format string) {
-	switch period {
-	case "24h", "1d":
-		return 24, time.Hour, "15:04"
-	case "7d":
-		return 7, 24 * time.Hour, "2006-01-02"
-	case "30d":
-		return 30, 24 * time.Hour, "2006-01-02"
-	default:
-		return 24, time.Hour, "15:04"
-	}
-}
-
 // UpdateConfig 更新配置（热更新时调用）
 func (h *Handler) UpdateConfig(cfg *config.AppConfig) {
 	h.cfgMu.Lock()
@@ -276,43 +449,61 @@ func availabilityWeight(status int, degradedWeight float64) float64 {
 		return 1.0
 	case 2: // 黄色（降级：慢响应或429）
 		return degradedWeight
-	default: // 红色（不可用）或灰色（未配置）
+	default: // 红色（不可用）或灰色（未配置）；维护中（4）的探测由调用方从分母中排除
 		return 0.0
 	}
 }
 
-// incrementStatusCount 统计每种状态及细分出现次数
-func incrementStatusCount(counts *storage.StatusCounts, status int, subStatus storage.SubStatus) {
+// addStatusCount 按次数 n 统计每种状态及细分出现次数
+func addStatusCount(counts *storage.StatusCounts, status int, subStatus storage.SubStatus, n int) {
 	switch status {
 	case 1: // 绿色
-		counts.Available++
+		counts.Available += n
 	case 2: // 黄色
-		counts.Degraded++
+		counts.Degraded += n
 		// 黄色细分
 		switch subStatus {
 		case storage.SubStatusSlowLatency:
-			counts.SlowLatency++
+			counts.SlowLatency += n
 		case storage.SubStatusRateLimit:
-			counts.RateLimit++
+			counts.RateLimit += n
+		case storage.SubStatusFlaky:
+			counts.Flaky += n
+		case storage.SubStatusCertExpiring:
+			counts.CertExpiring += n
 		}
 	case 0: // 红色
-		counts.Unavailable++
+		counts.Unavailable += n
 		// 红色细分
 		switch subStatus {
 		case storage.SubStatusServerError:
-			counts.ServerError++
+			counts.ServerError += n
 		case storage.SubStatusClientError:
-			counts.ClientError++
+			counts.ClientError += n
 		case storage.SubStatusAuthError:
-			counts.AuthError++
+			counts.AuthError += n
 		case storage.SubStatusInvalidRequest:
-			counts.InvalidRequest++
+			counts.InvalidRequest += n
 		case storage.SubStatusNetworkError:
-			counts.NetworkError++
+			counts.NetworkError += n
 		case storage.SubStatusContentMismatch:
-			counts.ContentMismatch++
+			counts.ContentMismatch += n
+		case storage.SubStatusStreamError:
+			counts.StreamError += n
+		case storage.SubStatusUnexpectedStatus:
+			counts.UnexpectedStatus += n
+		case storage.SubStatusHeaderMismatch:
+			counts.HeaderMismatch += n
+		case storage.SubStatusCertExpired:
+			counts.CertExpired += n
+		case storage.SubStatusCertHostnameMismatch:
+			counts.CertHostnameMismatch += n
+		case storage.SubStatusCertUnknownAuthority:
+			counts.CertUnknownAuthority += n
 		}
+	case 4: // 维护中
+		counts.Maintenance += n
 	default: // 灰色（3）或其他
-		counts.Missing++
+		counts.Missing += n
 	}
 }
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/storage"
+)
+
+// 故障事件查询数量限制
+const (
+	defaultIncidentLimit = 100
+	maxIncidentLimit     = 1000
+)
+
+// IncidentResult API 返回的故障事件
+type IncidentResult struct {
+	*storage.Incident
+	Open     bool  `json:"open"`     // 是否仍在进行中
+	Duration int64 `json:"duration"` // 持续时长（秒），进行中的故障计算到当前时间
+}
+
+// GetIncidents 查询故障事件
+// 参数：provider/service/channel（默认 all）、state（open/closed/all）、
+// period（默认 30d）或 from/to（Unix 秒或 RFC3339）、limit（默认 100，最大 1000）
+func (h *Handler) GetIncidents(c *gin.Context) {
+	filter := storage.IncidentFilter{
+		Provider: allToEmpty(c.DefaultQuery("provider", "all")),
+		Service:  allToEmpty(c.DefaultQuery("service", "all")),
+		Channel:  allToEmpty(c.DefaultQuery("channel", "all")),
+		State:    allToEmpty(c.DefaultQuery("state", "all")),
+	}
+	if filter.State != "" && filter.State != storage.IncidentStateOpen && filter.State != storage.IncidentStateClosed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("无效的 state: %s", filter.State)})
+		return
+	}
+
+	tr, err := parseTimeRange(c, "30d")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Since = tr.from.Unix()
+	filter.Until = tr.to.Unix()
+	now := time.Now()
+
+	filter.Limit = defaultIncidentLimit
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("无效的 limit: %s", v)})
+			return
+		}
+		filter.Limit = min(limit, maxIncidentLimit)
+	}
+
+	incidents, err := h.storage.GetIncidents(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("查询故障事件失败: %v", err),
+		})
+		return
+	}
+
+	results := make([]IncidentResult, 0, len(incidents))
+	var totalDuration int64
+	openCount := 0
+	for _, inc := range incidents {
+		r := IncidentResult{Incident: inc, Open: inc.IsOpen(), Duration: inc.Duration(now.Unix())}
+		if r.Open {
+			openCount++
+		}
+		totalDuration += r.Duration
+		results = append(results, r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{
+			"from":           filter.Since,
+			"to":             filter.Until,
+			"count":          len(results),
+			"open":           openCount,
+			"total_duration": totalDuration,
+		},
+		"data": results,
+	})
+}
+
+// allToEmpty 将查询参数中的 "all" 转换为空（不过滤）
+func allToEmpty(v string) string {
+	if v == "all" {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/agent"
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+// maxIngestBodySize 单次上报请求体上限
+const maxIngestBodySize = 8 << 20
+
+// maxIngestClockSkew 允许上报记录的时间戳超前中心节点的最大时长
+const maxIngestClockSkew = 5 * time.Minute
+
+// Ingest 接收探测节点上报的探测结果（POST /api/ingest，Authorization: Bearer <token>）
+// 记录的地域由凭证对应的 ingest.agents[].region 决定；未在本实例配置的监控项会被拒绝
+func (h *Handler) Ingest(c *gin.Context) {
+	token := bearerToken(c)
+
+	h.cfgMu.RLock()
+	region := ""
+	for _, a := range h.config.Ingest.Agents {
+		if tokenEqual(token, a.Token) {
+			region = a.Region
+		}
+	}
+	configured := make(map[string]*config.ServiceConfig, len(h.config.Monitors))
+	for i := range h.config.Monitors {
+		task := h.config.Monitors[i]
+		configured[task.Provider+"/"+task.Service+"/"+task.Channel] = &task
+	}
+	h.cfgMu.RUnlock()
+
+	if region == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的上报凭证"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxIngestBodySize)
+	var batch agent.Batch
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("解析上报数据失败: %v", err)})
+		return
+	}
+	if len(batch.Records) > agent.MaxBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("单次最多上报 %d 条记录", agent.MaxBatchSize)})
+		return
+	}
+
+	latest := time.Now().Add(maxIngestClockSkew).Unix()
+	var result agent.IngestResult
+	accepted := make([]*storage.ProbeRecord, 0, len(batch.Records))
+	for _, r := range batch.Records {
+		if configured[r.Provider+"/"+r.Service+"/"+r.Channel] == nil || !validIngestStatus(r.Status) || r.Timestamp <= 0 || r.Timestamp > latest {
+			result.Rejected++
+			continue
+		}
+		accepted = append(accepted, r.ProbeRecord(region))
+	}
+
+	// 整批在同一事务中写入：失败时探测节点会重发整批，不能留下部分已写入的记录
+	if err := h.storage.SaveRecords(accepted); err != nil {
+		log.Printf("[API] 保存 %s 上报的记录失败: %v", region, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("保存记录失败: %v", err)})
+		return
+	}
+	result.Accepted = len(accepted)
+
+	// 与本地探测结果一样通知告警、故障事件和指标（观察者按地域分别跟踪状态）
+	if h.ingestObserver != nil {
+		for _, record := range accepted {
+			h.ingestObserver.OnProbeResult(configured[record.Provider+"/"+record.Service+"/"+record.Channel], monitor.ResultFromRecord(record))
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// validIngestStatus 上报记录的状态是否合法（绿、红、黄，或探测节点按自身配置记录的维护状态）
+func validIngestStatus(status int) bool {
+	switch status {
+	case 0, 1, 2, 4:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/agent"
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+func TestIngestFromAgentAndFilterByRegion(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+
+	cfg := &config.AppConfig{
+		Region:   "hangzhou",
+		Monitors: []config.ServiceConfig{{Provider: "demo", Service: "cc"}},
+		Ingest:   config.IngestConfig{Agents: []config.IngestAgent{{Region: "shanghai", Token: "secret"}}},
+	}
+	h := NewHandler(store, cfg, nil)
+	observer := &recordingObserver{}
+	h.ingestObserver = observer
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST(agent.IngestPath, h.Ingest)
+	router.GET("/api/status", h.GetStatus)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	now := time.Now().Unix()
+	if err := store.SaveRecord(&storage.ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Latency: 100, Timestamp: now - 60, Region: "hangzhou"}); err != nil {
+		t.Fatalf("save local record: %v", err)
+	}
+
+	pusher := agent.NewPusher(config.AgentConfig{ServerURL: srv.URL, Token: "secret", BufferSize: 10})
+	pusher.SaveRecord(&storage.ProbeRecord{Provider: "demo", Service: "cc", Status: 0, SubStatus: storage.SubStatusNetworkError, Latency: 5000, Timestamp: now - 30})
+	pusher.SaveRecord(&storage.ProbeRecord{Provider: "unknown", Service: "cc", Status: 1, Timestamp: now - 30})
+	if err := pusher.Flush(t.Context()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if pusher.Pending() != 0 {
+		t.Fatalf("expected buffer to be drained, %d pending", pusher.Pending())
+	}
+
+	latest, err := store.GetLatest("demo", "cc", "", "shanghai")
+	if err != nil || latest == nil || latest.Status != 0 || latest.Region != "shanghai" {
+		t.Fatalf("unexpected ingested record: %+v (err=%v)", latest, err)
+	}
+	if all, _ := store.GetHistory("demo", "cc", "", "", time.Unix(0, 0), time.Now()); len(all) != 2 {
+		t.Fatalf("unconfigured monitor should be rejected, got %d records", len(all))
+	}
+
+	// 接受的记录按地域通知观察者
+	if len(observer.results) != 1 || observer.results[0].Region != "shanghai" || observer.results[0].Status != 0 ||
+		observer.results[0].SubStatus != storage.SubStatusNetworkError || observer.cfgs[0].Provider != "demo" {
+		t.Fatalf("unexpected observed results: %+v", observer.results)
+	}
+
+	// 凭证错误时记录保留在缓存中
+	intruder := agent.NewPusher(config.AgentConfig{ServerURL: srv.URL, Token: "wrong", BufferSize: 10})
+	intruder.SaveRecord(&storage.ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Timestamp: now})
+	if err := intruder.Flush(t.Context()); err == nil || intruder.Pending() != 1 {
+		t.Fatalf("expected unauthorized flush to fail and keep the record, err=%v pending=%d", err, intruder.Pending())
+	}
+
+	for region, want := range map[string]int{"": 0, "shanghai": 0, "hangzhou": 1} {
+		resp, err := http.Get(srv.URL + "/api/status?region=" + region)
+		if err != nil {
+			t.Fatalf("get status: %v", err)
+		}
+		var body struct {
+			Data []MonitorResult `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil || len(body.Data) != 1 {
+			t.Fatalf("region %q: decode status: %v", region, err)
+		}
+		result := body.Data[0]
+		if result.Current == nil || result.Current.Status != want {
+			t.Fatalf("region %q: expected current status %d, got %+v", region, want, result.Current)
+		}
+		if len(result.Regions) != 2 || result.Regions[0].Region != "hangzhou" || result.Regions[1].Status != 0 {
+			t.Fatalf("region %q: unexpected per-region status: %+v", region, result.Regions)
+		}
+	}
+}
+
+// recordingObserver 记录收到的探测结果
+type recordingObserver struct {
+	mu      sync.Mutex
+	cfgs    []*config.ServiceConfig
+	results []*monitor.ProbeResult
+}
+
+func (o *recordingObserver) OnProbeResult(cfg *config.ServiceConfig, result *monitor.ProbeResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cfgs = append(o.cfgs, cfg)
+	o.results = append(o.results, result)
+}
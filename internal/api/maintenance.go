@@ -0,0 +1,190 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// MaintenanceStatus 维护窗口及其正在进行（或下一次）的维护时段
+type MaintenanceStatus struct {
+	ID       int64  `json:"id"`     // API 创建的窗口 ID（配置文件中的窗口为 0）
+	Source   string `json:"source"` // config / api
+	Provider string `json:"provider"`
+	Service  string `json:"service"`
+	Channel  string `json:"channel"`
+	Schedule string `json:"schedule,omitempty"` // 周期性窗口的 cron 表达式
+	Duration string `json:"duration,omitempty"` // 周期性窗口每次的持续时间
+	Timezone string `json:"timezone,omitempty"`
+	Mode     string `json:"mode"`
+	Reason   string `json:"reason"`
+	Active   bool   `json:"active"` // 当前是否处于维护中
+	Start    int64  `json:"start"`  // 正在进行或下一次维护的开始时间（Unix 秒），已结束的一次性窗口为原开始时间
+	End      int64  `json:"end"`    // 对应的结束时间（Unix 秒）
+}
+
+// newMaintenanceStatus 由维护窗口生成 API 返回结构
+func newMaintenanceStatus(w *config.MaintenanceWindow, now time.Time) MaintenanceStatus {
+	status := MaintenanceStatus{
+		ID:       w.ID,
+		Source:   "config",
+		Provider: w.Provider,
+		Service:  w.Service,
+		Channel:  w.Channel,
+		Schedule: w.Schedule,
+		Duration: w.Duration,
+		Timezone: w.Timezone,
+		Mode:     w.Mode,
+		Reason:   w.Reason,
+	}
+	if w.ID > 0 {
+		status.Source = "api"
+	}
+
+	if iv, ok := w.NextOccurrence(now); ok {
+		status.Active = !now.Before(iv.Start)
+		status.Start, status.End = iv.Start.Unix(), iv.End.Unix()
+	} else if !w.Recurring() {
+		status.Start, status.End = w.StartTime.Unix(), w.EndTime.Unix()
+	}
+	return status
+}
+
+// activeMaintenance 监控项当前所处的维护窗口（不在维护中时返回 nil）
+func (h *Handler) activeMaintenance(task *config.ServiceConfig, now time.Time) *MaintenanceStatus {
+	if h.maintenance == nil {
+		return nil
+	}
+	w := h.maintenance.Active(task.Provider, task.Service, task.Channel, now)
+	if w == nil {
+		return nil
+	}
+	status := newMaintenanceStatus(w, now)
+	return &status
+}
+
+// annotateMaintenance 为与维护时段有交集的 bucket 添加维护标注
+func (h *Handler) annotateMaintenance(timeline []storage.TimePoint, tr timeRange, task *config.ServiceConfig) {
+	if h.maintenance == nil || len(timeline) == 0 {
+		return
+	}
+	occurrences := h.maintenance.Occurrences(task.Provider, task.Service, task.Channel, tr.bucketStart(0), tr.to)
+	for _, o := range occurrences {
+		note := storage.MaintenanceNote{Reason: o.Window.Reason, Start: o.Start.Unix(), End: o.End.Unix()}
+		for i := max(tr.bucketIndex(o.Start), 0); i < len(timeline) && tr.bucketStart(i).Before(o.End); i++ {
+			timeline[i].Maintenance = append(timeline[i].Maintenance, note)
+		}
+	}
+}
+
+// GetMaintenance 查询维护窗口（GET /api/maintenance）
+// 参数：provider/service（默认 all，service 为空的窗口匹配 provider 下全部服务）
+func (h *Handler) GetMaintenance(c *gin.Context) {
+	qProvider := c.DefaultQuery("provider", "all")
+	qService := c.DefaultQuery("service", "all")
+
+	response := make([]MaintenanceStatus, 0)
+	if h.maintenance != nil {
+		now := time.Now()
+		for _, w := range h.maintenance.Windows() {
+			if qProvider != "all" && qProvider != w.Provider {
+				continue
+			}
+			if qService != "all" && w.Service != "" && qService != w.Service {
+				continue
+			}
+			response = append(response, newMaintenanceStatus(&w, now))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{"count": len(response)},
+		"data": response,
+	})
+}
+
+// CreateMaintenance 新建维护窗口（POST /api/maintenance，Authorization: Bearer <maintenance.token>）
+func (h *Handler) CreateMaintenance(c *gin.Context) {
+	var w config.MaintenanceWindow
+	if err := c.ShouldBindJSON(&w); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("解析维护窗口失败: %v", err)})
+		return
+	}
+	w.ID = 0
+
+	h.cfgMu.RLock()
+	matched := h.config.HasMonitorMatching(&w)
+	h.cfgMu.RUnlock()
+	if !matched {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("没有匹配的监控项: provider=%s, service=%s, channel=%s", w.Provider, w.Service, w.Channel),
+		})
+		return
+	}
+
+	if err := h.maintenance.Add(&w); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "create maintenance #%d %s/%s/%s %s", w.ID, w.Provider, w.Service, w.Channel, w.Reason)
+
+	c.JSON(http.StatusCreated, newMaintenanceStatus(&w, time.Now()))
+}
+
+// DeleteMaintenance 删除通过 API 创建的维护窗口（DELETE /api/maintenance/:id）
+func (h *Handler) DeleteMaintenance(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的维护窗口 ID"})
+		return
+	}
+
+	found, err := h.maintenance.Delete(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("删除维护窗口失败: %v", err)})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "维护窗口不存在（配置文件中的窗口需修改配置文件）"})
+		return
+	}
+	audit(c, "delete maintenance #%d", id)
+
+	c.Status(http.StatusNoContent)
+}
+
+// maintenanceAuth 校验维护窗口管理凭证（maintenance.token 或任一 admin.tokens），并与管理 API 一样记录审计日志
+func (h *Handler) maintenanceAuth(c *gin.Context) {
+	token := bearerToken(c)
+
+	h.cfgMu.RLock()
+	expected := h.config.Maintenance.Token
+	actor := ""
+	if expected != "" && tokenEqual(token, expected) {
+		actor = "maintenance-token"
+	}
+	for _, t := range h.config.Admin.Tokens {
+		if tokenEqual(token, t.Token) {
+			actor = t.Name
+		}
+	}
+	enabled := expected != "" || h.config.Admin.Enabled()
+	h.cfgMu.RUnlock()
+
+	switch {
+	case h.maintenance == nil || !enabled:
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "未配置 maintenance.token 或 admin.tokens，维护窗口只能在配置文件中声明"})
+	case actor == "":
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "无效的维护窗口管理凭证"})
+	default:
+		c.Set(auditActorKey, actor)
+		c.Next()
+	}
+	logAudit(c, actor)
+}
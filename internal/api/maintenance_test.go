@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/maintenance"
+	"monitor/internal/storage"
+)
+
+func TestMaintenanceExcludedFromAvailability(t *testing.T) {
+	t.Parallel()
+
+	end := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	tr := timeRange{from: end.Add(-3 * time.Hour), to: end, bucket: time.Hour}
+	start := tr.bucketStart(0).Unix()
+
+	records := []*storage.ProbeRecord{
+		rec(1, storage.SubStatusNone, 100, start+60),
+		rec(0, storage.SubStatusServerError, 100, start+120),
+		rec(4, storage.SubStatusServerError, 100, start+3600+60), // 第二个 bucket 全部处于维护
+		rec(4, storage.SubStatusNone, 100, start+3600+120),
+		rec(4, storage.SubStatusServerError, 100, start+7200+60),
+		rec(1, storage.SubStatusNone, 100, start+7200+120),
+	}
+
+	window := config.MaintenanceWindow{Provider: "demo", Service: "cc", Reason: "升级",
+		Start: time.Unix(start+3600, 0).Format(time.RFC3339), End: time.Unix(start+7200+90, 0).Format(time.RFC3339)}
+	if err := window.Normalize(); err != nil {
+		t.Fatalf("normalize window: %v", err)
+	}
+	calendar := maintenance.NewCalendar(nil)
+	calendar.UpdateConfig(&config.AppConfig{Maintenance: config.MaintenanceConfig{Windows: []config.MaintenanceWindow{window}}})
+
+	h := &Handler{maintenance: calendar}
+	timeline := h.buildTimeline(records, tr, 0.7)
+	h.annotateMaintenance(timeline, tr, &config.ServiceConfig{Provider: "demo", Service: "cc"})
+
+	if timeline[0].Availability != 50 || len(timeline[0].Maintenance) != 0 {
+		t.Fatalf("unexpected first bucket: %+v", timeline[0])
+	}
+	if timeline[1].Availability != -1 || timeline[1].Status != 4 || timeline[1].StatusCounts.Maintenance != 2 {
+		t.Fatalf("bucket fully in maintenance should be excluded: %+v", timeline[1])
+	}
+	if timeline[2].Availability != 100 || len(timeline[2].Maintenance) != 1 || timeline[2].Maintenance[0].Reason != "升级" {
+		t.Fatalf("unexpected last bucket: %+v", timeline[2])
+	}
+
+	// SLA 报告：维护期间的探测不计入可用率，进入维护时结束进行中的故障
+	stats := buildReport(records, end.Unix(), 0.7)
+	if math.Abs(stats.Availability-200.0/3) > 1e-9 || stats.Outages != 1 || stats.Downtime != 3600-60 || stats.StatusCounts.Maintenance != 3 {
+		t.Fatalf("unexpected report: %+v", stats)
+	}
+}
+
+func TestMaintenanceAPI(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+
+	cfg := &config.AppConfig{
+		Monitors:    []config.ServiceConfig{{Provider: "demo", Service: "cc"}},
+		Maintenance: config.MaintenanceConfig{Token: "secret"},
+		Admin:       config.AdminConfig{Tokens: []config.AdminToken{{Name: "ops", Token: "admin-secret"}}},
+	}
+	calendar := maintenance.NewCalendar(store)
+	calendar.UpdateConfig(cfg)
+	h := NewHandler(store, cfg, calendar)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/maintenance", h.GetMaintenance)
+	router.POST("/api/maintenance", h.maintenanceAuth, h.CreateMaintenance)
+	router.DELETE("/api/maintenance/:id", h.maintenanceAuth, h.DeleteMaintenance)
+
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	now := time.Now()
+	window := gin.H{"provider": "demo", "service": "cc", "reason": "数据库迁移",
+		"start": now.Add(-time.Minute).Format(time.RFC3339), "end": now.Add(time.Hour).Format(time.RFC3339)}
+
+	if w := do(http.MethodPost, "/api/maintenance", "wrong", window); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong token, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/maintenance", "secret", gin.H{"provider": "unknown", "schedule": "0 3 * * *", "duration": "1h"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unmatched provider, got %d", w.Code)
+	}
+
+	w := do(http.MethodPost, "/api/maintenance", "secret", window)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body)
+	}
+	var created MaintenanceStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.ID == 0 || !created.Active || created.Source != "api" {
+		t.Fatalf("unexpected created window: %+v (err=%v)", created, err)
+	}
+
+	// 其他副本重新加载后可见
+	other := maintenance.NewCalendar(store)
+	if err := other.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if active := other.Active("demo", "cc", "", now); active == nil || active.Reason != "数据库迁移" {
+		t.Fatalf("expected window to be active after reload, got %+v", active)
+	}
+
+	var list struct {
+		Data []MaintenanceStatus `json:"data"`
+	}
+	w = do(http.MethodGet, "/api/maintenance?provider=demo", "", nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("unexpected list: %s (err=%v)", w.Body, err)
+	}
+
+	path := fmt.Sprintf("/api/maintenance/%d", created.ID)
+	// 管理 API 凭证同样可以管理维护窗口
+	if w := do(http.MethodDelete, path, "admin-secret", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, path, "secret", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for deleted window, got %d", w.Code)
+	}
+	if calendar.Active("demo", "cc", "", now) != nil {
+		t.Fatal("deleted window should no longer be active")
+	}
+}
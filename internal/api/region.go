@@ -0,0 +1,27 @@
+package api
+
+import "monitor/internal/storage"
+
+// RegionStatus 单个探测地域的最新状态
+type RegionStatus struct {
+	Region    string            `json:"region"`
+	Status    int               `json:"status"`
+	SubStatus storage.SubStatus `json:"sub_status"`
+	Latency   int               `json:"latency"`
+	Timestamp int64             `json:"timestamp"`
+}
+
+// newRegionStatuses 由各地域的最新记录生成地域状态列表（无记录时返回空列表）
+func newRegionStatuses(records []*storage.ProbeRecord) []RegionStatus {
+	regions := make([]RegionStatus, 0, len(records))
+	for _, r := range records {
+		regions = append(regions, RegionStatus{
+			Region:    r.Region,
+			Status:    r.Status,
+			SubStatus: r.SubStatus,
+			Latency:   r.Latency,
+			Timestamp: r.Timestamp,
+		})
+	}
+	return regions
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/storage"
+)
+
+// LatencyPercentiles 延迟分位数（毫秒，仅统计非红色探测）
+type LatencyPercentiles struct {
+	Avg int `json:"avg"`
+	P50 int `json:"p50"`
+	P95 int `json:"p95"`
+	P99 int `json:"p99"`
+}
+
+// ReportStats 单个监控项在时间范围内的汇总统计
+type ReportStats struct {
+	TotalProbes   int                  `json:"total_probes"`
+	Availability  float64              `json:"availability"`   // 加权可用率（0-100，不含维护期间的探测），无数据时为 -1
+	Outages       int                  `json:"outages"`        // 故障次数（连续红色探测算一次）
+	Downtime      int64                `json:"downtime"`       // 故障总时长（秒）
+	MTTR          int64                `json:"mttr"`           // 平均恢复时间（秒），无故障时为 -1
+	MTBF          int64                `json:"mtbf"`           // 平均故障间隔（秒），无故障时为 -1
+	LongestOutage int64                `json:"longest_outage"` // 最长故障时长（秒）
+	Latency       LatencyPercentiles   `json:"latency"`
+	StatusCounts  storage.StatusCounts `json:"status_counts"` // 各状态及细分状态计数
+}
+
+// ReportResult API 返回的监控项报告
+type ReportResult struct {
+	Provider string `json:"provider"`
+	Service  string `json:"service"`
+	Channel  string `json:"channel"`
+	Category string `json:"category"`
+	Sponsor  string `json:"sponsor"`
+	ReportStats
+}
+
+// GetReport 生成 SLA 报告
+// 参数：provider/service（默认 all）、period（默认 30d）或 from/to（Unix 秒或 RFC3339）、
+// region（默认为本实例的地域；不同地域的探测交错会导致故障次数失真，因此不汇总全部地域）
+func (h *Handler) GetReport(c *gin.Context) {
+	qProvider := c.DefaultQuery("provider", "all")
+	qService := c.DefaultQuery("service", "all")
+
+	tr, err := parseTimeRange(c, "30d")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to := tr.from.Unix(), min(tr.to.Unix(), time.Now().Unix())
+
+	h.cfgMu.RLock()
+	monitors := h.config.Monitors
+	degradedWeight := h.config.DegradedWeight
+	region := c.DefaultQuery("region", h.config.Region)
+	h.cfgMu.RUnlock()
+
+	response := make([]ReportResult, 0)
+	seen := make(map[string]bool)
+	for _, task := range monitors {
+		if qProvider != "all" && qProvider != task.Provider {
+			continue
+		}
+		if qService != "all" && qService != task.Service {
+			continue
+		}
+
+		key := task.Provider + "/" + task.Service + "/" + task.Channel
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		history, err := h.storage.GetHistory(task.Provider, task.Service, task.Channel, region, time.Unix(from, 0), time.Unix(to, 0))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("查询历史失败: %v", err),
+			})
+			return
+		}
+
+		response = append(response, ReportResult{
+			Provider:    task.Provider,
+			Service:     task.Service,
+			Channel:     task.Channel,
+			Category:    task.Category,
+			Sponsor:     task.Sponsor,
+			ReportStats: buildReport(history, to, degradedWeight),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meta": gin.H{
+			"from":   from,
+			"to":     to,
+			"region": region,
+			"count":  len(response),
+		},
+		"data": response,
+	})
+}
+
+// buildReport 汇总按时间升序排列的 [from, to) 内的探测记录（忽略 to 及之后的记录）
+// 连续的红色探测视为一次故障，故障从首个红色探测开始，到随后首个非红色探测结束；
+// 进入计划维护时故障在维护开始处结束，维护期间的探测不计入可用率；
+// 范围结束时仍未恢复的故障计算到最后一条记录（或 to）。
+func buildReport(records []*storage.ProbeRecord, to int64, degradedWeight float64) ReportStats {
+	stats := ReportStats{Availability: -1, MTTR: -1, MTBF: -1}
+
+	var weighted float64
+	var latencies []int
+	var latencySum int64
+	var outageStart int64 = -1
+	var first, last int64
+
+	closeOutage := func(end int64) {
+		d := max(end-outageStart, 0)
+		stats.Outages++
+		stats.Downtime += d
+		stats.LongestOutage = max(stats.LongestOutage, d)
+		outageStart = -1
+	}
+
+	for _, r := range records {
+		if r.Timestamp >= to {
+			break
+		}
+		if stats.TotalProbes == 0 {
+			first = r.Timestamp
+		}
+		last = r.Timestamp
+		stats.TotalProbes++
+		weighted += availabilityWeight(r.Status, degradedWeight)
+		addStatusCount(&stats.StatusCounts, r.Status, r.SubStatus, 1)
+
+		switch r.Status {
+		case 0:
+			if outageStart < 0 {
+				outageStart = r.Timestamp
+			}
+		case 1, 2:
+			latencies = append(latencies, r.Latency)
+			latencySum += int64(r.Latency)
+			if outageStart >= 0 {
+				closeOutage(r.Timestamp)
+			}
+		case 4:
+			if outageStart >= 0 {
+				closeOutage(r.Timestamp)
+			}
+		}
+	}
+
+	if stats.TotalProbes == 0 {
+		return stats
+	}
+	if outageStart >= 0 {
+		closeOutage(max(last, min(to, time.Now().Unix())))
+	}
+
+	if counted := stats.TotalProbes - stats.StatusCounts.Maintenance; counted > 0 {
+		stats.Availability = weighted / float64(counted) * 100
+	}
+
+	if stats.Outages > 0 {
+		observed := max(last, to) - first
+		stats.MTTR = stats.Downtime / int64(stats.Outages)
+		stats.MTBF = max(observed-stats.Downtime, 0) / int64(stats.Outages)
+	}
+
+	if len(latencies) > 0 {
+		sort.Ints(latencies)
+		stats.Latency = LatencyPercentiles{
+			Avg: int(float64(latencySum)/float64(len(latencies)) + 0.5),
+			P50: percentile(latencies, 50),
+			P95: percentile(latencies, 95),
+			P99: percentile(latencies, 99),
+		}
+	}
+
+	return stats
+}
+
+// percentile 最近秩法计算分位数（sorted 需已升序排列且非空）
+func percentile(sorted []int, p float64) int {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[min(rank, len(sorted))-1]
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"testing"
+
+	"monitor/internal/storage"
+)
+
+func rec(status int, sub storage.SubStatus, latency int, ts int64) *storage.ProbeRecord {
+	return &storage.ProbeRecord{Status: status, SubStatus: sub, Latency: latency, Timestamp: ts}
+}
+
+func TestBuildReport(t *testing.T) {
+	t.Parallel()
+
+	records := []*storage.ProbeRecord{
+		rec(1, storage.SubStatusNone, 100, 0),
+		rec(1, storage.SubStatusNone, 200, 60),
+		rec(0, storage.SubStatusServerError, 5000, 120), // 故障 1：120 -> 240
+		rec(0, storage.SubStatusNetworkError, 0, 180),
+		rec(2, storage.SubStatusSlowLatency, 3000, 240),
+		rec(1, storage.SubStatusNone, 300, 300),
+		rec(0, storage.SubStatusServerError, 100, 360), // 故障 2：360 -> 420
+		rec(1, storage.SubStatusNone, 400, 420),
+		rec(1, storage.SubStatusNone, 500, 9999), // 超出范围，忽略
+	}
+
+	stats := buildReport(records, 600, 0.5)
+
+	if stats.TotalProbes != 8 {
+		t.Fatalf("expected 8 probes, got %d", stats.TotalProbes)
+	}
+	// (4 绿 + 0.5 黄) / 8
+	if want := 4.5 / 8 * 100; stats.Availability != want {
+		t.Fatalf("expected availability %.4f, got %.4f", want, stats.Availability)
+	}
+	if stats.Outages != 2 || stats.Downtime != 180 || stats.LongestOutage != 120 {
+		t.Fatalf("unexpected outages: %+v", stats)
+	}
+	if stats.MTTR != 90 {
+		t.Fatalf("expected MTTR 90, got %d", stats.MTTR)
+	}
+	// 观察时长 600 - 故障 180 = 420，两次故障
+	if stats.MTBF != 210 {
+		t.Fatalf("expected MTBF 210, got %d", stats.MTBF)
+	}
+	// 非红色延迟：100, 200, 300, 400, 3000
+	if stats.Latency.P50 != 300 || stats.Latency.P95 != 3000 || stats.Latency.P99 != 3000 || stats.Latency.Avg != 800 {
+		t.Fatalf("unexpected latency percentiles: %+v", stats.Latency)
+	}
+	if stats.StatusCounts.ServerError != 2 || stats.StatusCounts.NetworkError != 1 || stats.StatusCounts.SlowLatency != 1 {
+		t.Fatalf("unexpected status counts: %+v", stats.StatusCounts)
+	}
+}
+
+func TestBuildReportNoData(t *testing.T) {
+	t.Parallel()
+
+	stats := buildReport(nil, 600, 0.7)
+	if stats.Availability != -1 || stats.MTTR != -1 || stats.MTBF != -1 || stats.TotalProbes != 0 {
+		t.Fatalf("unexpected empty report: %+v", stats)
+	}
+}
+
+func TestBuildReportOngoingOutage(t *testing.T) {
+	t.Parallel()
+
+	records := []*storage.ProbeRecord{
+		rec(1, storage.SubStatusNone, 100, 0),
+		rec(0, storage.SubStatusServerError, 100, 100),
+		rec(0, storage.SubStatusServerError, 100, 200),
+	}
+
+	stats := buildReport(records, 500, 0.7)
+	if stats.Outages != 1 || stats.Downtime != 400 {
+		t.Fatalf("expected ongoing outage counted to range end, got %+v", stats)
+	}
+}
@@ -14,8 +14,12 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"monitor/internal/agent"
 	"monitor/internal/buildinfo"
 	"monitor/internal/config"
+	"monitor/internal/maintenance"
+	"monitor/internal/metrics"
+	"monitor/internal/monitor"
 	"monitor/internal/storage"
 )
 
@@ -30,8 +34,8 @@ type Server struct {
 	port       string
 }
 
-// NewServer 创建服务器
-func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Server {
+// NewServer 创建服务器（collector 为 nil 时不注册 /metrics）
+func NewServer(store storage.Storage, cfg *config.AppConfig, port string, collector *metrics.Collector, calendar *maintenance.Calendar) *Server {
 	// 设置gin模式
 	gin.SetMode(gin.ReleaseMode)
 
@@ -56,10 +60,34 @@ func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Serve
 	router.Use(cors.New(corsConfig))
 
 	// 创建处理器
-	handler := NewHandler(store, cfg)
+	handler := NewHandler(store, cfg, calendar)
 
 	// 注册 API 路由
 	router.GET("/api/status", handler.GetStatus)
+	router.GET("/api/incidents", handler.GetIncidents)
+	router.GET("/api/report", handler.GetReport)
+	router.GET("/api/detail", handler.GetDetail)
+	router.GET("/api/maintenance", handler.GetMaintenance)
+
+	// 维护窗口管理（按 maintenance.token 或 admin.tokens 校验，记录审计日志）
+	router.POST("/api/maintenance", handler.maintenanceAuth, handler.CreateMaintenance)
+	router.DELETE("/api/maintenance/:id", handler.maintenanceAuth, handler.DeleteMaintenance)
+
+	// 探测节点上报（按 ingest.agents 中的凭证校验）
+	router.POST(agent.IngestPath, handler.Ingest)
+
+	// 管理 API（按 admin.tokens 校验，所有请求记录审计日志）
+	admin := router.Group("/api/admin", handler.adminAuth)
+	admin.GET("/monitors", handler.AdminListMonitors)
+	admin.POST("/monitors", handler.AdminCreateMonitor)
+	admin.PUT("/monitors", handler.AdminUpdateMonitor)
+	admin.DELETE("/monitors", handler.AdminDeleteMonitor)
+	admin.POST("/monitors/import", handler.AdminImportMonitors)
+	admin.POST("/monitors/pause", handler.AdminPauseMonitor)
+	admin.POST("/monitors/resume", handler.AdminResumeMonitor)
+	admin.POST("/monitors/probe", handler.AdminProbeMonitor)
+	admin.POST("/reload", handler.AdminReload)
+	admin.POST("/retention", handler.AdminRunRetention)
 
 	// 版本信息 API
 	router.GET("/api/version", func(c *gin.Context) {
@@ -72,6 +100,11 @@ func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Serve
 		})
 	})
 
+	// Prometheus 指标
+	if collector != nil {
+		router.GET("/metrics", gin.WrapH(collector.Handler()))
+	}
+
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -100,6 +133,7 @@ func (s *Server) Start() error {
 	log.Printf("\n🚀 监控服务已启动")
 	log.Printf("👉 Web 界面: http://localhost:%s", s.port)
 	log.Printf("👉 API 地址: http://localhost:%s/api/status", s.port)
+	log.Printf("👉 Prometheus 指标: http://localhost:%s/metrics", s.port)
 	log.Printf("👉 健康检查: http://localhost:%s/health\n", s.port)
 
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -125,6 +159,16 @@ func (s *Server) UpdateConfig(cfg *config.AppConfig) {
 	s.handler.UpdateConfig(cfg)
 }
 
+// SetAdmin 注入管理 API 依赖的运行时操作（需在 Start 之前调用）
+func (s *Server) SetAdmin(hooks AdminHooks) {
+	s.handler.admin = hooks
+}
+
+// SetIngestObserver 设置探测节点上报结果的观察者（通常为调度器，复用本地探测的告警、故障事件和指标通知），需在 Start 之前调用
+func (s *Server) SetIngestObserver(o monitor.ResultObserver) {
+	s.handler.ingestObserver = o
+}
+
 // setupStaticFiles 设置静态文件服务（前端）
 func setupStaticFiles(router *gin.Engine) {
 	// 获取嵌入的前端文件系统
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+func TestRollupTimelineMatchesRaw(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+
+	end := time.Now().Truncate(time.Hour).Add(-time.Hour)
+	start := end.Add(-72 * time.Hour)
+	statuses := []int{1, 1, 2, 0, 1}
+	subs := []storage.SubStatus{"", "", storage.SubStatusSlowLatency, storage.SubStatusServerError, ""}
+	for i, ts := 0, start; ts.Before(end); i, ts = i+1, ts.Add(7*time.Minute) {
+		r := &storage.ProbeRecord{
+			Provider: "demo", Service: "cc",
+			Status: statuses[i%5], SubStatus: subs[i%5],
+			Latency: 100 + i%13*10, Timestamp: ts.Unix(),
+			FirstByteLatency: i % 3 * 20,
+			DNSDuration:      i % 4 * 5, ConnectDuration: i % 2 * 30, TLSDuration: i % 4 * 40, BodyReadDuration: i%5 + 1,
+		}
+		if err := store.SaveRecord(r); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+	}
+
+	h := &Handler{storage: store}
+	// 短时间范围读取原始记录（不对齐结束时间）
+	if _, ok := (timeRange{from: end.Add(-24 * time.Hour), to: end, bucket: time.Hour}).rollupGranularity(); ok {
+		t.Fatal("24h range should not use rollups")
+	}
+
+	for _, bucket := range []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour} {
+		tr := timeRange{from: end.Add(-8 * 24 * time.Hour), to: end, bucket: bucket}
+		granularity, ok := tr.rollupGranularity()
+		if !ok {
+			t.Fatalf("bucket %v should use rollups", bucket)
+		}
+		tr = tr.alignTo(granularity)
+
+		history, err := store.GetHistory("demo", "cc", "", "", tr.from, tr.to)
+		if err != nil {
+			t.Fatalf("get history: %v", err)
+		}
+		rollups, err := store.GetRollups("demo", "cc", "", granularity, tr.from, tr.to)
+		if err != nil {
+			t.Fatalf("get rollups: %v", err)
+		}
+
+		raw := h.buildTimeline(history, tr, 0.7)
+		agg := h.buildRollupTimeline(rollups, tr, 0.7)
+		if len(raw) != len(agg) {
+			t.Fatalf("bucket %v: timeline length mismatch: %d vs %d", bucket, len(raw), len(agg))
+		}
+		for i := range raw {
+			// 加权可用率的累加顺序不同，只比较到浮点误差范围内
+			if math.Abs(raw[i].Availability-agg[i].Availability) > 1e-9 {
+				t.Fatalf("bucket %v: availability mismatch at %d: %v vs %v", bucket, i, raw[i].Availability, agg[i].Availability)
+			}
+			raw[i].Availability = agg[i].Availability
+			if !reflect.DeepEqual(raw[i], agg[i]) {
+				t.Fatalf("bucket %v: timeline mismatch at %d:\nraw:    %+v\nrollup: %+v", bucket, i, raw[i], agg[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/storage"
+)
+
+// maxBuckets 单次查询允许的最大 bucket 数量
+const maxBuckets = 1440
+
+// autoBucketTarget 自定义时间范围未指定 bucket 时，自动选择粒度的目标 bucket 数量上限
+const autoBucketTarget = 120
+
+// customPeriod 使用 from/to 自定义时间范围时的 period 标识
+const customPeriod = "custom"
+
+// periodPreset 预设时间范围及其默认 bucket 粒度
+type periodPreset struct {
+	duration time.Duration
+	bucket   time.Duration
+}
+
+// periodPresets 支持的 period 预设
+var periodPresets = map[string]periodPreset{
+	"1h":  {time.Hour, time.Minute},
+	"6h":  {6 * time.Hour, 5 * time.Minute},
+	"24h": {24 * time.Hour, time.Hour},
+	"1d":  {24 * time.Hour, time.Hour},
+	"7d":  {7 * 24 * time.Hour, 24 * time.Hour},
+	"30d": {30 * 24 * time.Hour, 24 * time.Hour},
+	"90d": {90 * 24 * time.Hour, 24 * time.Hour},
+}
+
+// autoBuckets 自定义时间范围时可自动选择的 bucket 粒度（从细到粗）
+var autoBuckets = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// timeRange 查询时间范围及时间轴粒度
+type timeRange struct {
+	period string // 预设名称，自定义范围时为 custom
+	from   time.Time
+	to     time.Time
+	bucket time.Duration
+	count  int // bucket 数量（对齐后保持不变），0 表示按 from/to 计算
+}
+
+// bucketCount 时间范围内的 bucket 数量（向上取整）
+func (r timeRange) bucketCount() int {
+	if r.count > 0 {
+		return r.count
+	}
+	span := r.to.Sub(r.from)
+	return int((span + r.bucket - 1) / r.bucket)
+}
+
+// bucketDays 整天粒度对应的天数，非整天粒度返回 0
+func (r timeRange) bucketDays() int {
+	if r.bucket%(24*time.Hour) != 0 {
+		return 0
+	}
+	return int(r.bucket / (24 * time.Hour))
+}
+
+// bucketStart 第 i 个 bucket 的起始时间（以 to 为终点向前推算，整天粒度按日历日计算）
+func (r timeRange) bucketStart(i int) time.Time {
+	back := r.bucketCount() - i
+	if days := r.bucketDays(); days > 0 {
+		return r.to.AddDate(0, 0, -back*days)
+	}
+	return r.to.Add(-time.Duration(back) * r.bucket)
+}
+
+// bucketIndex 时间点所在 bucket 的索引（从前往后，区间左闭右开），超出范围时返回 -1 或 bucketCount
+func (r timeRange) bucketIndex(t time.Time) int {
+	origin := r.bucketStart(0)
+	if t.Before(origin) {
+		return -1
+	}
+	if !t.Before(r.to) {
+		return r.bucketCount()
+	}
+	idx := int(t.Sub(origin) / r.bucket)
+	if r.bucketDays() > 0 {
+		// 整天粒度按日历日划分（夏令时切换日不是 24 小时），按实际边界修正估算值
+		idx = min(idx, r.bucketCount()-1)
+		for idx+1 < r.bucketCount() && !t.Before(r.bucketStart(idx+1)) {
+			idx++
+		}
+		for idx > 0 && t.Before(r.bucketStart(idx)) {
+			idx--
+		}
+	}
+	return idx
+}
+
+// alignTo 将终点向后对齐到聚合边界（整点或本地零点），bucket 数量保持不变
+func (r timeRange) alignTo(g storage.RollupGranularity) timeRange {
+	n := r.bucketCount()
+	var end time.Time
+	if g == storage.RollupDaily {
+		y, m, d := r.to.Date()
+		end = time.Date(y, m, d, 0, 0, 0, 0, r.to.Location())
+		if end.Before(r.to) {
+			end = end.AddDate(0, 0, 1)
+		}
+	} else {
+		end = r.to.Truncate(time.Hour)
+		if end.Before(r.to) {
+			end = end.Add(time.Hour)
+		}
+	}
+
+	r.to = end
+	r.count = n
+	r.from = r.bucketStart(0)
+	return r
+}
+
+// minRollupSpan 使用聚合表的最短时间范围（更短的范围读取原始记录，保持结束时间和最新不完整的 bucket 不变）
+const minRollupSpan = 7 * 24 * time.Hour
+
+// rollupGranularity 判断能否直接由聚合表计算：时间范围不少于 minRollupSpan 且 bucket 为整小时/整天
+func (r timeRange) rollupGranularity() (storage.RollupGranularity, bool) {
+	if r.to.Sub(r.from) < minRollupSpan {
+		return "", false
+	}
+	bucket := r.bucket
+	switch {
+	case bucket%(24*time.Hour) == 0:
+		return storage.RollupDaily, true
+	case bucket%time.Hour == 0:
+		return storage.RollupHourly, true
+	default:
+		return "", false
+	}
+}
+
+// format 时间轴标签格式
+func (r timeRange) format() string {
+	switch {
+	case r.bucket >= 24*time.Hour:
+		return "2006-01-02"
+	case r.to.Sub(r.from) > 24*time.Hour:
+		return "01-02 15:04"
+	default:
+		return "15:04"
+	}
+}
+
+// parseTimeRange 解析查询参数中的时间范围
+// - period：预设范围（1h/6h/24h/7d/30d/90d），未指定 from 时生效，结束时间为 to（默认当前时间）
+// - from/to：自定义范围（Unix 秒或 RFC3339），指定 from 时忽略 period
+// - bucket：时间轴粒度（如 1m/5m/1h/1d），默认使用预设粒度或自动选择
+func parseTimeRange(c *gin.Context, defaultPeriod string) (timeRange, error) {
+	now := time.Now()
+	r := timeRange{to: now}
+
+	if v := c.Query("to"); v != "" {
+		ts, err := parseTimeParam(v)
+		if err != nil {
+			return r, fmt.Errorf("无效的 to: %s", v)
+		}
+		r.to = time.Unix(ts, 0)
+	}
+
+	if v := c.Query("from"); v != "" {
+		ts, err := parseTimeParam(v)
+		if err != nil {
+			return r, fmt.Errorf("无效的 from: %s", v)
+		}
+		r.period = customPeriod
+		r.from = time.Unix(ts, 0)
+	} else {
+		period := c.DefaultQuery("period", defaultPeriod)
+		preset, ok := periodPresets[period]
+		if !ok {
+			return r, fmt.Errorf("无效的时间范围: %s", period)
+		}
+		r.period = period
+		r.from = r.to.Add(-preset.duration)
+		r.bucket = preset.bucket
+	}
+
+	if !r.to.After(r.from) {
+		return r, fmt.Errorf("to 必须晚于 from")
+	}
+
+	if v := c.Query("bucket"); v != "" {
+		bucket, err := parseBucket(v)
+		if err != nil {
+			return r, err
+		}
+		r.bucket = bucket
+	} else if r.bucket == 0 {
+		r.bucket = autoBucket(r.to.Sub(r.from))
+	}
+
+	if n := r.bucketCount(); n > maxBuckets {
+		return r, fmt.Errorf("bucket 数量过多（%d），最多 %d 个，请缩小时间范围或增大 bucket", n, maxBuckets)
+	}
+
+	return r, nil
+}
+
+// parseBucket 解析 bucket 粒度（支持 Go duration 格式及 d 天数后缀），最小 1 分钟且需为整分钟
+func parseBucket(v string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("无效的 bucket: %s", v)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("无效的 bucket: %s", v)
+		}
+		d = parsed
+	}
+
+	if d < time.Minute || d%time.Minute != 0 {
+		return 0, fmt.Errorf("无效的 bucket: %s（最小 1m，且必须为整分钟）", v)
+	}
+	return d, nil
+}
+
+// autoBucket 选择使 bucket 数量不超过 autoBucketTarget 的最细粒度
+func autoBucket(span time.Duration) time.Duration {
+	for _, b := range autoBuckets {
+		if span/b <= autoBucketTarget {
+			return b
+		}
+	}
+	return autoBuckets[len(autoBuckets)-1]
+}
+
+// parseTimeParam 解析时间参数（Unix 秒或 RFC3339）
+func parseTimeParam(v string) (int64, error) {
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return ts, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
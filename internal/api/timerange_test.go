@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func testContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/status?"+query, nil)
+	return c
+}
+
+func TestParseTimeRangePresets(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		query  string
+		bucket time.Duration
+		count  int
+	}{
+		{"", time.Hour, 24},
+		{"period=1h", time.Minute, 60},
+		{"period=6h", 5 * time.Minute, 72},
+		{"period=7d", 24 * time.Hour, 7},
+		{"period=90d", 24 * time.Hour, 90},
+		{"period=24h&bucket=5m", 5 * time.Minute, 288},
+	}
+	for _, tc := range cases {
+		tr, err := parseTimeRange(testContext(tc.query), "24h")
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.query, err)
+		}
+		if tr.bucket != tc.bucket || tr.bucketCount() != tc.count {
+			t.Fatalf("%q: expected bucket %v x %d, got %v x %d", tc.query, tc.bucket, tc.count, tr.bucket, tr.bucketCount())
+		}
+	}
+}
+
+func TestParseTimeRangeCustom(t *testing.T) {
+	t.Parallel()
+
+	tr, err := parseTimeRange(testContext("from=2025-01-01T00:00:00Z&to=1735693200"), "24h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr.period != customPeriod || tr.from.Unix() != 1735689600 || tr.to.Unix() != 1735693200 {
+		t.Fatalf("unexpected range: %+v", tr)
+	}
+	// 1 小时自定义范围自动选择 1m 粒度
+	if tr.bucket != time.Minute || tr.bucketCount() != 60 || tr.format() != "15:04" {
+		t.Fatalf("unexpected auto bucket: %v x %d", tr.bucket, tr.bucketCount())
+	}
+
+	tr, err = parseTimeRange(testContext("from=1735689600&to=1736294400&bucket=1d"), "24h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr.bucketCount() != 7 || tr.format() != "2006-01-02" {
+		t.Fatalf("unexpected daily range: %v x %d", tr.bucket, tr.bucketCount())
+	}
+}
+
+func TestParseTimeRangeErrors(t *testing.T) {
+	t.Parallel()
+
+	for query, want := range map[string]string{
+		"period=2h":                  "无效的时间范围",
+		"from=abc":                   "无效的 from",
+		"from=200&to=100":            "to 必须晚于 from",
+		"bucket=30s":                 "无效的 bucket",
+		"bucket=0d":                  "无效的 bucket",
+		"period=90d&bucket=1m":       "bucket 数量过多",
+		"from=0&to=864000&bucket=5m": "bucket 数量过多",
+	} {
+		_, err := parseTimeRange(testContext(query), "24h")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: expected error containing %q, got %v", query, want, err)
+		}
+	}
+}
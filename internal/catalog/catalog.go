@@ -0,0 +1,408 @@
+// Package catalog 数据库中的监控项定义（monitor_store 模式）：修改经配置加载器完整验证后写入数据库，再通过配置重载应用到调度器和 API
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"monitor/internal/config"
+	"monitor/internal/secret"
+	"monitor/internal/storage"
+)
+
+var (
+	// ErrExists 新建的监控项已存在
+	ErrExists = errors.New("监控项已存在")
+
+	// ErrNotFound 修改或删除的监控项不存在
+	ErrNotFound = errors.New("监控项不存在")
+
+	// ErrInvalid 修改后的配置未通过验证
+	ErrInvalid = errors.New("监控项配置无效")
+)
+
+// Catalog 监控项目录（配置文件提供其余配置，监控项来自数据库）
+type Catalog struct {
+	store    storage.Storage
+	key      []byte // 加密 API Key 的主密钥
+	loader   *config.Loader
+	filename string
+	now      func() time.Time
+
+	mu    sync.Mutex   // 串行化本副本上的修改
+	apply func() error // 重新加载配置使修改生效（通常为 Watcher.Reload）
+
+	revMu    sync.Mutex
+	revision string // 最近一次加载的监控项定义指纹
+}
+
+// New 创建监控项目录，修改前使用 loader 按配置文件 filename 验证完整配置
+func New(store storage.Storage, key []byte, loader *config.Loader, filename string) *Catalog {
+	return &Catalog{store: store, key: key, loader: loader, filename: filename, now: time.Now}
+}
+
+// OnChange 设置应用修改的回调（未设置时拒绝修改）
+func (c *Catalog) OnChange(apply func() error) {
+	c.mu.Lock()
+	c.apply = apply
+	c.mu.Unlock()
+}
+
+// Definitions 读取并解密全部监控项定义（作为配置加载器的监控项来源）
+func (c *Catalog) Definitions() ([]config.ServiceConfig, error) {
+	records, err := c.store.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+	monitors, err := c.decode(records)
+	if err != nil {
+		return nil, err
+	}
+	c.setRevision(fingerprint(records))
+	return monitors, nil
+}
+
+// Create 新建监控项
+func (c *Catalog) Create(m *config.ServiceConfig) error {
+	return c.save(m, true, false)
+}
+
+// Update 覆盖已有监控项的定义，keepKey 为 true 时沿用已保存的 API Key
+func (c *Catalog) Update(m *config.ServiceConfig, keepKey bool) error {
+	return c.save(m, false, keepKey)
+}
+
+// save 验证并保存监控项定义，然后重新加载配置
+func (c *Catalog) save(m *config.ServiceConfig, create, keepKey bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.apply == nil {
+		return errors.New("配置热更新不可用，无法应用监控项修改")
+	}
+	monitors, err := c.current()
+	if err != nil {
+		return err
+	}
+
+	index := indexOf(monitors, m.Provider, m.Service, m.Channel)
+	switch {
+	case create && index >= 0:
+		return ErrExists
+	case !create && index < 0:
+		return ErrNotFound
+	}
+
+	definition, err := EncodeDefinition(m)
+	if err != nil {
+		return err
+	}
+	// 以重新解析的副本参与验证，避免预设和占位符处理修改调用方的 headers
+	fresh, err := ParseDefinition([]byte(definition))
+	if err != nil {
+		return err
+	}
+	fresh.APIKey = m.APIKey
+	if keepKey && index >= 0 {
+		fresh.APIKey = monitors[index].APIKey
+	}
+
+	candidate := append([]config.ServiceConfig(nil), monitors...)
+	if index >= 0 {
+		candidate[index] = *fresh
+	} else {
+		candidate = append(candidate, *fresh)
+	}
+	if err := c.validate(candidate); err != nil {
+		return err
+	}
+
+	if err := c.put(definition, fresh); err != nil {
+		return err
+	}
+	action := "修改"
+	if create {
+		action = "新建"
+	}
+	log.Printf("[Catalog] 已%s监控项 %s/%s/%s", action, m.Provider, m.Service, m.Channel)
+	return c.applyChange()
+}
+
+// Delete 删除监控项
+func (c *Catalog) Delete(provider, service, channel string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.apply == nil {
+		return errors.New("配置热更新不可用，无法应用监控项修改")
+	}
+	monitors, err := c.current()
+	if err != nil {
+		return err
+	}
+	index := indexOf(monitors, provider, service, channel)
+	if index < 0 {
+		return ErrNotFound
+	}
+
+	candidate := append(monitors[:index:index], monitors[index+1:]...)
+	if err := c.validate(candidate); err != nil {
+		return err
+	}
+	if _, err := c.store.DeleteMonitor(provider, service, channel); err != nil {
+		return err
+	}
+	log.Printf("[Catalog] 已删除监控项 %s/%s/%s", provider, service, channel)
+	return c.applyChange()
+}
+
+// Seed 数据库中没有监控项时导入配置文件中的 monitors（启动时调用，不触发重载），返回导入数量
+func (c *Catalog) Seed() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	records, err := c.store.GetMonitors()
+	if err != nil {
+		return 0, err
+	}
+	if len(records) > 0 {
+		return 0, nil
+	}
+	return c.importMonitors(nil)
+}
+
+// Import 导入配置文件中数据库尚不存在的监控项（已存在的不覆盖），返回导入数量
+func (c *Catalog) Import() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.apply == nil {
+		return 0, errors.New("配置热更新不可用，无法应用监控项修改")
+	}
+	monitors, err := c.current()
+	if err != nil {
+		return 0, err
+	}
+	n, err := c.importMonitors(monitors)
+	if err != nil || n == 0 {
+		return n, err
+	}
+	return n, c.applyChange()
+}
+
+// importMonitors 将配置文件中不在 existing 内的监控项验证后写入数据库
+func (c *Catalog) importMonitors(existing []config.ServiceConfig) (int, error) {
+	seeds, err := c.loader.ReadMonitors(c.filename)
+	if err != nil {
+		return 0, err
+	}
+
+	candidate := append([]config.ServiceConfig(nil), existing...)
+	definitions := make([]string, 0, len(seeds))
+	added := make([]*config.ServiceConfig, 0, len(seeds))
+	for i := range seeds {
+		m := &seeds[i]
+		if indexOf(candidate, m.Provider, m.Service, m.Channel) >= 0 {
+			continue
+		}
+		definition, err := EncodeDefinition(m)
+		if err != nil {
+			return 0, err
+		}
+		fresh, err := ParseDefinition([]byte(definition))
+		if err != nil {
+			return 0, err
+		}
+		fresh.APIKey = m.APIKey
+		candidate = append(candidate, *fresh)
+		definitions = append(definitions, definition)
+		added = append(added, m)
+	}
+	if len(added) == 0 {
+		return 0, nil
+	}
+	if err := c.validate(candidate); err != nil {
+		return 0, err
+	}
+
+	// 同一事务写入，避免中途失败后 Seed 因表非空而不再导入剩余的监控项
+	records := make([]*storage.MonitorDefinition, len(added))
+	for i, m := range added {
+		if records[i], err = c.record(definitions[i], m); err != nil {
+			return 0, err
+		}
+	}
+	if err := c.store.SaveMonitors(records); err != nil {
+		return 0, err
+	}
+	log.Printf("[Catalog] 已从配置文件导入 %d 个监控项", len(added))
+	return len(added), nil
+}
+
+// Start 定期检查其他副本对监控项的修改，发现变化时重新加载配置
+func (c *Catalog) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.sync(); err != nil {
+					log.Printf("[Catalog] 同步监控项失败: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// sync 数据库中的定义与最近一次加载的不同时重新加载配置
+func (c *Catalog) sync() error {
+	records, err := c.store.GetMonitors()
+	if err != nil {
+		return err
+	}
+	if fingerprint(records) == c.getRevision() {
+		return nil
+	}
+
+	c.mu.Lock()
+	apply := c.apply
+	c.mu.Unlock()
+	if apply == nil {
+		return nil
+	}
+	log.Printf("[Catalog] 检测到监控项变更，正在重新加载配置...")
+	return apply()
+}
+
+// current 读取数据库中的全部监控项（调用方需持有 mu）
+func (c *Catalog) current() ([]config.ServiceConfig, error) {
+	records, err := c.store.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+	return c.decode(records)
+}
+
+// validate 以候选监控项替换配置文件中的 monitors 并完整验证
+func (c *Catalog) validate(monitors []config.ServiceConfig) error {
+	if _, err := c.loader.Preview(c.filename, monitors); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return nil
+}
+
+// put 加密 API Key 并写入数据库
+func (c *Catalog) put(definition string, m *config.ServiceConfig) error {
+	record, err := c.record(definition, m)
+	if err != nil {
+		return err
+	}
+	return c.store.SaveMonitor(record)
+}
+
+// record 加密 API Key 并生成待写入的监控项定义
+func (c *Catalog) record(definition string, m *config.ServiceConfig) (*storage.MonitorDefinition, error) {
+	apiKey, err := secret.Encrypt(c.key, m.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("加密 API Key 失败: %w", err)
+	}
+	now := c.now().Unix()
+	return &storage.MonitorDefinition{
+		Provider:   m.Provider,
+		Service:    m.Service,
+		Channel:    m.Channel,
+		Definition: definition,
+		APIKey:     apiKey,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// applyChange 重新加载配置（数据库已写入，失败时其他副本仍会在下次同步时应用）
+func (c *Catalog) applyChange() error {
+	if err := c.apply(); err != nil {
+		return fmt.Errorf("监控项已保存，但重新加载配置失败: %w", err)
+	}
+	return nil
+}
+
+// decode 解析并解密监控项定义
+func (c *Catalog) decode(records []*storage.MonitorDefinition) ([]config.ServiceConfig, error) {
+	monitors := make([]config.ServiceConfig, 0, len(records))
+	for _, r := range records {
+		m, err := ParseDefinition([]byte(r.Definition))
+		if err != nil {
+			return nil, fmt.Errorf("监控项 %s/%s/%s: %w", r.Provider, r.Service, r.Channel, err)
+		}
+		if m.APIKey, err = secret.Decrypt(c.key, r.APIKey); err != nil {
+			return nil, fmt.Errorf("监控项 %s/%s/%s: API Key %w", r.Provider, r.Service, r.Channel, err)
+		}
+		monitors = append(monitors, *m)
+	}
+	return monitors, nil
+}
+
+func (c *Catalog) setRevision(revision string) {
+	c.revMu.Lock()
+	c.revision = revision
+	c.revMu.Unlock()
+}
+
+func (c *Catalog) getRevision() string {
+	c.revMu.Lock()
+	defer c.revMu.Unlock()
+	return c.revision
+}
+
+// EncodeDefinition 将监控项编码为 YAML 定义（不含 api_key）
+func EncodeDefinition(m *config.ServiceConfig) (string, error) {
+	copied := *m
+	copied.APIKey = ""
+	data, err := yaml.Marshal(&copied)
+	if err != nil {
+		return "", fmt.Errorf("编码监控项定义失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// ParseDefinition 解析 YAML 监控项定义（字段与配置文件中 monitors 条目相同，不允许未知字段）
+func ParseDefinition(data []byte) (*config.ServiceConfig, error) {
+	var m config.ServiceConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("解析监控项定义失败: %w", err)
+	}
+	return &m, nil
+}
+
+// indexOf 按 provider/service/channel 查找监控项
+func indexOf(monitors []config.ServiceConfig, provider, service, channel string) int {
+	for i := range monitors {
+		if monitors[i].Provider == provider && monitors[i].Service == service && monitors[i].Channel == channel {
+			return i
+		}
+	}
+	return -1
+}
+
+// fingerprint 监控项定义的指纹（用于发现其他副本的修改）
+func fingerprint(records []*storage.MonitorDefinition) string {
+	h := sha256.New()
+	for _, r := range records {
+		fmt.Fprintf(h, "%d\x00%s\x00%s\x00%s\x00%s\x00%s\x00", r.ID, r.Provider, r.Service, r.Channel, r.Definition, r.APIKey)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
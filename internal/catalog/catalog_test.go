@@ -0,0 +1,219 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+const testConfig = `
+monitor_store:
+  enabled: true
+monitors:
+  - provider: demo
+    service: cc
+    category: public
+    sponsor: tester
+    url: https://api.example.com/v1/messages
+    method: POST
+    api_key: sk-seed
+    headers:
+      x-api-key: "{{API_KEY}}"
+`
+
+// newTestCatalog 创建使用临时配置文件和 SQLite 的监控项目录
+func newTestCatalog(t *testing.T) (*Catalog, *config.Loader, string, storage.Storage) {
+	t.Helper()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(filename, []byte(testConfig), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	store := storage.NewTestSQLite(t)
+
+	loader := config.NewLoader()
+	return New(store, bytes.Repeat([]byte{1}, 32), loader, filename), loader, filename, store
+}
+
+func TestCatalogCRUD(t *testing.T) {
+	t.Parallel()
+
+	c, loader, filename, store := newTestCatalog(t)
+	if n, err := c.Seed(); err != nil || n != 1 {
+		t.Fatalf("seed: n=%d err=%v", n, err)
+	}
+	if n, _ := c.Seed(); n != 0 {
+		t.Fatalf("seed should only import into an empty table, got %d", n)
+	}
+
+	// API Key 加密保存
+	records, _ := store.GetMonitors()
+	if len(records) != 1 || strings.Contains(records[0].APIKey, "sk-seed") || strings.Contains(records[0].Definition, "sk-seed") {
+		t.Fatalf("api key stored in plaintext: %+v", records[0])
+	}
+
+	loader.SetMonitorSource(c.Definitions)
+	var current *config.AppConfig
+	c.OnChange(func() error {
+		cfg, err := loader.Load(filename)
+		if err == nil {
+			current = cfg
+		}
+		return err
+	})
+	if _, err := c.Definitions(); err != nil {
+		t.Fatalf("definitions: %v", err)
+	}
+
+	seed := config.ServiceConfig{Provider: "demo", Service: "cc", Category: "public", Sponsor: "tester", URL: "https://api.example.com", Method: "GET"}
+	if err := c.Create(&seed); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+
+	invalid := config.ServiceConfig{Provider: "demo", Service: "cx", Category: "public", URL: "https://api.example.com", Method: "GET"}
+	if err := c.Create(&invalid); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for missing sponsor, got %v", err)
+	}
+
+	created := config.ServiceConfig{
+		Provider: "demo", Service: "cx", Category: "public", Sponsor: "tester",
+		URL: "https://api.example.com/v1/responses", Method: "POST", APIKey: "sk-new",
+		Headers: map[string]string{"Authorization": "Bearer {{API_KEY}}"},
+	}
+	if err := c.Create(&created); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Headers["Authorization"] != "Bearer {{API_KEY}}" {
+		t.Fatalf("create should not modify the caller's headers: %v", created.Headers)
+	}
+	if current == nil || len(current.Monitors) != 2 || current.Monitors[1].Headers["Authorization"] != "Bearer sk-new" {
+		t.Fatalf("expected reloaded config with the new monitor, got %+v", current)
+	}
+
+	// 不提供 API Key 时沿用已保存的值
+	updated := created
+	updated.APIKey, updated.Sponsor = "", "someone-else"
+	if err := c.Update(&updated, true); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if m := current.Monitors[1]; m.Sponsor != "someone-else" || m.APIKey != "sk-new" {
+		t.Fatalf("unexpected updated monitor: %+v", m)
+	}
+
+	if err := c.Delete("demo", "cx", ""); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := c.Delete("demo", "cx", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(current.Monitors) != 1 {
+		t.Fatalf("expected 1 monitor after delete, got %d", len(current.Monitors))
+	}
+
+	// 配置文件中被删除的监控项可以重新导入
+	if err := c.Delete("demo", "cc", ""); err != nil {
+		t.Fatalf("delete seed: %v", err)
+	}
+	if n, err := c.Import(); err != nil || n != 1 {
+		t.Fatalf("import: n=%d err=%v", n, err)
+	}
+	if len(current.Monitors) != 1 || current.Monitors[0].APIKey != "sk-seed" {
+		t.Fatalf("unexpected monitors after import: %+v", current.Monitors)
+	}
+}
+
+func TestCatalogSyncAppliesChangesFromOtherReplicas(t *testing.T) {
+	t.Parallel()
+
+	c, _, filename, store := newTestCatalog(t)
+	if _, err := c.Seed(); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if _, err := c.Definitions(); err != nil {
+		t.Fatalf("definitions: %v", err)
+	}
+	reloads := 0
+	c.OnChange(func() error {
+		reloads++
+		_, err := c.Definitions()
+		return err
+	})
+
+	if err := c.sync(); err != nil || reloads != 0 {
+		t.Fatalf("unchanged definitions should not reload: reloads=%d err=%v", reloads, err)
+	}
+
+	// 另一个副本新建监控项
+	other := New(store, c.key, config.NewLoader(), filename)
+	other.OnChange(func() error { return nil })
+	if err := other.Create(&config.ServiceConfig{
+		Provider: "demo", Service: "cx", Category: "public", Sponsor: "tester", URL: "https://api.example.com", Method: "GET",
+	}); err != nil {
+		t.Fatalf("create on other replica: %v", err)
+	}
+
+	if err := c.sync(); err != nil || reloads != 1 {
+		t.Fatalf("expected a reload after remote change: reloads=%d err=%v", reloads, err)
+	}
+	if err := c.sync(); err != nil || reloads != 1 {
+		t.Fatalf("expected no further reload: reloads=%d err=%v", reloads, err)
+	}
+
+	// 主密钥不匹配时拒绝加载
+	wrong := New(store, bytes.Repeat([]byte{2}, 32), config.NewLoader(), filename)
+	if _, err := wrong.Definitions(); err == nil {
+		t.Fatal("expected decrypt error with a different master key")
+	}
+}
+
+func TestCatalogKeepsSecretReferences(t *testing.T) {
+	t.Parallel()
+
+	c, loader, filename, _ := newTestCatalog(t)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(filename), "cx.key"), []byte("sk-from-file\n"), 0o600); err != nil {
+		t.Fatalf("write secret file: %v", err)
+	}
+	if _, err := c.Seed(); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	loader.SetMonitorSource(c.Definitions)
+	var current *config.AppConfig
+	c.OnChange(func() error {
+		cfg, err := loader.Load(filename)
+		if err == nil {
+			current = cfg
+		}
+		return err
+	})
+
+	m := config.ServiceConfig{
+		Provider: "demo", Service: "cx", Category: "public", Sponsor: "tester",
+		URL: "https://api.example.com", Method: "GET", APIKey: "file:cx.key",
+	}
+	if err := c.Create(&m); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// 数据库保存引用本身，加载配置时才解析为实际值
+	monitors, err := c.Definitions()
+	if err != nil || monitors[1].APIKey != "file:cx.key" {
+		t.Fatalf("expected stored reference, got %+v err=%v", monitors, err)
+	}
+	if current == nil || current.Monitors[1].APIKey != "sk-from-file" {
+		t.Fatalf("expected resolved api key in loaded config, got %+v", current)
+	}
+
+	// 无法解析的引用不会写入数据库
+	broken := m
+	broken.Service, broken.APIKey = "cy", "file:missing.key"
+	if err := c.Create(&broken); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for unresolvable reference, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// adminEnvTokenName 通过 MONITOR_ADMIN_TOKEN 设置的凭证在审计日志中的名称
+const adminEnvTokenName = "env"
+
+// AdminToken 管理 API 凭证
+type AdminToken struct {
+	// 凭证名称（记录在审计日志中，只允许字母、数字、下划线和连字符）
+	Name string `yaml:"name" json:"name"`
+
+	// 凭证内容，建议通过环境变量 MONITOR_ADMIN_<NAME>_TOKEN 设置
+	Token string `yaml:"token" json:"-"`
+}
+
+// AdminConfig 管理 API 配置（/api/admin，未配置凭证时不开放）
+type AdminConfig struct {
+	// 允许访问管理 API 的凭证；环境变量 MONITOR_ADMIN_TOKEN 会追加一个名为 env 的凭证
+	Tokens []AdminToken `yaml:"tokens" json:"tokens"`
+}
+
+// Validate 验证管理 API 配置
+func (a *AdminConfig) Validate() error {
+	seen := make(map[string]bool, len(a.Tokens))
+	for i, t := range a.Tokens {
+		if !regionPattern.MatchString(t.Name) {
+			return fmt.Errorf("admin.tokens[%d]: name 只能包含字母、数字、下划线和连字符，收到: %q", i, t.Name)
+		}
+		if t.Name == adminEnvTokenName {
+			return fmt.Errorf("admin.tokens[%d]: name %q 保留给环境变量 MONITOR_ADMIN_TOKEN", i, t.Name)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("admin.tokens[%d]: name 重复: %s", i, t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return nil
+}
+
+// Normalize 检查管理 API 凭证（token 可能来自环境变量，在此检查）
+func (a *AdminConfig) Normalize() error {
+	tokens := make(map[string]bool, len(a.Tokens))
+	for _, t := range a.Tokens {
+		if t.Token == "" {
+			return fmt.Errorf("admin.tokens: %s 未配置 token（可通过环境变量 %s 设置）", t.Name, adminTokenEnv(t.Name))
+		}
+		if tokens[t.Token] {
+			return fmt.Errorf("admin.tokens: %s 的 token 与其他凭证重复", t.Name)
+		}
+		tokens[t.Token] = true
+	}
+	return nil
+}
+
+// Enabled 是否开放管理 API
+func (a *AdminConfig) Enabled() bool {
+	return len(a.Tokens) > 0
+}
+
+// adminTokenEnv 管理 API 凭证的环境变量名
+func adminTokenEnv(name string) string {
+	return "MONITOR_ADMIN_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_TOKEN"
+}
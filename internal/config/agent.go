@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultRegion 未配置 region 时本实例探测记录的地域标识（与 storage.DefaultRegion 一致）
+const defaultRegion = "local"
+
+// defaultAgentFlushInterval 探测节点默认上报间隔
+const defaultAgentFlushInterval = 5 * time.Second
+
+// defaultAgentBufferSize 探测节点默认缓存的最大记录数
+const defaultAgentBufferSize = 10000
+
+// regionPattern 地域标识只允许字母、数字、下划线和连字符（用于 URL 参数和环境变量名）
+var regionPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// AgentConfig 探测节点模式：只运行调度器和探测器，将探测结果上报到中心节点
+type AgentConfig struct {
+	// 是否以探测节点模式运行（不提供 API、不发送告警、不写本地数据库）
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// 中心节点地址（如 "https://status.example.com"）
+	ServerURL string `yaml:"server_url" json:"server_url"`
+
+	// 上报凭证（需与中心节点 ingest.agents 中的 token 一致），建议通过环境变量 MONITOR_AGENT_TOKEN 设置
+	Token string `yaml:"token" json:"-"`
+
+	// 上报间隔（默认 "5s"）
+	FlushInterval string `yaml:"flush_interval" json:"flush_interval"`
+
+	// 解析后的上报间隔（内部使用，不序列化）
+	FlushIntervalDuration time.Duration `yaml:"-" json:"-"`
+
+	// 中心节点不可用时缓存的最大记录数（默认 10000），超出时丢弃最早的记录
+	BufferSize int `yaml:"buffer_size" json:"buffer_size"`
+}
+
+// Validate 验证探测节点配置
+func (a *AgentConfig) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(a.ServerURL) == "" {
+		return fmt.Errorf("agent: 开启探测节点模式时 server_url 不能为空")
+	}
+	if err := validateURL(a.ServerURL, "agent.server_url"); err != nil {
+		return err
+	}
+	if a.BufferSize < 0 {
+		return fmt.Errorf("agent: buffer_size 不能为负数，当前值: %d", a.BufferSize)
+	}
+	return nil
+}
+
+// Normalize 填充探测节点配置默认值（token 可能来自环境变量，在此检查）
+func (a *AgentConfig) Normalize() error {
+	var err error
+	if a.FlushIntervalDuration, err = parseMonitorDuration(a.FlushInterval, defaultAgentFlushInterval); err != nil {
+		return fmt.Errorf("agent: 解析 flush_interval 失败: %w", err)
+	}
+	if a.BufferSize == 0 {
+		a.BufferSize = defaultAgentBufferSize
+	}
+	a.ServerURL = strings.TrimRight(strings.TrimSpace(a.ServerURL), "/")
+	if a.Enabled && a.Token == "" {
+		return fmt.Errorf("agent: 未配置 token（可通过环境变量 MONITOR_AGENT_TOKEN 设置）")
+	}
+	return nil
+}
+
+// IngestAgent 允许向中心节点上报探测结果的探测节点
+type IngestAgent struct {
+	// 探测节点所属地域（上报的记录以此标记，节点无法冒充其他地域）
+	Region string `yaml:"region" json:"region"`
+
+	// 上报凭证，建议通过环境变量 MONITOR_INGEST_<REGION>_TOKEN 设置
+	Token string `yaml:"token" json:"-"`
+}
+
+// IngestConfig 中心节点接收探测节点上报的配置
+type IngestConfig struct {
+	// 允许上报的探测节点（为空时不接收上报）
+	Agents []IngestAgent `yaml:"agents" json:"agents"`
+}
+
+// Validate 验证上报配置
+func (i *IngestConfig) Validate() error {
+	seen := make(map[string]bool, len(i.Agents))
+	for idx, agent := range i.Agents {
+		if !regionPattern.MatchString(agent.Region) {
+			return fmt.Errorf("ingest.agents[%d]: region 只能包含字母、数字、下划线和连字符，收到: %q", idx, agent.Region)
+		}
+		if seen[agent.Region] {
+			return fmt.Errorf("ingest.agents[%d]: region 重复: %s", idx, agent.Region)
+		}
+		seen[agent.Region] = true
+	}
+	return nil
+}
+
+// Normalize 检查上报凭证（token 可能来自环境变量，在此检查），localRegion 为本实例的地域
+func (i *IngestConfig) Normalize(localRegion string) error {
+	tokens := make(map[string]bool, len(i.Agents))
+	for _, agent := range i.Agents {
+		if agent.Region == localRegion {
+			return fmt.Errorf("ingest.agents: region %q 与本实例的 region 相同", agent.Region)
+		}
+		if agent.Token == "" {
+			return fmt.Errorf("ingest.agents: %s 未配置 token（可通过环境变量 %s 设置）", agent.Region, ingestTokenEnv(agent.Region))
+		}
+		if tokens[agent.Token] {
+			return fmt.Errorf("ingest.agents: %s 的 token 与其他探测节点重复", agent.Region)
+		}
+		tokens[agent.Token] = true
+	}
+	return nil
+}
+
+// ingestTokenEnv 探测节点上报凭证的环境变量名
+func ingestTokenEnv(region string) string {
+	return "MONITOR_INGEST_" + strings.ToUpper(strings.ReplaceAll(region, "-", "_")) + "_TOKEN"
+}
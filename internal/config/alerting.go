@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 告警事件类型
+const (
+	AlertEventDown                  = "down"                   // 连续失败，进入不可用
+	AlertEventDegraded              = "degraded"               // 连续黄色，进入降级
+	AlertEventRecovered             = "recovered"              // 从不可用/降级恢复
+	AlertEventAvailabilityLow       = "availability_low"       // 窗口可用率低于阈值
+	AlertEventAvailabilityRecovered = "availability_recovered" // 窗口可用率恢复
+	AlertEventFlapping              = "flapping"               // 状态频繁变化，暂停推送状态变化通知
+	AlertEventFlappingStopped       = "flapping_stopped"       // 状态趋于稳定，恢复推送
+)
+
+// 通知渠道类型
+const (
+	NotifierWebhook  = "webhook"  // 通用 Webhook（POST JSON 事件）
+	NotifierDingTalk = "dingtalk" // 钉钉自定义机器人
+	NotifierFeishu   = "feishu"   // 飞书/Lark 自定义机器人
+	NotifierWeCom    = "wecom"    // 企业微信群机器人
+)
+
+// validNotifierTypes 支持的通知渠道类型
+var validNotifierTypes = map[string]bool{
+	NotifierWebhook:  true,
+	NotifierDingTalk: true,
+	NotifierFeishu:   true,
+	NotifierWeCom:    true,
+}
+
+// validAlertEvents 支持订阅的事件类型
+var validAlertEvents = map[string]bool{
+	AlertEventDown:                  true,
+	AlertEventDegraded:              true,
+	AlertEventRecovered:             true,
+	AlertEventAvailabilityLow:       true,
+	AlertEventAvailabilityRecovered: true,
+	AlertEventFlapping:              true,
+	AlertEventFlappingStopped:       true,
+}
+
+// AlertingConfig 告警配置
+type AlertingConfig struct {
+	// 是否启用告警
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// 连续 N 次红色后触发 down 告警（默认 3）
+	ConsecutiveFailures int `yaml:"consecutive_failures" json:"consecutive_failures"`
+
+	// 连续 N 次黄色后触发 degraded 告警（0 表示不对黄色告警）
+	ConsecutiveDegraded int `yaml:"consecutive_degraded" json:"consecutive_degraded"`
+
+	// 连续 N 次绿色后发送 recovered 通知（默认 1）
+	RecoverySuccesses int `yaml:"recovery_successes" json:"recovery_successes"`
+
+	// 窗口可用率阈值（百分比 0-100，0 表示不启用），可用率按 degraded_weight 加权
+	AvailabilityThreshold float64 `yaml:"availability_threshold" json:"availability_threshold"`
+
+	// 可用率统计窗口（默认 "1h"）
+	AvailabilityWindow string `yaml:"availability_window" json:"availability_window"`
+
+	// 解析后的可用率统计窗口（内部使用，不序列化）
+	AvailabilityWindowDuration time.Duration `yaml:"-" json:"-"`
+
+	// 窗口内最少样本数，样本不足时不判定可用率（默认 5）
+	AvailabilityMinSamples int `yaml:"availability_min_samples" json:"availability_min_samples"`
+
+	// 监控面板地址（IM 卡片中的跳转链接，可选）
+	DashboardURL string `yaml:"dashboard_url" json:"dashboard_url"`
+
+	// Webhook 通知目标
+	Webhooks []WebhookConfig `yaml:"webhooks" json:"webhooks"`
+}
+
+// WebhookConfig 通知渠道配置（通用 Webhook 或钉钉/飞书/企业微信机器人）
+type WebhookConfig struct {
+	Name    string            `yaml:"name" json:"name"`
+	URL     string            `yaml:"url" json:"-"`     // 可能包含 token，不返回给前端
+	Headers map[string]string `yaml:"headers" json:"-"` // 可能包含认证信息
+
+	// 渠道类型：webhook（默认）/ dingtalk / feishu / wecom
+	Type string `yaml:"type" json:"type"`
+
+	// 签名密钥（钉钉"加签"、飞书"签名校验"安全设置，可选）
+	Secret string `yaml:"secret" json:"-"`
+
+	// 订阅的事件类型（为空表示全部）
+	Events []string `yaml:"events" json:"events"`
+
+	// 单次请求超时（默认 "5s"）
+	Timeout string `yaml:"timeout" json:"timeout"`
+
+	// 失败重试次数（默认 3，-1 表示不重试）
+	Retries int `yaml:"retries" json:"retries"`
+
+	// 首次重试等待时间，之后按指数退避（默认 "1s"）
+	RetryBackoff string `yaml:"retry_backoff" json:"retry_backoff"`
+
+	// 解析后的超时和退避时间（内部使用，不序列化）
+	TimeoutDuration      time.Duration `yaml:"-" json:"-"`
+	RetryBackoffDuration time.Duration `yaml:"-" json:"-"`
+}
+
+// Validate 验证告警配置
+func (a *AlertingConfig) Validate() error {
+	if a.ConsecutiveFailures < 0 || a.ConsecutiveDegraded < 0 || a.RecoverySuccesses < 0 || a.AvailabilityMinSamples < 0 {
+		return fmt.Errorf("alerting: 次数配置不能为负数")
+	}
+	if a.AvailabilityThreshold < 0 || a.AvailabilityThreshold > 100 {
+		return fmt.Errorf("alerting: availability_threshold 必须在 0 到 100 之间，当前值: %.2f", a.AvailabilityThreshold)
+	}
+
+	if a.DashboardURL != "" {
+		if err := validateURL(a.DashboardURL, "dashboard_url"); err != nil {
+			return fmt.Errorf("alerting: %w", err)
+		}
+	}
+
+	for i, w := range a.Webhooks {
+		if typ := strings.ToLower(strings.TrimSpace(w.Type)); typ != "" && !validNotifierTypes[typ] {
+			return fmt.Errorf("alerting.webhooks[%d]: type '%s' 无效，必须是 webhook/dingtalk/feishu/wecom 之一", i, w.Type)
+		}
+		if strings.TrimSpace(w.URL) == "" {
+			return fmt.Errorf("alerting.webhooks[%d]: url 不能为空", i)
+		}
+		if err := validateURL(w.URL, "url"); err != nil {
+			return fmt.Errorf("alerting.webhooks[%d]: %w", i, err)
+		}
+		if err := validateAlertEvents(w.Events); err != nil {
+			return fmt.Errorf("alerting.webhooks[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Normalize 填充告警配置默认值
+func (a *AlertingConfig) Normalize() error {
+	if a.ConsecutiveFailures == 0 {
+		a.ConsecutiveFailures = 3
+	}
+	if a.RecoverySuccesses == 0 {
+		a.RecoverySuccesses = 1
+	}
+	if a.AvailabilityMinSamples == 0 {
+		a.AvailabilityMinSamples = 5
+	}
+
+	a.DashboardURL = strings.TrimRight(strings.TrimSpace(a.DashboardURL), "/")
+
+	var err error
+	if a.AvailabilityWindowDuration, err = parseMonitorDuration(a.AvailabilityWindow, time.Hour); err != nil {
+		return fmt.Errorf("alerting: 解析 availability_window 失败: %w", err)
+	}
+
+	for i := range a.Webhooks {
+		w := &a.Webhooks[i]
+		w.URL = strings.TrimSpace(w.URL)
+		w.Type = strings.ToLower(strings.TrimSpace(w.Type))
+		if w.Type == "" {
+			w.Type = NotifierWebhook
+		}
+		if w.Name == "" {
+			w.Name = fmt.Sprintf("%s-%d", w.Type, i)
+		}
+		normalizeAlertEvents(w.Events)
+		if w.TimeoutDuration, err = parseMonitorDuration(w.Timeout, 5*time.Second); err != nil {
+			return fmt.Errorf("alerting.webhooks[%d]: 解析 timeout 失败: %w", i, err)
+		}
+		if w.RetryBackoffDuration, err = parseMonitorDuration(w.RetryBackoff, time.Second); err != nil {
+			return fmt.Errorf("alerting.webhooks[%d]: 解析 retry_backoff 失败: %w", i, err)
+		}
+		if w.Retries == 0 {
+			w.Retries = 3
+		} else if w.Retries < 0 {
+			w.Retries = 0
+		}
+	}
+
+	return nil
+}
+
+// validateAlertEvents 校验订阅的事件类型
+func validateAlertEvents(events []string) error {
+	for _, e := range events {
+		if !validAlertEvents[strings.ToLower(strings.TrimSpace(e))] {
+			return fmt.Errorf("事件类型 '%s' 无效", e)
+		}
+	}
+	return nil
+}
+
+// normalizeAlertEvents 规范化事件类型为小写
+func normalizeAlertEvents(events []string) {
+	for i := range events {
+		events[i] = strings.ToLower(strings.TrimSpace(events[i]))
+	}
+}
+
+// Subscribes 判断 webhook 是否订阅了指定事件（未配置 events 表示订阅全部）
+func (w *WebhookConfig) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
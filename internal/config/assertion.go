@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"monitor/internal/jsonpath"
+)
+
+// 断言类型
+const (
+	AssertionStatusCode = "status_code" // HTTP 状态码白名单
+	AssertionHeader     = "header"      // 响应头断言
+	AssertionJSON       = "json"        // JSONPath 断言
+	AssertionBody       = "body"        // 原始响应体断言
+)
+
+// 断言操作符
+const (
+	AssertOpExists    = "exists"
+	AssertOpNotExists = "not_exists"
+	AssertOpEquals    = "equals"
+	AssertOpNotEquals = "not_equals"
+	AssertOpContains  = "contains"
+	AssertOpRegex     = "regex"
+	AssertOpGT        = "gt"
+	AssertOpGTE       = "gte"
+	AssertOpLT        = "lt"
+	AssertOpLTE       = "lte"
+)
+
+// AssertionConfig 响应断言（按配置顺序依次校验，第一个失败的断言决定细分状态）
+type AssertionConfig struct {
+	// Type 断言类型：status_code / header / json / body
+	Type string `yaml:"type" json:"type"`
+
+	// Path JSONPath 表达式（json 类型），例如 "$.choices[0].message.content"
+	Path string `yaml:"path" json:"path,omitempty"`
+
+	// Header 响应头名称（header 类型）
+	Header string `yaml:"header" json:"header,omitempty"`
+
+	// Op 操作符：exists / not_exists / equals / not_equals / contains / regex / gt / gte / lt / lte
+	// status_code 类型无需配置
+	Op string `yaml:"op" json:"op,omitempty"`
+
+	// Value 期望值（字符串、数字或布尔值）
+	Value any `yaml:"value" json:"value,omitempty"`
+
+	// Codes 允许的 HTTP 状态码列表（status_code 类型）
+	Codes []int `yaml:"codes" json:"codes,omitempty"`
+}
+
+// validOpsByType 各断言类型支持的操作符
+var validOpsByType = map[string]map[string]bool{
+	AssertionHeader: {
+		AssertOpExists: true, AssertOpNotExists: true, AssertOpEquals: true, AssertOpNotEquals: true,
+		AssertOpContains: true, AssertOpRegex: true,
+	},
+	AssertionJSON: {
+		AssertOpExists: true, AssertOpNotExists: true, AssertOpEquals: true, AssertOpNotEquals: true,
+		AssertOpContains: true, AssertOpRegex: true,
+		AssertOpGT: true, AssertOpGTE: true, AssertOpLT: true, AssertOpLTE: true,
+	},
+	AssertionBody: {
+		AssertOpContains: true, AssertOpRegex: true,
+	},
+}
+
+// Validate 验证断言配置
+func (a *AssertionConfig) Validate() error {
+	typ := strings.ToLower(strings.TrimSpace(a.Type))
+	op := strings.ToLower(strings.TrimSpace(a.Op))
+
+	switch typ {
+	case AssertionStatusCode:
+		if len(a.Codes) == 0 {
+			return fmt.Errorf("status_code 断言需要配置 codes")
+		}
+		for _, code := range a.Codes {
+			if code < 100 || code > 599 {
+				return fmt.Errorf("status_code 断言包含无效状态码: %d", code)
+			}
+		}
+		return nil
+	case AssertionHeader:
+		if strings.TrimSpace(a.Header) == "" {
+			return fmt.Errorf("header 断言需要配置 header")
+		}
+	case AssertionJSON:
+		if _, err := jsonpath.Parse(a.Path); err != nil {
+			return fmt.Errorf("json 断言的 path 无效: %w", err)
+		}
+	case AssertionBody:
+	default:
+		return fmt.Errorf("断言类型 '%s' 无效，必须是 status_code/header/json/body 之一", a.Type)
+	}
+
+	if !validOpsByType[typ][op] {
+		return fmt.Errorf("%s 断言不支持操作符 '%s'", typ, a.Op)
+	}
+
+	if op != AssertOpExists && op != AssertOpNotExists && a.Value == nil {
+		return fmt.Errorf("%s 断言的 %s 操作需要配置 value", typ, op)
+	}
+
+	switch op {
+	case AssertOpRegex:
+		if _, err := regexp.Compile(fmt.Sprint(a.Value)); err != nil {
+			return fmt.Errorf("%s 断言的正则表达式无效: %w", typ, err)
+		}
+	case AssertOpGT, AssertOpGTE, AssertOpLT, AssertOpLTE:
+		if _, err := strconv.ParseFloat(fmt.Sprint(a.Value), 64); err != nil {
+			return fmt.Errorf("%s 断言的 %s 操作需要数值 value，收到: %v", typ, op, a.Value)
+		}
+	}
+
+	return nil
+}
+
+// normalize 规范化断言类型和操作符
+func (a *AssertionConfig) normalize() {
+	a.Type = strings.ToLower(strings.TrimSpace(a.Type))
+	a.Op = strings.ToLower(strings.TrimSpace(a.Op))
+	a.Path = strings.TrimSpace(a.Path)
+	a.Header = strings.TrimSpace(a.Header)
+}
+
+// EffectiveAssertions 返回实际生效的断言列表
+// 兼容旧配置：success_contains 等价于排在最前面的 body contains 断言
+func (m *ServiceConfig) EffectiveAssertions() []AssertionConfig {
+	if m.SuccessContains == "" {
+		return m.Assertions
+	}
+
+	assertions := make([]AssertionConfig, 0, len(m.Assertions)+1)
+	assertions = append(assertions, AssertionConfig{
+		Type:  AssertionBody,
+		Op:    AssertOpContains,
+		Value: m.SuccessContains,
+	})
+	return append(assertions, m.Assertions...)
+}
@@ -12,22 +12,62 @@ import (
 
 // ServiceConfig 单个服务监控配置
 type ServiceConfig struct {
-	Provider    string            `yaml:"provider" json:"provider"`
-	ProviderURL string            `yaml:"provider_url" json:"provider_url"` // 服务商官网链接（可选）
-	Service     string            `yaml:"service" json:"service"`
-	Category    string            `yaml:"category" json:"category"` // 分类：commercial（推广站）或 public（公益站）
-	Sponsor     string            `yaml:"sponsor" json:"sponsor"`   // 赞助者：提供 API Key 的个人或组织
-	SponsorURL  string            `yaml:"sponsor_url" json:"sponsor_url"` // 赞助者链接（可选）
-	Channel     string            `yaml:"channel" json:"channel"`   // 业务通道标识（如 "vip-channel"、"standard-channel"），用于分类和过滤
-	URL         string            `yaml:"url" json:"url"`
-	Method      string            `yaml:"method" json:"method"`
-	Headers     map[string]string `yaml:"headers" json:"headers"`
-	Body        string            `yaml:"body" json:"body"`
+	Provider    string `yaml:"provider" json:"provider"`
+	ProviderURL string `yaml:"provider_url" json:"provider_url"` // 服务商官网链接（可选）
+	Service     string `yaml:"service" json:"service"`
+	Category    string `yaml:"category" json:"category"`       // 分类：commercial（推广站）或 public（公益站）
+	Sponsor     string `yaml:"sponsor" json:"sponsor"`         // 赞助者：提供 API Key 的个人或组织
+	SponsorURL  string `yaml:"sponsor_url" json:"sponsor_url"` // 赞助者链接（可选）
+	Channel     string `yaml:"channel" json:"channel"`         // 业务通道标识（如 "vip-channel"、"standard-channel"），用于分类和过滤
+	URL         string `yaml:"url" json:"url"`
+	Method      string `yaml:"method" json:"method"`
+
+	// Protocol 可选：内置协议预设（openai-chat、openai-responses、openai-models、anthropic-messages、gemini-generate）
+	// 配置后自动填充 method、认证请求头、最小请求体和响应断言，显式配置的字段优先
+	Protocol string `yaml:"protocol" json:"protocol"`
+
+	// Model 模型名称（protocol 预设生成请求体时使用，也可通过 {{MODEL}} 占位符引用）
+	Model string `yaml:"model" json:"model"`
+
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	Body    string            `yaml:"body" json:"body"`
 
 	// SuccessContains 可选：响应体需包含的关键字，用于判定请求语义是否成功
 	SuccessContains string `yaml:"success_contains" json:"success_contains"`
 
-	// 解析后的"慢请求"阈值（来自全局配置），用于黄灯判定
+	// Assertions 可选：结构化响应断言（状态码白名单、响应头、JSONPath、正则等），按顺序校验
+	// 与 success_contains 同时配置时，success_contains 最先校验
+	Assertions []AssertionConfig `yaml:"assertions" json:"assertions"`
+
+	// Stream 可选：按 SSE（text/event-stream）流式解析响应，记录首 token 延迟并校验流是否正常结束
+	// 请求体需自行开启流式（如 "stream": true）
+	Stream bool `yaml:"stream" json:"stream"`
+
+	// StreamDoneMarker 可选：自定义流结束标记（data 行包含该字符串即视为正常结束）
+	// 未配置时识别 OpenAI 的 [DONE]、Anthropic 的 message_stop 和 Gemini 的 finishReason
+	StreamDoneMarker string `yaml:"stream_done_marker" json:"stream_done_marker"`
+
+	// Interval 可选：该监控项的巡检间隔，未配置时使用全局 interval
+	Interval string `yaml:"interval" json:"interval"`
+
+	// Timeout 可选：该监控项的请求超时（含流式读取），未配置时使用全局 timeout
+	Timeout string `yaml:"timeout" json:"timeout"`
+
+	// SlowLatency 可选：该监控项的慢请求阈值，未配置时使用全局 slow_latency
+	SlowLatency string `yaml:"slow_latency" json:"slow_latency"`
+
+	// Transport 可选：该监控项的出站传输配置（代理、CA、mTLS、DNS 等），配置后整体替换全局 transports 中该 provider 的配置
+	Transport *TransportConfig `yaml:"transport" json:"-"`
+
+	// Retry 可选：该监控项的失败重试策略，未配置的字段使用全局 retry
+	Retry RetryConfig `yaml:"retry" json:"retry"`
+
+	// CertExpiryWarningDays 可选：证书剩余有效期不足该天数时降级为黄色，未配置时使用全局值，-1 表示不预警
+	CertExpiryWarningDays int `yaml:"cert_expiry_warning_days" json:"cert_expiry_warning_days"`
+
+	// 解析后的巡检间隔、请求超时和"慢请求"阈值（未单独配置时来自全局配置）
+	IntervalDuration    time.Duration `yaml:"-" json:"-"`
+	TimeoutDuration     time.Duration `yaml:"-" json:"-"`
 	SlowLatencyDuration time.Duration `yaml:"-" json:"-"`
 
 	APIKey string `yaml:"api_key" json:"-"` // 不返回给前端
@@ -62,6 +102,9 @@ type PostgresConfig struct {
 	ConnMaxLifetime string `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
 }
 
+// defaultCertExpiryWarningDays 证书过期预警默认天数
+const defaultCertExpiryWarningDays = 14
+
 // AppConfig 应用配置
 type AppConfig struct {
 	// 巡检间隔（支持 Go duration 格式，例如 "30s"、"1m", "5m"）
@@ -76,6 +119,15 @@ type AppConfig struct {
 	// 解析后的慢请求阈值（内部使用，不序列化）
 	SlowLatencyDuration time.Duration `yaml:"-" json:"-"`
 
+	// 单次探测请求超时（含流式读取），支持 Go duration 格式，默认 "10s"
+	Timeout string `yaml:"timeout" json:"timeout"`
+
+	// 解析后的请求超时（内部使用，不序列化）
+	TimeoutDuration time.Duration `yaml:"-" json:"-"`
+
+	// 证书剩余有效期不足该天数时降级为黄色（默认 14，-1 表示不预警）
+	CertExpiryWarningDays int `yaml:"cert_expiry_warning_days" json:"cert_expiry_warning_days"`
+
 	// 可用率中黄色状态的权重（0-1，默认 0.7）
 	// 绿色=1.0, 黄色=degraded_weight, 红色=0.0
 	DegradedWeight float64 `yaml:"degraded_weight" json:"degraded_weight"`
@@ -83,15 +135,123 @@ type AppConfig struct {
 	// 存储配置
 	Storage StorageConfig `yaml:"storage" json:"storage"`
 
+	// 告警配置
+	Alerting AlertingConfig `yaml:"alerting" json:"alerting"`
+
+	// 调度策略（错峰、抖动、并发限制）
+	Scheduler SchedulerConfig `yaml:"scheduler" json:"scheduler"`
+
+	// 按 provider 配置的出站传输设置（代理、CA、mTLS、DNS 等），监控项可通过 transport 整体覆盖
+	Transports map[string]TransportConfig `yaml:"transports" json:"-"`
+
+	// 探测失败重试策略（监控项可单独覆盖）
+	Retry RetryConfig `yaml:"retry" json:"retry"`
+
+	// 数据保留策略
+	Retention RetentionConfig `yaml:"retention" json:"retention"`
+
+	// 多副本主节点选举（修改后需重启生效）
+	Election ElectionConfig `yaml:"election" json:"election"`
+
+	// 本实例的探测地域（默认 "local"），用于区分多地域探测节点上报的记录
+	Region string `yaml:"region" json:"region"`
+
+	// 探测节点模式：只探测并上报到中心节点（修改后需重启生效）
+	Agent AgentConfig `yaml:"agent" json:"agent"`
+
+	// 中心节点接收探测节点上报的配置
+	Ingest IngestConfig `yaml:"ingest" json:"ingest"`
+
+	// 计划维护窗口（维护期间的探测不计入可用率）
+	Maintenance MaintenanceConfig `yaml:"maintenance" json:"maintenance"`
+
+	// 状态震荡检测与当前状态滞回
+	Flapping FlappingConfig `yaml:"flapping" json:"flapping"`
+
+	// 管理 API 凭证
+	Admin AdminConfig `yaml:"admin" json:"admin"`
+
+	// 监控项保存在数据库中（修改后需重启生效）
+	MonitorStore MonitorStoreConfig `yaml:"monitor_store" json:"monitor_store"`
+
 	Monitors []ServiceConfig `yaml:"monitors"`
 }
 
 // Validate 验证配置合法性
 func (c *AppConfig) Validate() error {
-	if len(c.Monitors) == 0 {
+	// 数据库模式下监控项可通过管理 API 添加，允许为空
+	if len(c.Monitors) == 0 && !c.MonitorStore.Enabled {
 		return fmt.Errorf("至少需要配置一个监控项")
 	}
 
+	// 告警配置
+	if err := c.Alerting.Validate(); err != nil {
+		return err
+	}
+
+	// 数据保留配置
+	if err := c.Retention.Validate(); err != nil {
+		return err
+	}
+
+	// 重试配置
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+
+	// 调度配置
+	if err := c.Scheduler.Validate(); err != nil {
+		return err
+	}
+
+	// 选举配置
+	if err := c.Election.Validate(); err != nil {
+		return err
+	}
+
+	// 地域与探测节点配置
+	if c.Region != "" && !regionPattern.MatchString(c.Region) {
+		return fmt.Errorf("region 只能包含字母、数字、下划线和连字符，收到: %q", c.Region)
+	}
+	if err := c.Agent.Validate(); err != nil {
+		return err
+	}
+	if err := c.Ingest.Validate(); err != nil {
+		return err
+	}
+
+	// 维护窗口
+	if err := c.Maintenance.Validate(); err != nil {
+		return err
+	}
+
+	// 震荡检测配置
+	if err := c.Flapping.Validate(); err != nil {
+		return err
+	}
+
+	// 管理 API 配置
+	if err := c.Admin.Validate(); err != nil {
+		return err
+	}
+
+	// 数据库监控项配置
+	if err := c.MonitorStore.Validate(); err != nil {
+		return err
+	}
+
+	// 传输配置
+	for provider, t := range c.Transports {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("transports[%s]: %w", provider, err)
+		}
+	}
+
+	// 证书预警天数
+	if c.CertExpiryWarningDays < -1 {
+		return fmt.Errorf("cert_expiry_warning_days 不能小于 -1（0 表示使用默认值，-1 表示不预警），当前值: %d", c.CertExpiryWarningDays)
+	}
+
 	// 检查重复和必填字段
 	seen := make(map[string]bool)
 	for i, m := range c.Monitors {
@@ -140,6 +300,30 @@ func (c *AppConfig) Validate() error {
 			}
 		}
 
+		if err := m.Retry.Validate(); err != nil {
+			return fmt.Errorf("monitor[%d]: %w", i, err)
+		}
+		if m.Transport != nil {
+			if err := m.Transport.Validate(); err != nil {
+				return fmt.Errorf("monitor[%d]: transport: %w", i, err)
+			}
+		}
+
+		if m.CertExpiryWarningDays < -1 {
+			return fmt.Errorf("monitor[%d]: cert_expiry_warning_days 不能小于 -1，当前值: %d", i, m.CertExpiryWarningDays)
+		}
+
+		// 响应断言验证
+		for j := range m.Assertions {
+			if err := m.Assertions[j].Validate(); err != nil {
+				return fmt.Errorf("monitor[%d]: assertions[%d]: %w", i, j, err)
+			}
+			// 流式响应体是 SSE 事件序列而不是单个 JSON 文档
+			if m.Stream && strings.EqualFold(strings.TrimSpace(m.Assertions[j].Type), AssertionJSON) {
+				return fmt.Errorf("monitor[%d]: assertions[%d]: stream 监控项不支持 json 断言，请改用 body 断言", i, j)
+			}
+		}
+
 		// 唯一性检查（provider + service + channel 组合唯一）
 		key := m.Provider + "/" + m.Service + "/" + m.Channel
 		if seen[key] {
@@ -148,9 +332,32 @@ func (c *AppConfig) Validate() error {
 		seen[key] = true
 	}
 
+	// API Key 覆盖环境变量名不能有歧义
+	if err := c.validateAPIKeyEnvNames(); err != nil {
+		return err
+	}
+
+	// 维护窗口需至少匹配一个监控项（避免 provider/service 拼写错误时静默失效）
+	for i := range c.Maintenance.Windows {
+		if !c.HasMonitorMatching(&c.Maintenance.Windows[i]) {
+			w := &c.Maintenance.Windows[i]
+			return fmt.Errorf("maintenance.windows[%d]: 没有匹配的监控项: provider=%s, service=%s, channel=%s", i, w.Provider, w.Service, w.Channel)
+		}
+	}
+
 	return nil
 }
 
+// HasMonitorMatching 是否存在受维护窗口影响的监控项
+func (c *AppConfig) HasMonitorMatching(w *MaintenanceWindow) bool {
+	for i := range c.Monitors {
+		if w.Matches(c.Monitors[i].Provider, c.Monitors[i].Service, c.Monitors[i].Channel) {
+			return true
+		}
+	}
+	return false
+}
+
 // Normalize 规范化配置（填充默认值等）
 func (c *AppConfig) Normalize() error {
 	// 巡检间隔
@@ -181,6 +388,20 @@ func (c *AppConfig) Normalize() error {
 		c.SlowLatencyDuration = d
 	}
 
+	// 请求超时
+	if c.Timeout == "" {
+		c.TimeoutDuration = 10 * time.Second
+	} else {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return fmt.Errorf("解析 timeout 失败: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout 必须大于 0")
+		}
+		c.TimeoutDuration = d
+	}
+
 	// 黄色状态权重（默认 0.7，允许 0.01-1.0）
 	// 注意：0 被视为未配置，将使用默认值 0.7
 	// 如果需要极低权重，请使用 0.01 或更小的正数
@@ -191,6 +412,67 @@ func (c *AppConfig) Normalize() error {
 		return fmt.Errorf("degraded_weight 必须在 0 到 1 之间（0 表示使用默认值 0.7），当前值: %.2f", c.DegradedWeight)
 	}
 
+	// 证书预警天数（0 表示使用默认值 14）
+	if c.CertExpiryWarningDays == 0 {
+		c.CertExpiryWarningDays = defaultCertExpiryWarningDays
+	}
+
+	// 告警配置默认值
+	if err := c.Alerting.Normalize(); err != nil {
+		return err
+	}
+
+	// 数据保留配置默认值
+	if err := c.Retention.Normalize(); err != nil {
+		return err
+	}
+
+	// 重试配置默认值
+	if err := c.Retry.Normalize(); err != nil {
+		return err
+	}
+
+	// 调度配置默认值
+	if err := c.Scheduler.Normalize(); err != nil {
+		return err
+	}
+
+	// 选举配置默认值
+	if err := c.Election.Normalize(); err != nil {
+		return err
+	}
+
+	// 地域与探测节点配置默认值
+	if c.Region == "" {
+		c.Region = defaultRegion
+	}
+	if err := c.Agent.Normalize(); err != nil {
+		return err
+	}
+	if err := c.Ingest.Normalize(c.Region); err != nil {
+		return err
+	}
+
+	// 维护窗口时间解析
+	if err := c.Maintenance.Normalize(); err != nil {
+		return err
+	}
+
+	// 震荡检测配置默认值
+	if err := c.Flapping.Normalize(); err != nil {
+		return err
+	}
+
+	// 管理 API 凭证检查
+	if err := c.Admin.Normalize(); err != nil {
+		return err
+	}
+
+	// 数据库监控项配置默认值
+	if err := c.MonitorStore.Normalize(); err != nil {
+		return err
+	}
+
 	// 存储配置默认值
 	if c.Storage.Type == "" {
 		c.Storage.Type = "sqlite" // 默认使用 SQLite
@@ -216,25 +498,53 @@ func (c *AppConfig) Normalize() error {
 		}
 	}
 
-	// 将全局慢请求阈值下发到每个监控项，并标准化 category、URLs
+	// 解析监控项级别的间隔/超时/慢请求阈值（未配置时使用全局值），并标准化 category、URLs
 	for i := range c.Monitors {
-		if c.Monitors[i].SlowLatencyDuration == 0 {
-			c.Monitors[i].SlowLatencyDuration = c.SlowLatencyDuration
+		m := &c.Monitors[i]
+		var err error
+		if m.IntervalDuration, err = parseMonitorDuration(m.Interval, c.IntervalDuration); err != nil {
+			return fmt.Errorf("monitor[%d]: 解析 interval 失败: %w", i, err)
+		}
+		if m.TimeoutDuration, err = parseMonitorDuration(m.Timeout, c.TimeoutDuration); err != nil {
+			return fmt.Errorf("monitor[%d]: 解析 timeout 失败: %w", i, err)
+		}
+		if m.SlowLatencyDuration, err = parseMonitorDuration(m.SlowLatency, c.SlowLatencyDuration); err != nil {
+			return fmt.Errorf("monitor[%d]: 解析 slow_latency 失败: %w", i, err)
+		}
+
+		if err := m.Retry.inherit(c.Retry); err != nil {
+			return fmt.Errorf("monitor[%d]: %w", i, err)
+		}
+		if m.Transport == nil {
+			if t, ok := c.Transports[m.Provider]; ok {
+				m.Transport = &t
+			}
+		}
+		if m.CertExpiryWarningDays == 0 {
+			m.CertExpiryWarningDays = c.CertExpiryWarningDays
 		}
+
 		// 标准化 category 为小写
 		c.Monitors[i].Category = strings.ToLower(c.Monitors[i].Category)
 
 		// 规范化 URLs：去除首尾空格和末尾的 /
 		c.Monitors[i].ProviderURL = strings.TrimRight(strings.TrimSpace(c.Monitors[i].ProviderURL), "/")
 		c.Monitors[i].SponsorURL = strings.TrimRight(strings.TrimSpace(c.Monitors[i].SponsorURL), "/")
+
+		// 规范化断言类型和操作符
+		for j := range c.Monitors[i].Assertions {
+			c.Monitors[i].Assertions[j].normalize()
+		}
 	}
 
 	return nil
 }
 
 // ApplyEnvOverrides 应用环境变量覆盖
-// API Key 格式：MONITOR_<PROVIDER>_<SERVICE>_API_KEY
+// API Key 格式：MONITOR_<PROVIDER>_<SERVICE>_<CHANNEL>_API_KEY（优先）、MONITOR_<PROVIDER>_<SERVICE>_API_KEY
 // 存储配置格式：MONITOR_STORAGE_TYPE, MONITOR_POSTGRES_HOST 等
+// 选举副本标识：MONITOR_ELECTION_ID
+// 探测节点上报凭证：MONITOR_AGENT_TOKEN（探测节点）、MONITOR_INGEST_<REGION>_TOKEN（中心节点）
 func (c *AppConfig) ApplyEnvOverrides() {
 	// 存储配置环境变量覆盖
 	if envType := os.Getenv("MONITOR_STORAGE_TYPE"); envType != "" {
@@ -268,28 +578,62 @@ func (c *AppConfig) ApplyEnvOverrides() {
 		c.Storage.SQLite.Path = envPath
 	}
 
-	// API Key 覆盖
+	// 选举副本标识覆盖（如 Kubernetes 中注入 Pod 名称）
+	if envID := os.Getenv("MONITOR_ELECTION_ID"); envID != "" {
+		c.Election.ID = envID
+	}
+
+	// 探测节点上报凭证覆盖
+	if envToken := os.Getenv("MONITOR_AGENT_TOKEN"); envToken != "" {
+		c.Agent.Token = envToken
+	}
+	for i := range c.Ingest.Agents {
+		if envToken := os.Getenv(ingestTokenEnv(c.Ingest.Agents[i].Region)); envToken != "" {
+			c.Ingest.Agents[i].Token = envToken
+		}
+	}
+
+	// 维护窗口 API 凭证覆盖
+	if envToken := os.Getenv("MONITOR_MAINTENANCE_TOKEN"); envToken != "" {
+		c.Maintenance.Token = envToken
+	}
+
+	// 管理 API 凭证覆盖
+	for i := range c.Admin.Tokens {
+		if envToken := os.Getenv(adminTokenEnv(c.Admin.Tokens[i].Name)); envToken != "" {
+			c.Admin.Tokens[i].Token = envToken
+		}
+	}
+	if envToken := os.Getenv("MONITOR_ADMIN_TOKEN"); envToken != "" {
+		c.Admin.Tokens = append(c.Admin.Tokens, AdminToken{Name: adminEnvTokenName, Token: envToken})
+	}
+
+	// API Key 覆盖（带 channel 的环境变量优先）
 	for i := range c.Monitors {
 		m := &c.Monitors[i]
-		envKey := fmt.Sprintf("MONITOR_%s_%s_API_KEY",
-			strings.ToUpper(strings.ReplaceAll(m.Provider, "-", "_")),
-			strings.ToUpper(strings.ReplaceAll(m.Service, "-", "_")))
-
-		if envVal := os.Getenv(envKey); envVal != "" {
-			m.APIKey = envVal
+		for _, envKey := range m.apiKeyEnvNames() {
+			if envVal := os.Getenv(envKey); envVal != "" {
+				m.APIKey = envVal
+				break
+			}
 		}
 	}
 }
 
-// ProcessPlaceholders 处理 {{API_KEY}} 占位符替换（headers 和 body）
+// ProcessPlaceholders 处理 {{API_KEY}} 和 {{MODEL}} 占位符替换（url、headers 和 body）
 func (m *ServiceConfig) ProcessPlaceholders() {
+	replacer := strings.NewReplacer("{{API_KEY}}", m.APIKey, "{{MODEL}}", m.Model)
+
+	// URL 中仅替换 {{MODEL}}（如 Gemini 的 /models/{{MODEL}}:generateContent）
+	m.URL = strings.ReplaceAll(m.URL, "{{MODEL}}", m.Model)
+
 	// Headers 中替换
 	for k, v := range m.Headers {
-		m.Headers[k] = strings.ReplaceAll(v, "{{API_KEY}}", m.APIKey)
+		m.Headers[k] = replacer.Replace(v)
 	}
 
 	// Body 中替换
-	m.Body = strings.ReplaceAll(m.Body, "{{API_KEY}}", m.APIKey)
+	m.Body = replacer.Replace(m.Body)
 }
 
 // ResolveBodyIncludes 允许 body 字段引用 data/ 目录下的 JSON 文件
@@ -338,6 +682,22 @@ func (m *ServiceConfig) resolveBodyInclude(configDir string) error {
 	return nil
 }
 
+// parseMonitorDuration 解析监控项级别的 duration，为空时返回全局默认值
+func parseMonitorDuration(value string, fallback time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("必须大于 0")
+	}
+	return d, nil
+}
+
 // isValidCategory 检查 category 是否为有效值
 func isValidCategory(category string) bool {
 	normalized := strings.ToLower(strings.TrimSpace(category))
@@ -347,13 +707,29 @@ func isValidCategory(category string) bool {
 // Clone 深拷贝配置（用于热更新回滚）
 func (c *AppConfig) Clone() *AppConfig {
 	clone := &AppConfig{
-		Interval:            c.Interval,
-		IntervalDuration:    c.IntervalDuration,
-		SlowLatency:         c.SlowLatency,
-		SlowLatencyDuration: c.SlowLatencyDuration,
-		DegradedWeight:      c.DegradedWeight,
-		Storage:             c.Storage,
-		Monitors:            make([]ServiceConfig, len(c.Monitors)),
+		Interval:              c.Interval,
+		IntervalDuration:      c.IntervalDuration,
+		SlowLatency:           c.SlowLatency,
+		SlowLatencyDuration:   c.SlowLatencyDuration,
+		Timeout:               c.Timeout,
+		TimeoutDuration:       c.TimeoutDuration,
+		DegradedWeight:        c.DegradedWeight,
+		CertExpiryWarningDays: c.CertExpiryWarningDays,
+		Storage:               c.Storage,
+		Alerting:              c.Alerting,
+		Retention:             c.Retention,
+		Retry:                 c.Retry,
+		Scheduler:             c.Scheduler,
+		Transports:            c.Transports,
+		Election:              c.Election,
+		Region:                c.Region,
+		Agent:                 c.Agent,
+		Ingest:                c.Ingest,
+		Maintenance:           c.Maintenance,
+		Flapping:              c.Flapping,
+		Admin:                 c.Admin,
+		MonitorStore:          c.MonitorStore,
+		Monitors:              make([]ServiceConfig, len(c.Monitors)),
 	}
 	copy(clone.Monitors, c.Monitors)
 	return clone
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"monitor/internal/secret"
 )
 
 func TestResolveBodyIncludes(t *testing.T) {
@@ -58,3 +64,435 @@ func TestResolveBodyIncludesRejectsOutsideData(t *testing.T) {
 		t.Fatalf("期望 include 非 data 目录时报错")
 	}
 }
+
+func TestAssertionValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := []AssertionConfig{
+		{Type: "status_code", Codes: []int{200, 201}},
+		{Type: "json", Path: "$.choices[0].message.content", Op: "exists"},
+		{Type: "JSON", Path: "$.usage.total_tokens", Op: "GT", Value: 0},
+		{Type: "header", Header: "Content-Type", Op: "contains", Value: "json"},
+		{Type: "body", Op: "regex", Value: `"id":\s*"chatcmpl-`},
+	}
+	for i := range valid {
+		if err := valid[i].Validate(); err != nil {
+			t.Fatalf("assertion[%d] 期望合法，got err=%v", i, err)
+		}
+	}
+
+	invalid := []AssertionConfig{
+		{Type: "status_code"},
+		{Type: "json", Path: "choices", Op: "exists"},
+		{Type: "json", Path: "$.choices[0", Op: "exists"},
+		{Type: "json", Path: "$..content", Op: "exists"},
+		{Type: "json", Path: "$.a", Op: "equals"},
+		{Type: "json", Path: "$.a", Op: "gt", Value: "abc"},
+		{Type: "body", Op: "exists"},
+		{Type: "body", Op: "regex", Value: "("},
+		{Type: "unknown", Op: "exists"},
+	}
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Fatalf("assertion[%d] 期望非法，但校验通过", i)
+		}
+	}
+}
+
+func TestValidateRejectsJSONAssertionOnStream(t *testing.T) {
+	t.Parallel()
+
+	m := ServiceConfig{
+		Provider: "demo", Service: "cc", URL: "https://api.example.com/v1/messages", Method: "POST",
+		Category: "public", Sponsor: "demo", Stream: true,
+		Assertions: []AssertionConfig{{Type: "body", Op: "contains", Value: "message_stop"}},
+	}
+	cfg := &AppConfig{Monitors: []ServiceConfig{m}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("期望 stream 监控项允许 body 断言，got err=%v", err)
+	}
+
+	m.Assertions = append(m.Assertions, AssertionConfig{Type: "JSON", Path: "$.content", Op: "exists"})
+	cfg = &AppConfig{Monitors: []ServiceConfig{m}}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "monitor[0]: assertions[1]") {
+		t.Fatalf("期望 stream 监控项的 json 断言报错，got err=%v", err)
+	}
+}
+
+func TestEffectiveAssertionsIncludesSuccessContains(t *testing.T) {
+	t.Parallel()
+
+	m := ServiceConfig{
+		SuccessContains: "pong",
+		Assertions:      []AssertionConfig{{Type: "status_code", Codes: []int{200}}},
+	}
+
+	assertions := m.EffectiveAssertions()
+	if len(assertions) != 2 {
+		t.Fatalf("期望 2 条断言，got %d", len(assertions))
+	}
+	if assertions[0].Type != AssertionBody || assertions[0].Value != "pong" {
+		t.Fatalf("success_contains 应转换为首条 body contains 断言，got %+v", assertions[0])
+	}
+}
+
+func TestApplyProtocolPreset(t *testing.T) {
+	t.Parallel()
+
+	cfg := AppConfig{
+		Monitors: []ServiceConfig{
+			{
+				Provider: "demo",
+				Service:  "cc",
+				Protocol: "Anthropic-Messages",
+				Model:    "claude-haiku-4-5",
+				URL:      "https://relay.example.com/v1/messages",
+				Headers:  map[string]string{"content-type": "application/json; charset=utf-8"},
+			},
+		},
+	}
+
+	if err := cfg.ApplyProtocolPresets(); err != nil {
+		t.Fatalf("应用协议预设失败: %v", err)
+	}
+
+	m := cfg.Monitors[0]
+	if m.Method != "POST" {
+		t.Fatalf("期望默认 method 为 POST，got %s", m.Method)
+	}
+	if m.Headers["x-api-key"] != "{{API_KEY}}" {
+		t.Fatalf("期望注入 x-api-key 认证头，got %v", m.Headers)
+	}
+	if _, exists := m.Headers["Content-Type"]; exists || m.Headers["content-type"] != "application/json; charset=utf-8" {
+		t.Fatalf("用户配置的请求头应覆盖预设（大小写不敏感），got %v", m.Headers)
+	}
+	if !strings.Contains(m.Body, `"model":"claude-haiku-4-5"`) {
+		t.Fatalf("期望请求体包含 model，got %s", m.Body)
+	}
+	if len(m.Assertions) == 0 {
+		t.Fatalf("期望注入默认响应断言")
+	}
+}
+
+func TestApplyProtocolPresetRequiresModel(t *testing.T) {
+	t.Parallel()
+
+	cfg := AppConfig{Monitors: []ServiceConfig{{Provider: "demo", Service: "cx", Protocol: "openai-chat"}}}
+	if err := cfg.ApplyProtocolPresets(); err == nil {
+		t.Fatalf("期望缺少 model 时报错")
+	}
+
+	cfg = AppConfig{Monitors: []ServiceConfig{{Provider: "demo", Service: "cx", Protocol: "unknown"}}}
+	if err := cfg.ApplyProtocolPresets(); err == nil {
+		t.Fatalf("期望未知 protocol 时报错")
+	}
+}
+
+func TestRetentionConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (&RetentionConfig{HourlyDays: -2}).Validate(); err == nil {
+		t.Fatal("expected error for hourly_days < -1")
+	}
+
+	r := RetentionConfig{RawDays: 7, Interval: "6h"}
+	if err := r.Normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if r.RawDays != 7 || r.HourlyDays != 90 || r.DailyDays != 365 || r.IntervalDuration != 6*time.Hour {
+		t.Fatalf("unexpected normalized config: %+v", r)
+	}
+}
+
+func TestRetryConfigInherit(t *testing.T) {
+	t.Parallel()
+
+	if err := (&RetryConfig{Count: 6}).Validate(); err == nil {
+		t.Fatal("expected error for count > 5")
+	}
+
+	flaky := true
+	global := RetryConfig{Count: 2, Backoff: "500ms", FlakyAsDegraded: &flaky}
+	if err := global.Normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+
+	inherited := RetryConfig{}
+	if err := inherited.inherit(global); err != nil {
+		t.Fatalf("inherit: %v", err)
+	}
+	if inherited.Count != 2 || inherited.BackoffDuration != 500*time.Millisecond || !inherited.FlakyDegraded() {
+		t.Fatalf("unexpected inherited config: %+v", inherited)
+	}
+
+	notFlaky := false
+	disabled := RetryConfig{Count: -1, Backoff: "3s", FlakyAsDegraded: &notFlaky}
+	if err := disabled.inherit(global); err != nil {
+		t.Fatalf("inherit: %v", err)
+	}
+	if disabled.Count != 0 || disabled.BackoffDuration != 3*time.Second || disabled.FlakyDegraded() {
+		t.Fatalf("unexpected overridden config: %+v", disabled)
+	}
+}
+
+func TestTransportConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	invalid := []TransportConfig{
+		{Proxy: "ftp://proxy:21"},
+		{Proxy: "socks5://"},
+		{ClientCert: "client.pem"},
+		{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{DNSServer: "dns.example.com"},
+		{Hosts: map[string]string{"relay.test": "not-an-ip"}},
+	}
+	for _, tc := range invalid {
+		if err := tc.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", tc)
+		}
+	}
+
+	valid := TransportConfig{Proxy: "socks5h://127.0.0.1:1080", DNSServer: "1.1.1.1", Hosts: map[string]string{"relay.test": "10.0.0.1"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid.DNSServerAddr() != "1.1.1.1:53" {
+		t.Fatalf("unexpected DNS server address: %s", valid.DNSServerAddr())
+	}
+}
+
+func TestSchedulerConfigNormalize(t *testing.T) {
+	t.Parallel()
+
+	if err := (&SchedulerConfig{MaxConcurrencyPerHost: -1}).Validate(); err == nil {
+		t.Fatal("expected error for negative max_concurrency_per_host")
+	}
+
+	var s SchedulerConfig
+	if err := s.Normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if !s.StaggerEnabled() || s.JitterDuration != 0 || s.MaxConcurrency != 10 || s.MaxConcurrencyPerHost != 0 {
+		t.Fatalf("unexpected defaults: %+v", s)
+	}
+
+	off := false
+	s = SchedulerConfig{Stagger: &off, Jitter: "3s"}
+	if err := s.Normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if s.StaggerEnabled() || s.JitterDuration != 3*time.Second {
+		t.Fatalf("unexpected config: %+v", s)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"30 3 * * *", time.Date(2026, 3, 1, 3, 30, 0, 0, loc), time.Date(2026, 3, 2, 3, 30, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2026, 3, 1, 10, 7, 30, 0, loc), time.Date(2026, 3, 1, 10, 15, 0, 0, loc)},
+		{"0 2 * * 7", time.Date(2026, 3, 2, 0, 0, 0, 0, loc), time.Date(2026, 3, 8, 2, 0, 0, 0, loc)},         // 周日
+		{"0 0 1 * 1", time.Date(2026, 3, 2, 12, 0, 0, 0, loc), time.Date(2026, 3, 9, 0, 0, 0, 0, loc)},        // 日、周都限制时满足其一
+		{"0 4 29 2 *", time.Date(2026, 1, 1, 0, 0, 0, 0, loc), time.Date(2028, 2, 29, 4, 0, 0, 0, loc)},       // 闰年
+		{"0 9-17/4 * * 1-5", time.Date(2026, 3, 6, 17, 0, 0, 0, loc), time.Date(2026, 3, 9, 9, 0, 0, 0, loc)}, // 周五之后到周一
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.expr, err)
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Fatalf("%q after %v: got %v, want %v", tt.expr, tt.from, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+	if s, _ := ParseCron("0 0 30 2 *"); !s.Next(time.Now()).IsZero() {
+		t.Fatal("expected no occurrence for February 30")
+	}
+}
+
+func TestMaintenanceWindowOccurrences(t *testing.T) {
+	t.Parallel()
+
+	weekly := MaintenanceWindow{Provider: "demo", Schedule: "0 3 * * 0", Duration: "2h", Timezone: "Asia/Shanghai"}
+	if err := weekly.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := weekly.Normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if weekly.Mode != MaintenanceModeRecord || !weekly.Matches("demo", "cc", "vip") || weekly.Matches("other", "cc", "") {
+		t.Fatalf("unexpected window: %+v", weekly)
+	}
+
+	// 2026-03-08 是周日，维护时段为北京时间 03:00-05:00
+	sunday := time.Date(2026, 3, 8, 3, 0, 0, 0, weekly.Location)
+	got := weekly.Occurrences(sunday.Add(-7*24*time.Hour-time.Hour), sunday.Add(time.Hour))
+	if len(got) != 2 || !got[1].Start.Equal(sunday) || !got[1].End.Equal(sunday.Add(2*time.Hour)) {
+		t.Fatalf("unexpected occurrences: %+v", got)
+	}
+	if _, ok := weekly.ActiveAt(sunday.Add(119 * time.Minute)); !ok {
+		t.Fatal("expected window to be active before it ends")
+	}
+	if _, ok := weekly.ActiveAt(sunday.Add(2 * time.Hour)); ok {
+		t.Fatal("window end should be exclusive")
+	}
+
+	// 一次性窗口：不带时区的时间按 timezone 解析
+	once := MaintenanceWindow{Provider: "demo", Service: "cc", Start: "2026-03-10 02:00", End: "2026-03-10 04:00", Timezone: "Asia/Shanghai", Mode: MaintenanceModeSkip}
+	if err := once.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := once.Normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if once.StartTime.Unix() != time.Date(2026, 3, 9, 18, 0, 0, 0, time.UTC).Unix() {
+		t.Fatalf("unexpected start: %v", once.StartTime)
+	}
+	if _, ok := once.NextOccurrence(once.EndTime); ok {
+		t.Fatal("finished one-off window should have no next occurrence")
+	}
+
+	for _, bad := range []MaintenanceWindow{
+		{Service: "cc", Start: "2026-03-10 02:00", End: "2026-03-10 04:00"},
+		{Provider: "demo", Start: "2026-03-10 02:00"},
+		{Provider: "demo", Start: "2026-03-10 02:00", End: "2026-03-10 04:00", Schedule: "0 3 * * *"},
+		{Provider: "demo", Channel: "vip", Schedule: "0 3 * * *", Duration: "1h"},
+		{Provider: "demo", Schedule: "0 3 * * *", Duration: "1h", Mode: "pause"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", bad)
+		}
+	}
+	for _, bad := range []MaintenanceWindow{
+		{Provider: "demo", Start: "2026-03-10 04:00", End: "2026-03-10 02:00"},
+		{Provider: "demo", Schedule: "0 3 * * *", Duration: "200h"},
+		{Provider: "demo", Schedule: "0 3 * * *", Duration: "1h", Timezone: "Mars/Olympus"},
+	} {
+		if err := bad.Normalize(); err == nil {
+			t.Fatalf("expected normalize error for %+v", bad)
+		}
+	}
+}
+
+func TestAdminConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, bad := range []AdminConfig{
+		{Tokens: []AdminToken{{Name: "ops team", Token: "a"}}},
+		{Tokens: []AdminToken{{Name: "env", Token: "a"}}},
+		{Tokens: []AdminToken{{Name: "ops", Token: "a"}, {Name: "ops", Token: "b"}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", bad)
+		}
+	}
+	for _, bad := range []AdminConfig{
+		{Tokens: []AdminToken{{Name: "ops"}}},
+		{Tokens: []AdminToken{{Name: "ops", Token: "a"}, {Name: "ci", Token: "a"}}},
+	} {
+		if err := bad.Normalize(); err == nil {
+			t.Fatalf("expected normalize error for %+v", bad)
+		}
+	}
+
+	if got := adminTokenEnv("on-call"); got != "MONITOR_ADMIN_ON_CALL_TOKEN" {
+		t.Fatalf("unexpected env name: %s", got)
+	}
+}
+
+func TestResolveSecretReferences(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "openai.key"), []byte("sk-from-file\n"), 0o600); err != nil {
+		t.Fatalf("write secret file: %v", err)
+	}
+	key := strings.Repeat("k", 32)
+	sealed, err := secret.Encrypt([]byte(key), "sk-encrypted")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	env := map[string]string{
+		"OPENAI_KEY":        "sk-from-env",
+		secret.MasterKeyEnv: base64.StdEncoding.EncodeToString([]byte(key)),
+	}
+	r := newSecretResolver(dir)
+	r.getenv = func(name string) string { return env[name] }
+
+	for value, want := range map[string]string{
+		"sk-plain":        "sk-plain",
+		"":                "",
+		"file:openai.key": "sk-from-file",
+		"file:" + filepath.Join(dir, "openai.key"): "sk-from-file",
+		"env:OPENAI_KEY": "sk-from-env",
+		sealed:           "sk-encrypted",
+	} {
+		got, err := r.resolve(value)
+		if err != nil || got != want {
+			t.Fatalf("resolve(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+
+	for _, bad := range []string{"file:", "file:missing.key", "env:", "env:UNSET", "enc:v1:not-base64"} {
+		if _, err := r.resolve(bad); err == nil {
+			t.Fatalf("expected error resolving %q", bad)
+		}
+	}
+
+	// 未设置主密钥时加密值无法解析
+	noKey := newSecretResolver(dir)
+	noKey.getenv = func(string) string { return "" }
+	if _, err := noKey.resolve(sealed); !errors.Is(err, secret.ErrNoMasterKey) {
+		t.Fatalf("expected ErrNoMasterKey, got %v", err)
+	}
+}
+
+func TestAPIKeyEnvNames(t *testing.T) {
+	t.Parallel()
+
+	m := ServiceConfig{Provider: "open-ai", Service: "cc", Channel: "vip-1"}
+	got := m.apiKeyEnvNames()
+	if len(got) != 2 || got[0] != "MONITOR_OPEN_AI_CC_VIP_1_API_KEY" || got[1] != "MONITOR_OPEN_AI_CC_API_KEY" {
+		t.Fatalf("unexpected env names: %v", got)
+	}
+
+	m.Channel = ""
+	if got := m.apiKeyEnvNames(); len(got) != 1 || got[0] != "MONITOR_OPEN_AI_CC_API_KEY" {
+		t.Fatalf("unexpected env names without channel: %v", got)
+	}
+}
+
+func TestAPIKeyEnvNamesMustBeUnambiguous(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		monitors []ServiceConfig
+		wantErr  bool
+	}{
+		{"channels share fallback", []ServiceConfig{{Provider: "p", Service: "s", Channel: "x"}, {Provider: "p", Service: "s", Channel: "y"}, {Provider: "p", Service: "s"}}, false},
+		{"provider and service split", []ServiceConfig{{Provider: "a-b", Service: "c"}, {Provider: "a", Service: "b-c"}}, true},
+		{"channel vs service suffix", []ServiceConfig{{Provider: "p", Service: "s", Channel: "x"}, {Provider: "p", Service: "s-x"}}, true},
+		{"channel vs channel", []ServiceConfig{{Provider: "p", Service: "s", Channel: "x-y"}, {Provider: "p", Service: "s-x", Channel: "y"}}, true},
+	}
+	for _, tc := range cases {
+		cfg := &AppConfig{Monitors: tc.monitors}
+		if err := cfg.validateAPIKeyEnvNames(); (err != nil) != tc.wantErr {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+}
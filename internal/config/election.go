@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultLeaseTTL 主节点租约默认有效期
+const defaultLeaseTTL = 15 * time.Second
+
+// minLeaseTTL 租约有效期下限（续期间隔为有效期的 1/3，过短会频繁访问数据库）
+const minLeaseTTL = 3 * time.Second
+
+// ElectionConfig 多副本主节点选举（基于共享存储中的租约）
+// 开启后只有持有租约的副本执行探测和数据清理，所有副本都提供 API 服务
+type ElectionConfig struct {
+	// 是否开启选举（默认 false，单副本部署无需开启）
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// 副本标识（默认 主机名-进程号），可通过环境变量 MONITOR_ELECTION_ID 覆盖
+	ID string `yaml:"id" json:"id"`
+
+	// 租约有效期（默认 "15s"），主节点每 1/3 有效期续期一次，失联超过有效期后由其他副本接管
+	LeaseTTL string `yaml:"lease_ttl" json:"lease_ttl"`
+
+	// 解析后的租约有效期（内部使用，不序列化）
+	LeaseTTLDuration time.Duration `yaml:"-" json:"-"`
+}
+
+// Validate 验证选举配置
+func (e *ElectionConfig) Validate() error {
+	if e.LeaseTTL == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(e.LeaseTTL)
+	if err != nil {
+		return fmt.Errorf("election: 解析 lease_ttl 失败: %w", err)
+	}
+	if d < minLeaseTTL {
+		return fmt.Errorf("election: lease_ttl 不能小于 %v，当前值: %s", minLeaseTTL, e.LeaseTTL)
+	}
+	return nil
+}
+
+// Normalize 填充选举配置默认值
+func (e *ElectionConfig) Normalize() error {
+	var err error
+	if e.LeaseTTLDuration, err = parseMonitorDuration(e.LeaseTTL, defaultLeaseTTL); err != nil {
+		return fmt.Errorf("election: 解析 lease_ttl 失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import "fmt"
+
+// defaultFlappingWindow 统计状态变化频率的默认探测次数
+const defaultFlappingWindow = 20
+
+// maxFlappingWindow 统计窗口上限（每次查询 /api/status 需读取该数量的记录）
+const maxFlappingWindow = 500
+
+// defaultFlappingThreshold 判定为震荡的默认状态变化频率
+const defaultFlappingThreshold = 0.5
+
+// FlappingConfig 状态震荡检测与当前状态滞回
+type FlappingConfig struct {
+	// 统计状态变化频率的最近探测次数（默认 20，-1 表示关闭震荡检测）
+	Window int `yaml:"window" json:"window"`
+
+	// 状态变化频率（0-1，相邻两次有效探测状态不同记为一次变化）达到该值时判定为震荡（默认 0.5）
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+
+	// 当前状态需连续 N 次相同的探测结果才切换（默认 1，即直接使用最新一次探测）
+	Hysteresis int `yaml:"hysteresis" json:"hysteresis"`
+}
+
+// Validate 验证震荡检测配置
+func (f *FlappingConfig) Validate() error {
+	if f.Window < -1 || f.Window > maxFlappingWindow {
+		return fmt.Errorf("flapping: window 必须在 -1 到 %d 之间（0 表示使用默认值，-1 表示关闭），当前值: %d", maxFlappingWindow, f.Window)
+	}
+	if f.Threshold < 0 || f.Threshold > 1 {
+		return fmt.Errorf("flapping: threshold 必须在 0 到 1 之间（0 表示使用默认值 %.1f），当前值: %.2f", defaultFlappingThreshold, f.Threshold)
+	}
+	if f.Hysteresis < 0 || f.Hysteresis > maxFlappingWindow/2 {
+		return fmt.Errorf("flapping: hysteresis 必须在 0 到 %d 之间（0 表示使用默认值 1），当前值: %d", maxFlappingWindow/2, f.Hysteresis)
+	}
+	return nil
+}
+
+// Normalize 填充震荡检测配置默认值
+func (f *FlappingConfig) Normalize() error {
+	if f.Window == 0 {
+		f.Window = defaultFlappingWindow
+	}
+	if f.Threshold == 0 {
+		f.Threshold = defaultFlappingThreshold
+	}
+	if f.Hysteresis == 0 {
+		f.Hysteresis = 1
+	}
+	return nil
+}
+
+// Enabled 是否开启震荡检测
+func (f *FlappingConfig) Enabled() bool {
+	return f.Window > 0
+}
+
+// SampleSize 判定震荡和稳定状态需要读取的最近探测次数
+func (f *FlappingConfig) SampleSize() int {
+	if !f.Enabled() && f.Hysteresis <= 1 {
+		return 0
+	}
+	// 至少读取两倍滞回次数，使稳定状态的判定有足够的上下文
+	return max(f.Window, 2*f.Hysteresis)
+}
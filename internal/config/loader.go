@@ -8,9 +8,13 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// MonitorSource 提供替代配置文件 monitors 的监控项定义（monitor_store 模式下来自数据库）
+type MonitorSource func() ([]ServiceConfig, error)
+
 // Loader 配置加载器
 type Loader struct {
 	currentConfig *AppConfig
+	monitorSource MonitorSource
 }
 
 // NewLoader 创建配置加载器
@@ -18,8 +22,59 @@ func NewLoader() *Loader {
 	return &Loader{}
 }
 
+// SetMonitorSource 设置监控项来源（需在开始监听配置变更前调用），设置后忽略配置文件中的 monitors
+func (l *Loader) SetMonitorSource(source MonitorSource) {
+	l.monitorSource = source
+}
+
 // Load 加载并验证配置文件
 func (l *Loader) Load(filename string) (*AppConfig, error) {
+	cfg, err := readConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.monitorSource != nil {
+		monitors, err := l.monitorSource()
+		if err != nil {
+			return nil, fmt.Errorf("加载数据库中的监控项失败: %w", err)
+		}
+		cfg.Monitors = monitors
+	}
+
+	if err := cfg.prepare(filename); err != nil {
+		return nil, err
+	}
+
+	l.currentConfig = cfg
+	return cfg, nil
+}
+
+// Preview 以指定的监控项替换配置文件中的 monitors，完成与 Load 相同的验证和处理，但不替换当前配置
+func (l *Loader) Preview(filename string, monitors []ServiceConfig) (*AppConfig, error) {
+	cfg, err := readConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Monitors = monitors
+
+	if err := cfg.prepare(filename); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadMonitors 读取配置文件中未经处理的 monitors（用于导入数据库）
+func (l *Loader) ReadMonitors(filename string) ([]ServiceConfig, error) {
+	cfg, err := readConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Monitors, nil
+}
+
+// readConfig 读取并解析配置文件
+func readConfig(filename string) (*AppConfig, error) {
 	// 读取文件
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -31,38 +86,50 @@ func (l *Loader) Load(filename string) (*AppConfig, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
+	return &cfg, nil
+}
 
+// prepare 验证配置并完成环境变量覆盖、body include、默认值和占位符处理
+func (c *AppConfig) prepare(filename string) error {
 	absPath, err := filepath.Abs(filename)
 	if err != nil {
-		return nil, fmt.Errorf("解析配置文件路径失败: %w", err)
+		return fmt.Errorf("解析配置文件路径失败: %w", err)
 	}
 	configDir := filepath.Dir(absPath)
 
+	// 应用协议预设（填充 method/headers/body 等默认值，需在验证前执行）
+	if err := c.ApplyProtocolPresets(); err != nil {
+		return fmt.Errorf("配置验证失败: %w", err)
+	}
+
 	// 验证配置
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("配置验证失败: %w", err)
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("配置验证失败: %w", err)
 	}
 
 	// 应用环境变量覆盖
-	cfg.ApplyEnvOverrides()
+	c.ApplyEnvOverrides()
+
+	// 解析 api_key 中的密钥引用（file:/env:/enc:v1:）
+	if err := c.ResolveSecrets(configDir); err != nil {
+		return err
+	}
 
 	// 解析 body include
-	if err := cfg.ResolveBodyIncludes(configDir); err != nil {
-		return nil, err
+	if err := c.ResolveBodyIncludes(configDir); err != nil {
+		return err
 	}
 
 	// 规范化配置（填充默认值等）
-	if err := cfg.Normalize(); err != nil {
-		return nil, fmt.Errorf("配置规范化失败: %w", err)
+	if err := c.Normalize(); err != nil {
+		return fmt.Errorf("配置规范化失败: %w", err)
 	}
 
 	// 处理占位符
-	for i := range cfg.Monitors {
-		cfg.Monitors[i].ProcessPlaceholders()
+	for i := range c.Monitors {
+		c.Monitors[i].ProcessPlaceholders()
 	}
-
-	l.currentConfig = &cfg
-	return &cfg, nil
+	return nil
 }
 
 // LoadOrRollback 加载配置，失败时保持旧配置
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 维护期间的探测方式
+const (
+	MaintenanceModeRecord = "record" // 照常探测，结果记为维护状态（默认）
+	MaintenanceModeSkip   = "skip"   // 跳过探测，不产生记录
+)
+
+// maxMaintenanceDuration 周期性维护窗口单次持续时间上限
+const maxMaintenanceDuration = 7 * 24 * time.Hour
+
+// maxMaintenanceOccurrences 单次查询展开的周期性维护次数上限（避免 "* * * * *" 之类的表达式展开过多）
+const maxMaintenanceOccurrences = 10000
+
+// maintenanceTimeLayouts 一次性维护窗口支持的时间格式（不带时区的格式按 timezone 解析）
+var maintenanceTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// MaintenanceConfig 计划维护配置
+type MaintenanceConfig struct {
+	// 通过 API 管理维护窗口的凭证（为空时只能在配置文件中声明），建议通过环境变量 MONITOR_MAINTENANCE_TOKEN 设置
+	Token string `yaml:"token" json:"-"`
+
+	// 配置文件中声明的维护窗口
+	Windows []MaintenanceWindow `yaml:"windows" json:"windows"`
+}
+
+// Validate 验证维护配置
+func (m *MaintenanceConfig) Validate() error {
+	for i := range m.Windows {
+		if err := m.Windows[i].Validate(); err != nil {
+			return fmt.Errorf("maintenance.windows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Normalize 解析维护窗口的时间与周期
+func (m *MaintenanceConfig) Normalize() error {
+	for i := range m.Windows {
+		if err := m.Windows[i].Normalize(); err != nil {
+			return fmt.Errorf("maintenance.windows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// MaintenanceWindow 维护窗口：一次性（start/end）或周期性（schedule/duration）
+// service、channel 为空时匹配 provider 下的全部监控项
+type MaintenanceWindow struct {
+	// 由 API 创建的窗口在数据库中的 ID（配置文件中的窗口为 0）
+	ID int64 `yaml:"-" json:"id"`
+
+	Provider string `yaml:"provider" json:"provider"`
+	Service  string `yaml:"service" json:"service"`
+	Channel  string `yaml:"channel" json:"channel"`
+
+	// 一次性窗口的起止时间（RFC3339，或 "2006-01-02 15:04" 按 timezone 解析）
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+
+	// 周期性窗口：5 段 cron 表达式（分 时 日 月 周）表示每次开始时间，duration 为每次持续时间
+	Schedule string `yaml:"schedule" json:"schedule"`
+	Duration string `yaml:"duration" json:"duration"`
+
+	// 解析时间与 cron 表达式使用的时区（IANA 名称，如 "Asia/Shanghai"），默认服务器本地时区
+	Timezone string `yaml:"timezone" json:"timezone"`
+
+	// 维护期间的探测方式：record（默认）/ skip
+	Mode string `yaml:"mode" json:"mode"`
+
+	// 维护说明（展示在时间轴上）
+	Reason string `yaml:"reason" json:"reason"`
+
+	// 解析后的字段（内部使用，不序列化）
+	StartTime time.Time      `yaml:"-" json:"-"`
+	EndTime   time.Time      `yaml:"-" json:"-"`
+	Cron      *CronSchedule  `yaml:"-" json:"-"`
+	Length    time.Duration  `yaml:"-" json:"-"`
+	Location  *time.Location `yaml:"-" json:"-"`
+}
+
+// MaintenanceInterval 维护窗口的一次发生
+type MaintenanceInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Validate 验证维护窗口的必填字段和组合
+func (w *MaintenanceWindow) Validate() error {
+	if strings.TrimSpace(w.Provider) == "" {
+		return fmt.Errorf("provider 不能为空")
+	}
+	if w.Channel != "" && w.Service == "" {
+		return fmt.Errorf("指定 channel 时 service 不能为空")
+	}
+
+	oneOff := w.Start != "" || w.End != ""
+	recurring := w.Schedule != "" || w.Duration != ""
+	switch {
+	case oneOff && recurring:
+		return fmt.Errorf("start/end 与 schedule/duration 只能二选一")
+	case oneOff && (w.Start == "" || w.End == ""):
+		return fmt.Errorf("一次性维护窗口需要同时配置 start 和 end")
+	case recurring && (w.Schedule == "" || w.Duration == ""):
+		return fmt.Errorf("周期性维护窗口需要同时配置 schedule 和 duration")
+	case !oneOff && !recurring:
+		return fmt.Errorf("需要配置 start/end 或 schedule/duration")
+	}
+
+	switch w.Mode {
+	case "", MaintenanceModeRecord, MaintenanceModeSkip:
+	default:
+		return fmt.Errorf("mode 只能是 %s 或 %s，收到: %q", MaintenanceModeRecord, MaintenanceModeSkip, w.Mode)
+	}
+	return nil
+}
+
+// Normalize 填充默认值并解析时间、时区和 cron 表达式
+func (w *MaintenanceWindow) Normalize() error {
+	if w.Mode == "" {
+		w.Mode = MaintenanceModeRecord
+	}
+
+	w.Location = time.Local
+	if w.Timezone != "" {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone 无效: %w", err)
+		}
+		w.Location = loc
+	}
+
+	if w.Schedule != "" {
+		cron, err := ParseCron(w.Schedule)
+		if err != nil {
+			return err
+		}
+		d, err := time.ParseDuration(w.Duration)
+		if err != nil {
+			return fmt.Errorf("解析 duration 失败: %w", err)
+		}
+		if d <= 0 || d > maxMaintenanceDuration {
+			return fmt.Errorf("duration 必须大于 0 且不超过 %v，当前值: %v", maxMaintenanceDuration, d)
+		}
+		w.Cron, w.Length = cron, d
+		return nil
+	}
+
+	var err error
+	if w.StartTime, err = parseMaintenanceTime(w.Start, w.Location); err != nil {
+		return fmt.Errorf("解析 start 失败: %w", err)
+	}
+	if w.EndTime, err = parseMaintenanceTime(w.End, w.Location); err != nil {
+		return fmt.Errorf("解析 end 失败: %w", err)
+	}
+	if !w.EndTime.After(w.StartTime) {
+		return fmt.Errorf("end 必须晚于 start")
+	}
+	return nil
+}
+
+// parseMaintenanceTime 按 maintenanceTimeLayouts 依次尝试解析
+func parseMaintenanceTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range maintenanceTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("时间格式无效（支持 RFC3339 或 \"2006-01-02 15:04\"）: %q", value)
+}
+
+// Recurring 是否为周期性窗口
+func (w *MaintenanceWindow) Recurring() bool {
+	return w.Cron != nil
+}
+
+// Matches 窗口是否作用于指定监控项
+func (w *MaintenanceWindow) Matches(provider, service, channel string) bool {
+	if w.Provider != provider {
+		return false
+	}
+	if w.Service != "" && w.Service != service {
+		return false
+	}
+	return w.Channel == "" || w.Channel == channel
+}
+
+// Occurrences 返回与 [from, to) 有交集的维护时段（按开始时间升序，相互重叠的周期合并为一段）
+func (w *MaintenanceWindow) Occurrences(from, to time.Time) []MaintenanceInterval {
+	if !w.Recurring() {
+		if w.StartTime.Before(to) && w.EndTime.After(from) {
+			return []MaintenanceInterval{{Start: w.StartTime, End: w.EndTime}}
+		}
+		return nil
+	}
+
+	var result []MaintenanceInterval
+	start := w.Cron.Next(from.Add(-w.Length).Add(-time.Minute).In(w.Location))
+	for n := 0; !start.IsZero() && start.Before(to) && n < maxMaintenanceOccurrences; n++ {
+		end := start.Add(w.Length)
+		if end.After(from) {
+			if last := len(result) - 1; last >= 0 && !start.After(result[last].End) {
+				result[last].End = end
+			} else {
+				result = append(result, MaintenanceInterval{Start: start, End: end})
+			}
+		}
+		start = w.Cron.Next(start)
+	}
+	return result
+}
+
+// ActiveAt 返回包含 t 的维护时段
+func (w *MaintenanceWindow) ActiveAt(t time.Time) (MaintenanceInterval, bool) {
+	for _, iv := range w.Occurrences(t, t.Add(time.Second)) {
+		if !t.Before(iv.Start) && t.Before(iv.End) {
+			return iv, true
+		}
+	}
+	return MaintenanceInterval{}, false
+}
+
+// NextOccurrence 返回正在进行或下一次的维护时段（一次性窗口已结束时返回 false）
+func (w *MaintenanceWindow) NextOccurrence(now time.Time) (MaintenanceInterval, bool) {
+	if iv, ok := w.ActiveAt(now); ok {
+		return iv, true
+	}
+	if !w.Recurring() {
+		if w.StartTime.After(now) {
+			return MaintenanceInterval{Start: w.StartTime, End: w.EndTime}, true
+		}
+		return MaintenanceInterval{}, false
+	}
+	start := w.Cron.Next(now.In(w.Location))
+	if start.IsZero() {
+		return MaintenanceInterval{}, false
+	}
+	return MaintenanceInterval{Start: start, End: start.Add(w.Length)}, true
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultMonitorSyncInterval 检查其他副本修改监控项的默认间隔
+const defaultMonitorSyncInterval = time.Minute
+
+// minMonitorSyncInterval 检查间隔下限（每次检查需读取全部监控项定义）
+const minMonitorSyncInterval = 5 * time.Second
+
+// MonitorStoreConfig 数据库中的监控项（修改后需重启生效）
+// 开启后监控项保存在数据库 monitors 表中，通过管理 API 增删改，配置文件中的 monitors 只在表为空时导入
+type MonitorStoreConfig struct {
+	// 是否开启（默认 false，监控项只来自配置文件）
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// 检查其他副本修改的间隔（默认 "1m"）
+	SyncInterval string `yaml:"sync_interval" json:"sync_interval"`
+
+	// 解析后的检查间隔（内部使用，不序列化）
+	SyncIntervalDuration time.Duration `yaml:"-" json:"-"`
+}
+
+// Validate 验证数据库监控项配置
+func (s *MonitorStoreConfig) Validate() error {
+	if s.SyncInterval == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(s.SyncInterval)
+	if err != nil {
+		return fmt.Errorf("monitor_store: 解析 sync_interval 失败: %w", err)
+	}
+	if d < minMonitorSyncInterval {
+		return fmt.Errorf("monitor_store: sync_interval 不能小于 %v，当前值: %s", minMonitorSyncInterval, s.SyncInterval)
+	}
+	return nil
+}
+
+// Normalize 填充数据库监控项配置默认值
+func (s *MonitorStoreConfig) Normalize() error {
+	var err error
+	if s.SyncIntervalDuration, err = parseMonitorDuration(s.SyncInterval, defaultMonitorSyncInterval); err != nil {
+		return fmt.Errorf("monitor_store: 解析 sync_interval 失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// protocolPreset 协议预设：为监控项提供默认的请求方法、请求头、请求体和响应断言
+type protocolPreset struct {
+	method       string
+	headers      map[string]string
+	requireModel bool
+	// buildBody 生成最小请求体（model 已校验非空；stream 表示是否开启流式）
+	buildBody func(model string, stream bool) map[string]any
+	// assertions 非流式模式下的默认响应断言
+	assertions []AssertionConfig
+}
+
+// protocolPresets 内置协议预设
+var protocolPresets = map[string]protocolPreset{
+	"openai-chat": {
+		method: "POST",
+		headers: map[string]string{
+			"Authorization": "Bearer {{API_KEY}}",
+			"Content-Type":  "application/json",
+		},
+		requireModel: true,
+		buildBody: func(model string, stream bool) map[string]any {
+			body := map[string]any{
+				"model":      model,
+				"messages":   []map[string]string{{"role": "user", "content": "hi"}},
+				"max_tokens": 1,
+			}
+			if stream {
+				body["stream"] = true
+			}
+			return body
+		},
+		assertions: []AssertionConfig{
+			{Type: AssertionJSON, Path: "$.choices[0]", Op: AssertOpExists},
+		},
+	},
+	"openai-responses": {
+		method: "POST",
+		headers: map[string]string{
+			"Authorization": "Bearer {{API_KEY}}",
+			"Content-Type":  "application/json",
+		},
+		requireModel: true,
+		buildBody: func(model string, stream bool) map[string]any {
+			body := map[string]any{
+				"model":             model,
+				"input":             "hi",
+				"max_output_tokens": 16,
+			}
+			if stream {
+				body["stream"] = true
+			}
+			return body
+		},
+		assertions: []AssertionConfig{
+			{Type: AssertionJSON, Path: "$.output", Op: AssertOpExists},
+		},
+	},
+	"openai-models": {
+		method: "GET",
+		headers: map[string]string{
+			"Authorization": "Bearer {{API_KEY}}",
+		},
+		assertions: []AssertionConfig{
+			{Type: AssertionJSON, Path: "$.data", Op: AssertOpExists},
+		},
+	},
+	"anthropic-messages": {
+		method: "POST",
+		headers: map[string]string{
+			"x-api-key":         "{{API_KEY}}",
+			"anthropic-version": "2023-06-01",
+			"Content-Type":      "application/json",
+		},
+		requireModel: true,
+		buildBody: func(model string, stream bool) map[string]any {
+			body := map[string]any{
+				"model":      model,
+				"messages":   []map[string]string{{"role": "user", "content": "hi"}},
+				"max_tokens": 1,
+			}
+			if stream {
+				body["stream"] = true
+			}
+			return body
+		},
+		assertions: []AssertionConfig{
+			{Type: AssertionJSON, Path: "$.type", Op: AssertOpEquals, Value: "message"},
+		},
+	},
+	"gemini-generate": {
+		// URL 中可使用 {{MODEL}} 占位符，例如 /v1beta/models/{{MODEL}}:generateContent
+		// 流式需使用 :streamGenerateContent?alt=sse 端点，请求体相同
+		method: "POST",
+		headers: map[string]string{
+			"x-goog-api-key": "{{API_KEY}}",
+			"Content-Type":   "application/json",
+		},
+		requireModel: true,
+		buildBody: func(model string, stream bool) map[string]any {
+			return map[string]any{
+				"contents": []map[string]any{
+					{"role": "user", "parts": []map[string]string{{"text": "hi"}}},
+				},
+				"generationConfig": map[string]any{"maxOutputTokens": 1},
+			}
+		},
+		assertions: []AssertionConfig{
+			{Type: AssertionJSON, Path: "$.candidates[0]", Op: AssertOpExists},
+		},
+	},
+}
+
+// ProtocolNames 返回所有内置协议名称（排序后）
+func ProtocolNames() []string {
+	names := make([]string, 0, len(protocolPresets))
+	for name := range protocolPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProtocolPresets 为配置了 protocol 的监控项填充默认值（需在 Validate 之前调用）
+func (c *AppConfig) ApplyProtocolPresets() error {
+	for i := range c.Monitors {
+		if err := c.Monitors[i].applyProtocolPreset(); err != nil {
+			return fmt.Errorf("monitor[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// applyProtocolPreset 按协议预设填充 method、headers、body 和断言
+// 显式配置的字段优先：headers 逐项合并，method/body/断言仅在未配置时使用预设
+func (m *ServiceConfig) applyProtocolPreset() error {
+	m.Protocol = strings.ToLower(strings.TrimSpace(m.Protocol))
+	if m.Protocol == "" {
+		return nil
+	}
+
+	preset, ok := protocolPresets[m.Protocol]
+	if !ok {
+		return fmt.Errorf("protocol '%s' 无效，必须是 %s 之一", m.Protocol, strings.Join(ProtocolNames(), "/"))
+	}
+
+	m.Model = strings.TrimSpace(m.Model)
+	if preset.requireModel && m.Model == "" {
+		return fmt.Errorf("protocol '%s' 需要配置 model", m.Protocol)
+	}
+
+	if m.Method == "" {
+		m.Method = preset.method
+	}
+
+	if len(preset.headers) > 0 {
+		merged := make(map[string]string, len(preset.headers)+len(m.Headers))
+		for k, v := range preset.headers {
+			merged[k] = v
+		}
+		for k, v := range m.Headers {
+			// 大小写不敏感覆盖，避免同时出现 Content-Type 和 content-type
+			for pk := range merged {
+				if strings.EqualFold(pk, k) {
+					delete(merged, pk)
+				}
+			}
+			merged[k] = v
+		}
+		m.Headers = merged
+	}
+
+	if strings.TrimSpace(m.Body) == "" && preset.buildBody != nil {
+		data, err := json.Marshal(preset.buildBody(m.Model, m.Stream))
+		if err != nil {
+			return fmt.Errorf("生成 protocol '%s' 请求体失败: %w", m.Protocol, err)
+		}
+		m.Body = string(data)
+	}
+
+	// 流式模式由 SSE 解析负责校验，JSON 断言不适用
+	if !m.Stream && len(m.Assertions) == 0 && m.SuccessContains == "" {
+		m.Assertions = append([]AssertionConfig(nil), preset.assertions...)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// 数据保留默认值（天）
+const (
+	defaultRawRetentionDays    = 30
+	defaultHourlyRetentionDays = 90
+	defaultDailyRetentionDays  = 365
+)
+
+// RetentionConfig 数据保留与清理策略
+type RetentionConfig struct {
+	// 原始探测记录保留天数（默认 30，-1 表示永久保留）
+	RawDays int `yaml:"raw_days" json:"raw_days"`
+
+	// 小时聚合数据保留天数（默认 90，-1 表示永久保留）
+	HourlyDays int `yaml:"hourly_days" json:"hourly_days"`
+
+	// 天聚合数据保留天数（默认 365，-1 表示永久保留）
+	DailyDays int `yaml:"daily_days" json:"daily_days"`
+
+	// 清理任务执行间隔（默认 "24h"），启动时会先执行一次
+	Interval string `yaml:"interval" json:"interval"`
+
+	// 解析后的清理间隔（内部使用，不序列化）
+	IntervalDuration time.Duration `yaml:"-" json:"-"`
+
+	// 清理后执行 VACUUM 回收磁盘空间（SQLite 会重写整个数据库文件，大库耗时较长）
+	Vacuum bool `yaml:"vacuum" json:"vacuum"`
+
+	// 清理后执行 ANALYZE 更新查询统计信息
+	Analyze bool `yaml:"analyze" json:"analyze"`
+}
+
+// Validate 验证数据保留配置
+func (r *RetentionConfig) Validate() error {
+	fields := []struct {
+		name string
+		days int
+	}{
+		{"raw_days", r.RawDays},
+		{"hourly_days", r.HourlyDays},
+		{"daily_days", r.DailyDays},
+	}
+	for _, f := range fields {
+		if f.days < -1 {
+			return fmt.Errorf("retention: %s 不能小于 -1（0 表示使用默认值，-1 表示永久保留），当前值: %d", f.name, f.days)
+		}
+	}
+	return nil
+}
+
+// Normalize 填充数据保留配置默认值
+func (r *RetentionConfig) Normalize() error {
+	if r.RawDays == 0 {
+		r.RawDays = defaultRawRetentionDays
+	}
+	if r.HourlyDays == 0 {
+		r.HourlyDays = defaultHourlyRetentionDays
+	}
+	if r.DailyDays == 0 {
+		r.DailyDays = defaultDailyRetentionDays
+	}
+
+	var err error
+	if r.IntervalDuration, err = parseMonitorDuration(r.Interval, 24*time.Hour); err != nil {
+		return fmt.Errorf("retention: 解析 interval 失败: %w", err)
+	}
+	return nil
+}
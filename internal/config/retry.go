@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxRetryCount 单次探测的最大重试次数
+const maxRetryCount = 5
+
+// defaultRetryBackoff 首次重试前的默认等待时间
+const defaultRetryBackoff = time.Second
+
+// RetryConfig 探测失败重试策略（全局配置为默认值，监控项可单独覆盖）
+type RetryConfig struct {
+	// 红色探测的最大重试次数（全局默认 0 不重试；监控项中 0 表示沿用全局值，-1 表示关闭重试）
+	Count int `yaml:"count" json:"count"`
+
+	// 首次重试前的等待时间，之后每次翻倍（默认 "1s"）
+	Backoff string `yaml:"backoff" json:"backoff"`
+
+	// 解析后的等待时间（内部使用，不序列化）
+	BackoffDuration time.Duration `yaml:"-" json:"-"`
+
+	// 重试后成功时记为黄色 flaky（默认 false，记为绿色），未配置时沿用全局值
+	FlakyAsDegraded *bool `yaml:"flaky_as_degraded" json:"flaky_as_degraded"`
+}
+
+// Validate 验证重试配置
+func (r *RetryConfig) Validate() error {
+	if r.Count < -1 || r.Count > maxRetryCount {
+		return fmt.Errorf("retry: count 必须在 -1 到 %d 之间（0 表示使用默认值，-1 表示关闭重试），当前值: %d", maxRetryCount, r.Count)
+	}
+	return nil
+}
+
+// Normalize 填充全局重试配置默认值
+func (r *RetryConfig) Normalize() error {
+	if r.Count < 0 {
+		r.Count = 0
+	}
+
+	var err error
+	if r.BackoffDuration, err = parseMonitorDuration(r.Backoff, defaultRetryBackoff); err != nil {
+		return fmt.Errorf("retry: 解析 backoff 失败: %w", err)
+	}
+
+	if r.FlakyAsDegraded == nil {
+		r.FlakyAsDegraded = new(bool)
+	}
+	return nil
+}
+
+// inherit 以全局配置补齐监控项未配置的字段（global 需已 Normalize）
+func (r *RetryConfig) inherit(global RetryConfig) error {
+	switch {
+	case r.Count == 0:
+		r.Count = global.Count
+	case r.Count < 0:
+		r.Count = 0
+	}
+
+	var err error
+	if r.BackoffDuration, err = parseMonitorDuration(r.Backoff, global.BackoffDuration); err != nil {
+		return fmt.Errorf("解析 retry.backoff 失败: %w", err)
+	}
+
+	if r.FlakyAsDegraded == nil {
+		r.FlakyAsDegraded = global.FlakyAsDegraded
+	}
+	return nil
+}
+
+// FlakyDegraded 重试后成功时是否记为黄色 flaky
+func (r *RetryConfig) FlakyDegraded() bool {
+	return r.FlakyAsDegraded != nil && *r.FlakyAsDegraded
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultMaxConcurrency 默认同时进行的探测数量上限
+const defaultMaxConcurrency = 10
+
+// SchedulerConfig 调度策略（错峰、抖动、并发限制）
+type SchedulerConfig struct {
+	// 按监控项固定偏移把探测均匀分散到巡检间隔内（默认 true），关闭后所有监控项在同一时刻触发
+	Stagger *bool `yaml:"stagger" json:"stagger"`
+
+	// 每次执行时额外增加的随机延迟上限（如 "5s"，默认不抖动），实际不超过监控项 interval 的一半
+	Jitter string `yaml:"jitter" json:"jitter"`
+
+	// 解析后的抖动上限（内部使用，不序列化）
+	JitterDuration time.Duration `yaml:"-" json:"-"`
+
+	// 全局同时进行的探测数量上限（默认 10）
+	MaxConcurrency int `yaml:"max_concurrency" json:"max_concurrency"`
+
+	// 同一目标主机（URL 中的 host:port）同时进行的探测数量上限（默认 0 不限制）
+	MaxConcurrencyPerHost int `yaml:"max_concurrency_per_host" json:"max_concurrency_per_host"`
+}
+
+// Validate 验证调度配置
+func (s *SchedulerConfig) Validate() error {
+	if s.MaxConcurrency < 0 {
+		return fmt.Errorf("scheduler: max_concurrency 不能为负数，当前值: %d", s.MaxConcurrency)
+	}
+	if s.MaxConcurrencyPerHost < 0 {
+		return fmt.Errorf("scheduler: max_concurrency_per_host 不能为负数，当前值: %d", s.MaxConcurrencyPerHost)
+	}
+	return nil
+}
+
+// Normalize 填充调度配置默认值
+func (s *SchedulerConfig) Normalize() error {
+	if s.Stagger == nil {
+		stagger := true
+		s.Stagger = &stagger
+	}
+
+	s.JitterDuration = 0
+	if jitter := strings.TrimSpace(s.Jitter); jitter != "" {
+		d, err := time.ParseDuration(jitter)
+		if err != nil {
+			return fmt.Errorf("scheduler: 解析 jitter 失败: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("scheduler: jitter 不能为负数")
+		}
+		s.JitterDuration = d
+	}
+
+	if s.MaxConcurrency == 0 {
+		s.MaxConcurrency = defaultMaxConcurrency
+	}
+	return nil
+}
+
+// StaggerEnabled 是否开启错峰调度
+func (s *SchedulerConfig) StaggerEnabled() bool {
+	return s.Stagger != nil && *s.Stagger
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"monitor/internal/secret"
+)
+
+// api_key 中的密钥引用前缀（加密值使用 secret 包的 enc:v1: 格式）
+const (
+	secretFilePrefix = "file:" // 从文件读取，如 file:/run/secrets/openai
+	secretEnvPrefix  = "env:"  // 从指定环境变量读取，如 env:OPENAI_KEY
+)
+
+// apiKeyEnvNames 监控项 API Key 覆盖环境变量名（按优先级排列）
+// 设置了 channel 时 MONITOR_<PROVIDER>_<SERVICE>_<CHANNEL>_API_KEY 优先于 MONITOR_<PROVIDER>_<SERVICE>_API_KEY
+func (m *ServiceConfig) apiKeyEnvNames() []string {
+	base := "MONITOR_" + envSegment(m.Provider) + "_" + envSegment(m.Service)
+	if m.Channel == "" {
+		return []string{base + "_API_KEY"}
+	}
+	return []string{base + "_" + envSegment(m.Channel) + "_API_KEY", base + "_API_KEY"}
+}
+
+// validateAPIKeyEnvNames 检查不同监控项的 API Key 覆盖环境变量名是否冲突
+// 例如 provider=a-b/service=c 与 provider=a/service=b-c 都对应 MONITOR_A_B_C_API_KEY，
+// service=s/channel=x 与 service=s-x 都对应 MONITOR_<P>_S_X_API_KEY；同一 provider/service 的多个 channel 共用回退变量名不算冲突
+func (c *AppConfig) validateAPIKeyEnvNames() error {
+	owners := make(map[string]string)
+	for i := range c.Monitors {
+		m := &c.Monitors[i]
+		names := m.apiKeyEnvNames()
+		for j, name := range names {
+			owner := m.Provider + "/" + m.Service
+			if j == 0 && m.Channel != "" {
+				owner += "/" + m.Channel
+			}
+			if prev, ok := owners[name]; ok && prev != owner {
+				return fmt.Errorf("monitor[%d] (%s/%s/%s): API Key 环境变量 %s 与 %s 冲突，请调整 provider/service/channel 命名", i, m.Provider, m.Service, m.Channel, name, prev)
+			}
+			owners[name] = owner
+		}
+	}
+	return nil
+}
+
+// envSegment 将配置名称转换为环境变量名的一段（大写，- 替换为 _）
+func envSegment(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ResolveSecrets 解析监控项 api_key 中的密钥引用（在环境变量覆盖之后执行，覆盖值同样可以是引用）
+// file: 的相对路径相对于配置文件所在目录
+func (c *AppConfig) ResolveSecrets(configDir string) error {
+	r := newSecretResolver(configDir)
+	for i := range c.Monitors {
+		m := &c.Monitors[i]
+		value, err := r.resolve(m.APIKey)
+		if err != nil {
+			return fmt.Errorf("monitor[%d] (%s/%s/%s): api_key %w", i, m.Provider, m.Service, m.Channel, err)
+		}
+		m.APIKey = value
+	}
+	return nil
+}
+
+// secretResolver 密钥引用解析器（依赖可替换，便于测试）
+type secretResolver struct {
+	dir      string
+	getenv   func(string) string
+	readFile func(string) ([]byte, error)
+
+	key    []byte // 主密钥，首次遇到加密值时读取
+	keyErr error
+}
+
+// newSecretResolver 创建读取真实环境变量和文件的解析器
+func newSecretResolver(dir string) *secretResolver {
+	return &secretResolver{dir: dir, getenv: os.Getenv, readFile: os.ReadFile}
+}
+
+// resolve 解析单个值：file:/env:/enc:v1: 引用返回实际内容，其他值原样返回
+func (r *secretResolver) resolve(value string) (string, error) {
+	var resolved string
+	switch {
+	case strings.HasPrefix(value, secretFilePrefix):
+		path := strings.TrimPrefix(value, secretFilePrefix)
+		if path == "" {
+			return "", errors.New("file: 引用缺少文件路径")
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.dir, path)
+		}
+		data, err := r.readFile(path)
+		if err != nil {
+			return "", fmt.Errorf("读取密钥文件失败: %w", err)
+		}
+		// 去掉首尾空白（密钥文件通常以换行结尾）
+		resolved = strings.TrimSpace(string(data))
+		if resolved == "" {
+			return "", fmt.Errorf("密钥文件 %s 内容为空", path)
+		}
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		if name == "" {
+			return "", errors.New("env: 引用缺少环境变量名")
+		}
+		if resolved = r.getenv(name); resolved == "" {
+			return "", fmt.Errorf("引用的环境变量 %s 未设置", name)
+		}
+	case secret.IsEncrypted(value):
+		key, err := r.masterKey()
+		if err != nil {
+			return "", fmt.Errorf("使用了加密值，但无法读取主密钥: %w", err)
+		}
+		if resolved, err = secret.Decrypt(key, value); err != nil {
+			return "", err
+		}
+	default:
+		return value, nil
+	}
+	return resolved, nil
+}
+
+// masterKey 读取主密钥（结果缓存，同一次加载只解析一次）
+func (r *secretResolver) masterKey() ([]byte, error) {
+	if r.key == nil && r.keyErr == nil {
+		encoded := r.getenv(secret.MasterKeyEnv)
+		if encoded == "" {
+			r.keyErr = secret.ErrNoMasterKey
+		} else {
+			r.key, r.keyErr = secret.ParseKey(encoded)
+		}
+	}
+	return r.key, r.keyErr
+}
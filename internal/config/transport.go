@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TransportConfig 探测请求的出站传输配置（代理、TLS、DNS）
+// 可按 provider 在全局 transports 中配置，也可在监控项中通过 transport 整体覆盖
+type TransportConfig struct {
+	// 出站代理，支持 http://、https://、socks5://、socks5h://（如 "socks5://127.0.0.1:1080"）
+	Proxy string `yaml:"proxy" json:"proxy"`
+
+	// 额外信任的 CA 证书文件（PEM），与系统根证书一起使用
+	CAFile string `yaml:"ca_file" json:"ca_file"`
+
+	// mTLS 客户端证书与私钥文件（PEM），需同时配置
+	ClientCert string `yaml:"client_cert" json:"client_cert"`
+	ClientKey  string `yaml:"client_key" json:"client_key"`
+
+	// 跳过 TLS 证书校验（仅用于排查问题，会在日志中输出警告，且证书错误不再被识别）
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify" json:"insecure_skip_verify"`
+
+	// 强制使用 HTTP/1.1（禁用 HTTP/2）
+	ForceHTTP1 bool `yaml:"force_http1" json:"force_http1"`
+
+	// 自定义 DNS 服务器（如 "1.1.1.1" 或 "1.1.1.1:53"），未配置时使用系统解析
+	DNSServer string `yaml:"dns_server" json:"dns_server"`
+
+	// 主机名到 IP 的固定映射（类似 /etc/hosts），TLS SNI 和 Host 头仍使用原主机名
+	Hosts map[string]string `yaml:"hosts" json:"hosts"`
+}
+
+// Validate 验证传输配置
+func (t *TransportConfig) Validate() error {
+	if t.Proxy != "" {
+		u, err := url.Parse(t.Proxy)
+		if err != nil {
+			return fmt.Errorf("proxy 格式无效: %w", err)
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("proxy 只支持 http/https/socks5/socks5h 协议，收到: %q", u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("proxy 缺少主机地址: %s", t.Proxy)
+		}
+	}
+
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return fmt.Errorf("client_cert 和 client_key 必须同时配置")
+	}
+	for name, path := range map[string]string{"ca_file": t.CAFile, "client_cert": t.ClientCert, "client_key": t.ClientKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%s 无法读取: %w", name, err)
+		}
+	}
+
+	if t.DNSServer != "" {
+		host := t.DNSServer
+		if h, _, err := net.SplitHostPort(t.DNSServer); err == nil {
+			host = h
+		}
+		if net.ParseIP(host) == nil {
+			return fmt.Errorf("dns_server 必须是 IP 或 IP:端口，收到: %s", t.DNSServer)
+		}
+	}
+
+	for host, ip := range t.Hosts {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("hosts[%s] 不是有效的 IP: %s", host, ip)
+		}
+	}
+	return nil
+}
+
+// DNSServerAddr 自定义 DNS 服务器地址（补齐默认端口 53），未配置时返回空
+func (t *TransportConfig) DNSServerAddr() string {
+	if t.DNSServer == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(t.DNSServer); err == nil {
+		return t.DNSServer
+	}
+	return net.JoinHostPort(t.DNSServer, "53")
+}
+
+// Key 传输配置的唯一标识（配置相同的监控项共享 HTTP 客户端），nil 表示默认配置
+// 包含证书文件的修改时间和大小，同一路径下的证书轮换后会重建客户端
+func (t *TransportConfig) Key() string {
+	if t == nil {
+		return ""
+	}
+	data, _ := json.Marshal(t) // map 按键排序，结果稳定
+	key := string(data)
+	for _, path := range []string{t.CAFile, t.ClientCert, t.ClientKey} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			key += fmt.Sprintf("\x00%d/%d", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return key
+}
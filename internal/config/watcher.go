@@ -21,6 +21,7 @@ type Watcher struct {
 	debounceTime time.Duration
 	watchMu      sync.Mutex
 	watchedDirs  map[string]struct{}
+	reloadMu     sync.Mutex // 串行化文件变更与手动触发的重载
 }
 
 // NewWatcher 创建配置监听器
@@ -116,10 +117,19 @@ func (w *Watcher) Start(ctx context.Context) error {
 
 // reload 重新加载配置
 func (w *Watcher) reload() {
+	if err := w.Reload(); err != nil {
+		log.Printf("[Config] 重载失败: %v", err)
+	}
+}
+
+// Reload 立即重新加载配置文件并通知订阅者（管理 API 强制重载时调用），失败时保持旧配置并返回错误
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
 	newConfig, err := w.loader.LoadOrRollback(w.filename)
 	if err != nil {
-		log.Printf("[Config] 重载失败: %v", err)
-		return
+		return err
 	}
 
 	log.Printf("[Config] 热更新成功！已加载 %d 个监控任务", len(newConfig.Monitors))
@@ -128,6 +138,7 @@ func (w *Watcher) reload() {
 	if w.onReload != nil {
 		w.onReload(newConfig)
 	}
+	return nil
 }
 
 // Stop 停止监听
@@ -0,0 +1,166 @@
+// Package election 基于共享存储租约的主节点选举：多副本部署时只有主节点执行探测和数据清理
+package election
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// LeaseName 调度器主节点租约名称
+const LeaseName = "scheduler"
+
+// Elector 主节点选举：定期获取/续期共享存储中的租约，持有未过期租约的副本为主节点
+// 各副本时钟需大致同步（租约过期时间由发起续期的副本按本地时间计算）
+type Elector struct {
+	store storage.Storage
+	id    string
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu         sync.Mutex
+	leaseUntil time.Time // 本地记录的租约到期时间（从发起续期时起算，续期失败时到期自动卸任）
+	leader     bool      // 最近一次通知的角色
+	listeners  []func(leader bool)
+}
+
+// NewElector 创建选举器，id 为空时使用 DefaultID
+func NewElector(store storage.Storage, id string, ttl time.Duration) *Elector {
+	if id == "" {
+		id = DefaultID()
+	}
+	return &Elector{
+		store: store,
+		id:    id,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// DefaultID 默认副本标识：主机名-进程号-随机后缀（避免同一主机上的多个容器重名）
+func DefaultID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "monitor"
+	}
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// ID 副本标识
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// RenewInterval 租约续期间隔（租约有效期的 1/3），也是从节点角色可能发生变化的最短间隔
+func (e *Elector) RenewInterval() time.Duration {
+	return e.ttl / 3
+}
+
+// OnChange 注册角色变化回调（需在 Start 之前调用），回调在选举协程中同步执行
+func (e *Elector) OnChange(fn func(leader bool)) {
+	e.listeners = append(e.listeners, fn)
+}
+
+// Start 立即参与一次选举，之后每 1/3 租约有效期续期一次，直到 ctx 取消
+func (e *Elector) Start(ctx context.Context) {
+	if !e.Tick() {
+		log.Printf("[Election] %s 以从节点启动，仅提供 API 服务", e.id)
+	}
+	go e.loop(ctx)
+}
+
+// loop 续期循环
+func (e *Elector) loop(ctx context.Context) {
+	ticker := time.NewTicker(e.RenewInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Tick()
+		}
+	}
+}
+
+// Tick 尝试获取或续期一次租约，返回当前是否为主节点
+func (e *Elector) Tick() bool {
+	start := e.now()
+	acquired, err := e.store.AcquireLease(LeaseName, e.id, start, e.ttl)
+	if err != nil {
+		log.Printf("⚠️  [Election] %s 续期租约失败: %v", e.id, err)
+	}
+
+	e.mu.Lock()
+	if acquired {
+		e.leaseUntil = start.Add(e.ttl)
+	} else if err == nil {
+		e.leaseUntil = time.Time{} // 租约已被其他副本持有
+	}
+	e.mu.Unlock()
+
+	leader := e.IsLeader()
+	e.setLeader(leader)
+	return leader
+}
+
+// IsLeader 当前是否为主节点（本地租约未过期，存储不可用时到期自动卸任）
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.now().Before(e.leaseUntil)
+}
+
+// Leader 当前主节点的副本标识（租约不存在或已过期时返回空字符串）
+func (e *Elector) Leader() (string, error) {
+	lease, err := e.store.GetLease(LeaseName)
+	if err != nil || lease == nil || lease.ExpiresAt <= e.now().UnixMilli() {
+		return "", err
+	}
+	return lease.Holder, nil
+}
+
+// Release 主动释放租约（优雅退出时调用），其他副本在下一次续期时即可接管
+func (e *Elector) Release() {
+	e.mu.Lock()
+	held := !e.leaseUntil.IsZero()
+	e.leaseUntil = time.Time{}
+	e.mu.Unlock()
+
+	if held {
+		if err := e.store.ReleaseLease(LeaseName, e.id); err != nil {
+			log.Printf("⚠️  [Election] %s 释放租约失败: %v", e.id, err)
+		}
+	}
+	e.setLeader(false)
+}
+
+// setLeader 记录角色并在变化时通知回调
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	changed := e.leader != leader
+	e.leader = leader
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if leader {
+		log.Printf("[Election] %s 成为主节点", e.id)
+	} else {
+		log.Printf("[Election] %s 不再是主节点", e.id)
+	}
+	for _, fn := range e.listeners {
+		fn(leader)
+	}
+}
@@ -0,0 +1,127 @@
+package election
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// openShared 打开同一个数据库文件的 n 个独立连接，模拟共享存储的多个副本
+func openShared(t *testing.T, n int) []storage.Storage {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "monitor.db")
+	stores := make([]storage.Storage, n)
+	for i := range stores {
+		store, err := storage.NewSQLiteStorage(path)
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		if err := store.Init(); err != nil {
+			t.Fatalf("init sqlite: %v", err)
+		}
+		stores[i] = store
+	}
+	return stores
+}
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestSingleLeaderAndFailoverOnExpiry(t *testing.T) {
+	t.Parallel()
+
+	stores := openShared(t, 2)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	ttl := 15 * time.Second
+
+	a := NewElector(stores[0], "a", ttl)
+	b := NewElector(stores[1], "b", ttl)
+	a.now, b.now = clock.Now, clock.Now
+
+	var changes []bool
+	b.OnChange(func(leader bool) { changes = append(changes, leader) })
+
+	if !a.Tick() {
+		t.Fatalf("first replica should acquire the lease")
+	}
+	if b.Tick() {
+		t.Fatalf("second replica must not acquire a held lease")
+	}
+
+	// a 正常续期，b 始终无法接管
+	for i := 0; i < 5; i++ {
+		clock.now = clock.now.Add(ttl / 3)
+		if !a.Tick() || b.Tick() {
+			t.Fatalf("round %d: expected a to stay leader", i)
+		}
+	}
+
+	// a 失联（不再续期）：租约过期前 b 仍无法接管，过期后 a 本地卸任、b 接管
+	clock.now = clock.now.Add(ttl - time.Second)
+	if b.Tick() {
+		t.Fatalf("lease taken over before expiry")
+	}
+	if !a.IsLeader() {
+		t.Fatalf("a should still hold its lease")
+	}
+	clock.now = clock.now.Add(time.Second)
+	if a.IsLeader() {
+		t.Fatalf("a should step down once its lease expires")
+	}
+	if !b.Tick() {
+		t.Fatalf("b should take over after the lease expires")
+	}
+
+	// a 恢复后发现租约已被接管
+	if a.Tick() {
+		t.Fatalf("recovered replica must not reclaim a held lease")
+	}
+
+	if len(changes) != 1 || !changes[0] {
+		t.Fatalf("unexpected role changes for b: %v", changes)
+	}
+	lease, err := stores[0].GetLease(LeaseName)
+	if err != nil || lease == nil || lease.Holder != "b" {
+		t.Fatalf("unexpected lease: %+v, %v", lease, err)
+	}
+	if leader, err := a.Leader(); err != nil || leader != "b" {
+		t.Fatalf("expected a to report b as leader, got %q, %v", leader, err)
+	}
+
+	// b 同样失联后没有主节点
+	clock.now = clock.now.Add(ttl)
+	if leader, err := a.Leader(); err != nil || leader != "" {
+		t.Fatalf("expected no leader after the lease expires, got %q, %v", leader, err)
+	}
+}
+
+func TestReleaseHandsOverImmediately(t *testing.T) {
+	t.Parallel()
+
+	stores := openShared(t, 2)
+	a := NewElector(stores[0], "a", time.Minute)
+	b := NewElector(stores[1], "b", time.Minute)
+
+	var changes []bool
+	a.OnChange(func(leader bool) { changes = append(changes, leader) })
+
+	if !a.Tick() || b.Tick() {
+		t.Fatalf("expected a to lead")
+	}
+	a.Release()
+	if a.IsLeader() {
+		t.Fatalf("released replica still reports leader")
+	}
+	if !b.Tick() {
+		t.Fatalf("b should acquire a released lease without waiting for expiry")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Fatalf("unexpected role changes for a: %v", changes)
+	}
+}
@@ -0,0 +1,67 @@
+// Package flapping 状态震荡检测：统计最近若干次探测的状态变化频率，并按滞回规则给出稳定状态
+package flapping
+
+import "monitor/internal/config"
+
+// minSamples 判定震荡所需的最少有效探测次数（样本过少时频率没有意义）
+const minSamples = 5
+
+// Result 震荡检测结果
+type Result struct {
+	Score    float64 // 状态变化频率（0-1）
+	Flapping bool    // 是否处于震荡
+	Stable   int     // 按滞回规则得到的稳定状态（没有有效探测时为 -1）
+}
+
+// Counted 参与震荡检测的状态（绿/红/黄，灰色和维护中不参与）
+func Counted(status int) bool {
+	return status == 0 || status == 1 || status == 2
+}
+
+// Detect 根据按时间升序排列的探测状态计算震荡评分和稳定状态（不参与检测的状态会被忽略）
+func Detect(statuses []int, cfg config.FlappingConfig) Result {
+	counted := make([]int, 0, len(statuses))
+	for _, s := range statuses {
+		if Counted(s) {
+			counted = append(counted, s)
+		}
+	}
+
+	result := Result{Stable: Stabilize(counted, cfg.Hysteresis)}
+	if !cfg.Enabled() {
+		return result
+	}
+
+	window := counted[max(len(counted)-cfg.Window, 0):]
+	if len(window) < 2 {
+		return result
+	}
+	changes := 0
+	for i := 1; i < len(window); i++ {
+		if window[i] != window[i-1] {
+			changes++
+		}
+	}
+	result.Score = float64(changes) / float64(len(window)-1)
+	result.Flapping = len(window) >= minSamples && result.Score >= cfg.Threshold
+	return result
+}
+
+// Stabilize 滞回：稳定状态为最近一段连续出现至少 n 次的状态，没有这样的连续段时为最新状态
+// （n<=1 时即最新状态，空输入返回 -1）。只看最近的连续段，结果不受窗口最早一侧的探测影响
+func Stabilize(statuses []int, n int) int {
+	if len(statuses) == 0 {
+		return -1
+	}
+	run := 0
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if i < len(statuses)-1 && statuses[i] != statuses[i+1] {
+			run = 0
+		}
+		run++
+		if run >= n {
+			return statuses[i]
+		}
+	}
+	return statuses[len(statuses)-1]
+}
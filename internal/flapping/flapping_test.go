@@ -0,0 +1,82 @@
+package flapping
+
+import (
+	"testing"
+
+	"monitor/internal/config"
+)
+
+func TestDetectScoreAndHysteresis(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.FlappingConfig{Window: 6, Threshold: 0.5, Hysteresis: 3}
+
+	// 灰色（-1）和维护中（4）不参与检测
+	r := Detect([]int{1, 4, 0, 1, -1, 0, 1, 0}, cfg)
+	if !r.Flapping || r.Score != 1 {
+		t.Fatalf("expected flapping with score 1, got %+v", r)
+	}
+	if r.Stable != 0 {
+		t.Fatalf("expected the latest status without a stable run, got %d", r.Stable)
+	}
+
+	r = Detect([]int{1, 1, 0, 0, 0, 1, 1}, cfg)
+	if r.Flapping {
+		t.Fatalf("expected not flapping, got %+v", r)
+	}
+	if r.Stable != 0 {
+		t.Fatalf("expected stable status 0 after 3 consecutive reds, got %d", r.Stable)
+	}
+
+	// 样本不足时不判定为震荡
+	if r := Detect([]int{1, 0, 1}, cfg); r.Flapping {
+		t.Fatalf("expected not flapping with few samples, got %+v", r)
+	}
+
+	// 关闭震荡检测时仍计算稳定状态
+	cfg.Window = -1
+	if r := Detect([]int{1, 0, 0, 0}, cfg); r.Flapping || r.Score != 0 || r.Stable != 0 {
+		t.Fatalf("unexpected result with detection disabled: %+v", r)
+	}
+}
+
+func TestStabilize(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		statuses []int
+		n        int
+		want     int
+	}{
+		{nil, 3, -1},
+		{[]int{1, 0, 2}, 1, 2},
+		{[]int{1, 0, 0, 2, 0}, 2, 0},
+		{[]int{1, 0, 2, 0, 2}, 2, 2},
+		{[]int{0, 1, 1, 0, 1, 1}, 3, 1},
+		{[]int{0, 0, 1, 1, 0, 1, 1, 1}, 3, 1},
+	}
+	for _, tc := range cases {
+		if got := Stabilize(tc.statuses, tc.n); got != tc.want {
+			t.Errorf("Stabilize(%v, %d) = %d, want %d", tc.statuses, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestStabilizeIgnoresBlipAtWindowEdge(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.FlappingConfig{Window: 20, Threshold: 0.5, Hysteresis: 3}
+
+	// 大部分为绿色、偶有单次红色：不会因为窗口最早的一次是红色而判为红色
+	if r := Detect([]int{0, 1, 1, 0, 1, 1}, cfg); r.Stable != 1 {
+		t.Fatalf("expected green with single red blips, got %+v", r)
+	}
+
+	// 窗口滑动使最早一侧的红色移出时，稳定状态不变
+	statuses := []int{0, 1, 1, 1, 0, 1, 1}
+	for start := 0; start < 3; start++ {
+		if got := Stabilize(statuses[start:], 3); got != 1 {
+			t.Fatalf("Stabilize(%v, 3) = %d, want 1", statuses[start:], got)
+		}
+	}
+}
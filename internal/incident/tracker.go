@@ -0,0 +1,135 @@
+// Package incident 根据探测结果自动开启/关闭故障事件
+package incident
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+// openIncident 进行中的故障及其细分状态计数
+type openIncident struct {
+	incident *storage.Incident
+	counts   map[storage.SubStatus]int
+}
+
+// Tracker 故障事件跟踪器：红色探测开启故障，随后首个绿色/黄色或维护中的探测关闭故障
+type Tracker struct {
+	mu    sync.Mutex
+	store storage.Storage
+	open  map[string]*openIncident // key 为 provider/service/channel（探测节点上报的结果附加 @region）
+}
+
+// NewTracker 创建故障事件跟踪器，并恢复存储中仍在进行的故障
+func NewTracker(store storage.Storage) (*Tracker, error) {
+	t := &Tracker{
+		store: store,
+		open:  make(map[string]*openIncident),
+	}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload 从存储重新加载进行中的故障（多副本部署时成为主节点后调用，接续原主节点开启的故障）
+func (t *Tracker) Reload() error {
+	incidents, err := t.store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateOpen})
+	if err != nil {
+		return fmt.Errorf("加载进行中的故障事件失败: %w", err)
+	}
+
+	open := make(map[string]*openIncident, len(incidents))
+	for _, inc := range incidents {
+		key := incidentKey(inc.Provider, inc.Service, inc.Channel, inc.Region)
+		if _, dup := open[key]; dup {
+			continue // 只保留最新的一条（按开始时间倒序）
+		}
+		// 重启后细分状态计数丢失，以主要原因作为初始计数
+		open[key] = &openIncident{
+			incident: inc,
+			counts:   map[storage.SubStatus]int{inc.SubStatus: inc.ProbeCount},
+		}
+	}
+	if len(open) > 0 {
+		log.Printf("[Incident] 恢复 %d 个进行中的故障事件", len(open))
+	}
+
+	t.mu.Lock()
+	t.open = open
+	t.mu.Unlock()
+	return nil
+}
+
+// OnProbeResult 处理一次探测结果（实现 monitor.ResultObserver）
+func (t *Tracker) OnProbeResult(cfg *config.ServiceConfig, result *monitor.ProbeResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := incidentKey(cfg.Provider, cfg.Service, cfg.Channel, result.Region)
+	current, ok := t.open[key]
+
+	switch result.Status {
+	case 0:
+		if !ok {
+			current = &openIncident{
+				incident: &storage.Incident{
+					Provider:  cfg.Provider,
+					Service:   cfg.Service,
+					Channel:   cfg.Channel,
+					Region:    result.Region,
+					StartTime: result.Timestamp,
+				},
+				counts: make(map[storage.SubStatus]int),
+			}
+			log.Printf("[Incident] %s 故障开始 (%s)", key, result.SubStatus)
+		}
+		current.record(result)
+		if err := t.store.SaveIncident(current.incident); err != nil {
+			log.Printf("[Incident] 保存故障事件失败 %s: %v", key, err)
+			return
+		}
+		t.open[key] = current
+
+	case 1, 2, 4:
+		// 进入维护窗口同样结束故障，与 SLA 报表的故障统计口径一致
+		if !ok {
+			return
+		}
+		current.incident.EndTime = result.Timestamp
+		if err := t.store.SaveIncident(current.incident); err != nil {
+			log.Printf("[Incident] 关闭故障事件失败 %s: %v", key, err)
+			return
+		}
+		delete(t.open, key)
+		log.Printf("[Incident] %s 故障结束，持续 %ds", key, current.incident.Duration(result.Timestamp))
+	}
+}
+
+// record 累计一次红色探测
+func (o *openIncident) record(result *monitor.ProbeResult) {
+	inc := o.incident
+	inc.ProbeCount++
+	if result.Latency > inc.PeakLatency {
+		inc.PeakLatency = result.Latency
+	}
+
+	o.counts[result.SubStatus]++
+	// 出现次数最多的细分状态作为主要原因（并列时保持原有原因）
+	if inc.ProbeCount == 1 || o.counts[result.SubStatus] > o.counts[inc.SubStatus] {
+		inc.SubStatus = result.SubStatus
+	}
+}
+
+// incidentKey 监控项唯一标识（不同地域的故障分别跟踪）
+func incidentKey(provider, service, channel, region string) string {
+	key := provider + "/" + service + "/" + channel
+	if region != "" {
+		key += "@" + region
+	}
+	return key
+}
@@ -0,0 +1,215 @@
+package incident
+
+import (
+	"testing"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+func result(status int, sub storage.SubStatus, latency int, ts int64) *monitor.ProbeResult {
+	return &monitor.ProbeResult{Status: status, SubStatus: sub, Latency: latency, Timestamp: ts}
+}
+
+func TestTrackerOpensAndClosesIncident(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	tracker, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc", Channel: "vip"}
+
+	tracker.OnProbeResult(cfg, result(1, storage.SubStatusNone, 100, 1000))
+	tracker.OnProbeResult(cfg, result(0, storage.SubStatusNetworkError, 5000, 1060))
+	tracker.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 800, 1120))
+	tracker.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 900, 1180))
+
+	open, err := store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateOpen})
+	if err != nil {
+		t.Fatalf("get open incidents: %v", err)
+	}
+	if len(open) != 1 || open[0].ProbeCount != 3 || open[0].PeakLatency != 5000 || open[0].SubStatus != storage.SubStatusServerError {
+		t.Fatalf("unexpected open incident: %+v", open)
+	}
+
+	tracker.OnProbeResult(cfg, result(2, storage.SubStatusSlowLatency, 6000, 1240))
+
+	closed, err := store.GetIncidents(storage.IncidentFilter{Provider: "demo", State: storage.IncidentStateClosed})
+	if err != nil {
+		t.Fatalf("get closed incidents: %v", err)
+	}
+	if len(closed) != 1 || closed[0].StartTime != 1060 || closed[0].EndTime != 1240 || closed[0].Duration(0) != 180 {
+		t.Fatalf("unexpected closed incident: %+v", closed)
+	}
+}
+
+func TestTrackerResumesOpenIncident(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+
+	first, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	first.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 100, 1000))
+
+	// 模拟重启：新的跟踪器应接续进行中的故障，而不是新开一条
+	second, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	second.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 100, 1060))
+	second.OnProbeResult(cfg, result(1, storage.SubStatusNone, 100, 1120))
+
+	all, err := store.GetIncidents(storage.IncidentFilter{})
+	if err != nil {
+		t.Fatalf("get incidents: %v", err)
+	}
+	if len(all) != 1 || all[0].ProbeCount != 2 || all[0].EndTime != 1120 {
+		t.Fatalf("unexpected incidents after restart: %+v", all)
+	}
+}
+
+func TestTrackerReloadAfterTakeover(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+
+	// 从节点先启动，此时还没有进行中的故障
+	follower, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	leader, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	leader.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 100, 1000))
+
+	// 从节点成为主节点后重新加载，接续并关闭原主节点开启的故障
+	if err := follower.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	follower.OnProbeResult(cfg, result(1, storage.SubStatusNone, 100, 1060))
+
+	all, err := store.GetIncidents(storage.IncidentFilter{})
+	if err != nil {
+		t.Fatalf("get incidents: %v", err)
+	}
+	if len(all) != 1 || all[0].EndTime != 1060 {
+		t.Fatalf("unexpected incidents after takeover: %+v", all)
+	}
+}
+
+func TestGetIncidentsTimeRange(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	for _, inc := range []*storage.Incident{
+		{Provider: "a", Service: "cc", StartTime: 100, EndTime: 200},
+		{Provider: "a", Service: "cc", StartTime: 300, EndTime: 400},
+		{Provider: "b", Service: "cc", StartTime: 500},
+	} {
+		if err := store.SaveIncident(inc); err != nil {
+			t.Fatalf("save incident: %v", err)
+		}
+	}
+
+	got, err := store.GetIncidents(storage.IncidentFilter{Since: 250, Until: 1000})
+	if err != nil {
+		t.Fatalf("get incidents: %v", err)
+	}
+	if len(got) != 2 || got[0].StartTime != 500 || got[1].StartTime != 300 {
+		t.Fatalf("unexpected incidents in range: %+v", got)
+	}
+
+	got, err = store.GetIncidents(storage.IncidentFilter{Provider: "a", Limit: 1})
+	if err != nil {
+		t.Fatalf("get incidents: %v", err)
+	}
+	if len(got) != 1 || got[0].StartTime != 300 {
+		t.Fatalf("unexpected limited incidents: %+v", got)
+	}
+}
+
+func TestTrackerTracksRegionsSeparately(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	tracker, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+	remote := func(status int, ts int64) *monitor.ProbeResult {
+		r := result(status, storage.SubStatusNetworkError, 100, ts)
+		r.Region = "shanghai"
+		return r
+	}
+
+	// 只有探测节点所在地域故障，本地探测正常
+	tracker.OnProbeResult(cfg, remote(0, 1000))
+	tracker.OnProbeResult(cfg, result(1, storage.SubStatusNone, 100, 1010))
+	tracker.OnProbeResult(cfg, remote(0, 1060))
+
+	open, err := store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateOpen})
+	if err != nil {
+		t.Fatalf("get open incidents: %v", err)
+	}
+	if len(open) != 1 || open[0].Region != "shanghai" || open[0].ProbeCount != 2 {
+		t.Fatalf("unexpected open incidents: %+v", open)
+	}
+
+	// 重启后按地域接续
+	resumed, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	resumed.OnProbeResult(cfg, remote(1, 1120))
+	closed, err := store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateClosed})
+	if err != nil || len(closed) != 1 || closed[0].EndTime != 1120 {
+		t.Fatalf("expected the regional incident to close, got %+v (err=%v)", closed, err)
+	}
+}
+
+func TestTrackerClosesIncidentOnMaintenance(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	tracker, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("new tracker: %v", err)
+	}
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc", Channel: "vip"}
+
+	tracker.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 800, 1000))
+	tracker.OnProbeResult(cfg, result(4, storage.SubStatusNone, 0, 1060))
+	tracker.OnProbeResult(cfg, result(4, storage.SubStatusNone, 0, 1120))
+
+	open, err := store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateOpen})
+	if err != nil {
+		t.Fatalf("get open incidents: %v", err)
+	}
+	if len(open) != 0 {
+		t.Fatalf("maintenance should close the open incident, got %+v", open)
+	}
+	closed, err := store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateClosed})
+	if err != nil {
+		t.Fatalf("get closed incidents: %v", err)
+	}
+	if len(closed) != 1 || closed[0].EndTime != 1060 {
+		t.Fatalf("unexpected closed incident: %+v", closed)
+	}
+
+	// 维护结束后再次失败开启新的故障
+	tracker.OnProbeResult(cfg, result(0, storage.SubStatusServerError, 800, 1180))
+	if open, _ = store.GetIncidents(storage.IncidentFilter{State: storage.IncidentStateOpen}); len(open) != 1 || open[0].StartTime != 1180 {
+		t.Fatalf("expected a new incident after maintenance, got %+v", open)
+	}
+}
@@ -0,0 +1,118 @@
+// Package jsonpath 响应断言使用的 JSONPath 子集（配置校验与探测时共用同一解析器）
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Step JSONPath 的一段路径
+type Step struct {
+	key      string // 对象字段名
+	index    int    // 数组下标（支持负数，-1 表示最后一个）
+	isIndex  bool   // 是否为数组下标
+	wildcard bool   // 是否为通配（[*] 或 .*）
+}
+
+// Parse 解析 JSONPath 子集：$、.key、['key']、[n]、[-n]、[*]、.*
+func Parse(path string) ([]Step, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath 必须以 $ 开头: %s", path)
+	}
+
+	var steps []Step
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath 字段名不能为空: %s", path)
+			}
+			if name == "*" {
+				steps = append(steps, Step{wildcard: true})
+			} else {
+				steps = append(steps, Step{key: name})
+			}
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath 缺少 ]: %s", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				steps = append(steps, Step{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, Step{key: inner[1 : len(inner)-1]})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath 下标无效 [%s]: %s", inner, path)
+				}
+				steps = append(steps, Step{index: idx, isIndex: true})
+			}
+
+		default:
+			return nil, fmt.Errorf("JSONPath 语法错误（位置 %d）: %s", len(path)-len(rest), path)
+		}
+	}
+
+	return steps, nil
+}
+
+// Eval 在已解析的 JSON 文档上执行 JSONPath，返回所有匹配的值
+func Eval(doc any, path string) ([]any, error) {
+	steps, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []any{doc}
+	for _, step := range steps {
+		var next []any
+		for _, node := range current {
+			switch v := node.(type) {
+			case map[string]any:
+				if step.wildcard {
+					for _, child := range v {
+						next = append(next, child)
+					}
+				} else if !step.isIndex {
+					if child, ok := v[step.key]; ok {
+						next = append(next, child)
+					}
+				}
+			case []any:
+				if step.wildcard {
+					next = append(next, v...)
+				} else if step.isIndex {
+					idx := step.index
+					if idx < 0 {
+						idx += len(v)
+					}
+					if idx >= 0 && idx < len(v) {
+						next = append(next, v[idx])
+					}
+				}
+			}
+		}
+		current = next
+		if len(current) == 0 {
+			break
+		}
+	}
+
+	return current, nil
+}
@@ -0,0 +1,37 @@
+package jsonpath
+
+import "testing"
+
+func TestEvalWildcardAndNegativeIndex(t *testing.T) {
+	t.Parallel()
+
+	doc := map[string]any{
+		"content": []any{
+			map[string]any{"type": "thinking"},
+			map[string]any{"type": "text", "text": "hi"},
+		},
+	}
+
+	values, err := Eval(doc, "$.content[*].type")
+	if err != nil || len(values) != 2 {
+		t.Fatalf("expected 2 wildcard matches, got %v err=%v", values, err)
+	}
+
+	values, err = Eval(doc, "$['content'][-1].text")
+	if err != nil || len(values) != 1 || values[0] != "hi" {
+		t.Fatalf("expected last element text, got %v err=%v", values, err)
+	}
+}
+
+func TestParseRejectsInvalidPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"choices[0]", "$.", "$.choices[0", "$.choices[x]", "$..content", "$foo"} {
+		if _, err := Parse(path); err == nil {
+			t.Errorf("expected %q to be rejected", path)
+		}
+	}
+	if steps, err := Parse("$.choices[0]['message'].*"); err != nil || len(steps) != 4 {
+		t.Fatalf("expected 4 steps, got %+v err=%v", steps, err)
+	}
+}
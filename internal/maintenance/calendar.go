@@ -0,0 +1,223 @@
+// Package maintenance 计划维护窗口：合并配置文件与 API 创建的窗口，供调度器和 API 判断监控项是否处于维护中
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// Occurrence 维护窗口的一次发生
+type Occurrence struct {
+	Window *config.MaintenanceWindow
+	config.MaintenanceInterval
+}
+
+// Calendar 维护日历（配置文件中的窗口随热更新替换，API 创建的窗口保存在存储中）
+type Calendar struct {
+	store storage.Storage // 探测节点模式下为 nil，只使用配置文件中的窗口
+	now   func() time.Time
+
+	mu         sync.RWMutex
+	configured []config.MaintenanceWindow
+	dynamic    []config.MaintenanceWindow
+}
+
+// NewCalendar 创建维护日历
+func NewCalendar(store storage.Storage) *Calendar {
+	return &Calendar{store: store, now: time.Now}
+}
+
+// UpdateConfig 替换配置文件中的维护窗口（热更新时调用）
+func (c *Calendar) UpdateConfig(cfg *config.AppConfig) {
+	windows := make([]config.MaintenanceWindow, len(cfg.Maintenance.Windows))
+	copy(windows, cfg.Maintenance.Windows)
+
+	c.mu.Lock()
+	c.configured = windows
+	c.mu.Unlock()
+}
+
+// Reload 从存储重新加载 API 创建的维护窗口（无法解析的窗口记录日志后跳过）
+func (c *Calendar) Reload() error {
+	if c.store == nil {
+		return nil
+	}
+	records, err := c.store.GetMaintenanceWindows()
+	if err != nil {
+		return err
+	}
+
+	windows := make([]config.MaintenanceWindow, 0, len(records))
+	for _, r := range records {
+		w, err := FromRecord(r)
+		if err != nil {
+			log.Printf("[Maintenance] 跳过无效的维护窗口 #%d: %v", r.ID, err)
+			continue
+		}
+		windows = append(windows, *w)
+	}
+
+	c.mu.Lock()
+	c.dynamic = windows
+	c.mu.Unlock()
+	return nil
+}
+
+// Start 定期重新加载 API 创建的维护窗口（多副本部署时同步其他副本上的修改）
+func (c *Calendar) Start(ctx context.Context, interval time.Duration) {
+	if c.store == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Reload(); err != nil {
+					log.Printf("[Maintenance] 加载维护窗口失败: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Windows 全部维护窗口（配置文件中的在前）
+func (c *Calendar) Windows() []config.MaintenanceWindow {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	windows := make([]config.MaintenanceWindow, 0, len(c.configured)+len(c.dynamic))
+	windows = append(windows, c.configured...)
+	return append(windows, c.dynamic...)
+}
+
+// Active 返回监控项在 t 时刻所处的维护窗口（不在维护中时返回 nil，多个窗口重叠时跳过探测的优先）
+func (c *Calendar) Active(provider, service, channel string, t time.Time) *config.MaintenanceWindow {
+	var active *config.MaintenanceWindow
+	for _, w := range c.Windows() {
+		if !w.Matches(provider, service, channel) {
+			continue
+		}
+		if _, ok := w.ActiveAt(t); !ok {
+			continue
+		}
+		if active == nil || w.Mode == config.MaintenanceModeSkip {
+			active = &w
+		}
+	}
+	return active
+}
+
+// Occurrences 返回监控项在 [from, to) 内的维护时段（按开始时间升序）
+func (c *Calendar) Occurrences(provider, service, channel string, from, to time.Time) []Occurrence {
+	var result []Occurrence
+	for _, w := range c.Windows() {
+		if !w.Matches(provider, service, channel) {
+			continue
+		}
+		for _, iv := range w.Occurrences(from, to) {
+			result = append(result, Occurrence{Window: &w, MaintenanceInterval: iv})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// Add 校验并保存通过 API 创建的维护窗口（回填 ID）
+func (c *Calendar) Add(w *config.MaintenanceWindow) error {
+	if c.store == nil {
+		return fmt.Errorf("未配置存储，无法保存维护窗口")
+	}
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if err := w.Normalize(); err != nil {
+		return err
+	}
+
+	record := ToRecord(w)
+	record.CreatedAt = c.now().Unix()
+	if err := c.store.SaveMaintenanceWindow(record); err != nil {
+		return err
+	}
+	w.ID = record.ID
+
+	c.mu.Lock()
+	c.dynamic = append(c.dynamic, *w)
+	c.mu.Unlock()
+	return nil
+}
+
+// Delete 删除通过 API 创建的维护窗口，返回是否存在
+func (c *Calendar) Delete(id int64) (bool, error) {
+	if c.store == nil {
+		return false, nil
+	}
+	found, err := c.store.DeleteMaintenanceWindow(id)
+	if err != nil || !found {
+		return found, err
+	}
+
+	c.mu.Lock()
+	for i := range c.dynamic {
+		if c.dynamic[i].ID == id {
+			c.dynamic = append(c.dynamic[:i:i], c.dynamic[i+1:]...)
+			break
+		}
+	}
+	c.mu.Unlock()
+	return true, nil
+}
+
+// FromRecord 将存储中的维护窗口解析为配置结构
+func FromRecord(r *storage.MaintenanceWindow) (*config.MaintenanceWindow, error) {
+	w := &config.MaintenanceWindow{
+		ID:       r.ID,
+		Provider: r.Provider,
+		Service:  r.Service,
+		Channel:  r.Channel,
+		Start:    r.Start,
+		End:      r.End,
+		Schedule: r.Schedule,
+		Duration: r.Duration,
+		Timezone: r.Timezone,
+		Mode:     r.Mode,
+		Reason:   r.Reason,
+	}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	if err := w.Normalize(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// ToRecord 将配置结构转换为存储记录
+func ToRecord(w *config.MaintenanceWindow) *storage.MaintenanceWindow {
+	return &storage.MaintenanceWindow{
+		ID:       w.ID,
+		Provider: w.Provider,
+		Service:  w.Service,
+		Channel:  w.Channel,
+		Start:    w.Start,
+		End:      w.End,
+		Schedule: w.Schedule,
+		Duration: w.Duration,
+		Timezone: w.Timezone,
+		Mode:     w.Mode,
+		Reason:   w.Reason,
+	}
+}
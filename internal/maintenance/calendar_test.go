@@ -0,0 +1,155 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// window 创建并规范化维护窗口
+func window(t *testing.T, w config.MaintenanceWindow) config.MaintenanceWindow {
+	t.Helper()
+
+	if err := w.Validate(); err != nil {
+		t.Fatalf("validate window: %v", err)
+	}
+	if err := w.Normalize(); err != nil {
+		t.Fatalf("normalize window: %v", err)
+	}
+	return w
+}
+
+// configuredCalendar 创建包含指定配置文件窗口的维护日历
+func configuredCalendar(store storage.Storage, windows ...config.MaintenanceWindow) *Calendar {
+	c := NewCalendar(store)
+	c.UpdateConfig(&config.AppConfig{Maintenance: config.MaintenanceConfig{Windows: windows}})
+	return c
+}
+
+func TestCalendarActivePrefersSkip(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 10, 3, 30, 0, 0, time.UTC)
+	record := window(t, config.MaintenanceWindow{Provider: "demo", Reason: "record",
+		Start: "2026-03-10T03:00:00Z", End: "2026-03-10T05:00:00Z"})
+	skip := window(t, config.MaintenanceWindow{Provider: "demo", Service: "cc", Mode: config.MaintenanceModeSkip, Reason: "skip",
+		Schedule: "0 3 * * *", Duration: "1h", Timezone: "UTC"})
+
+	// 重叠时无论声明顺序如何都优先跳过探测的窗口
+	for _, c := range []*Calendar{configuredCalendar(nil, record, skip), configuredCalendar(nil, skip, record)} {
+		if w := c.Active("demo", "cc", "", at); w == nil || w.Reason != "skip" {
+			t.Fatalf("expected skip window to win, got %+v", w)
+		}
+		if w := c.Active("demo", "cx", "", at); w == nil || w.Reason != "record" {
+			t.Fatalf("expected record window for other service, got %+v", w)
+		}
+		if w := c.Active("demo", "cc", "", at.Add(time.Hour)); w == nil || w.Reason != "record" {
+			t.Fatalf("expected record window after skip ends, got %+v", w)
+		}
+		if w := c.Active("demo", "cc", "", at.Add(2*time.Hour)); w != nil {
+			t.Fatalf("expected no active window, got %+v", w)
+		}
+		if w := c.Active("other", "cc", "", at); w != nil {
+			t.Fatalf("expected no window for other provider, got %+v", w)
+		}
+	}
+}
+
+func TestCalendarOccurrencesMergesWindows(t *testing.T) {
+	t.Parallel()
+
+	c := configuredCalendar(storage.NewTestSQLite(t),
+		window(t, config.MaintenanceWindow{Provider: "demo", Reason: "daily", Schedule: "0 3 * * *", Duration: "1h", Timezone: "UTC"}),
+		window(t, config.MaintenanceWindow{Provider: "demo", Reason: "upgrade", Start: "2026-03-10T10:00:00Z", End: "2026-03-10T11:00:00Z"}),
+		window(t, config.MaintenanceWindow{Provider: "other", Reason: "other", Start: "2026-03-10T00:00:00Z", End: "2026-03-12T00:00:00Z"}),
+	)
+	if err := c.Add(&config.MaintenanceWindow{Provider: "demo", Service: "cc", Reason: "api",
+		Start: "2026-03-11T01:00:00Z", End: "2026-03-11T02:00:00Z"}); err != nil {
+		t.Fatalf("add window: %v", err)
+	}
+
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	got := c.Occurrences("demo", "cc", "", from, from.Add(48*time.Hour))
+	want := []struct {
+		reason string
+		hour   int
+	}{{"daily", 3}, {"upgrade", 10}, {"api", 24 + 1}, {"daily", 24 + 3}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d occurrences, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Window.Reason != w.reason || !got[i].Start.Equal(from.Add(time.Duration(w.hour)*time.Hour)) {
+			t.Fatalf("occurrence %d: expected %s at +%dh, got %s at %s", i, w.reason, w.hour, got[i].Window.Reason, got[i].Start)
+		}
+	}
+}
+
+func TestCalendarAddDelete(t *testing.T) {
+	t.Parallel()
+
+	if err := NewCalendar(nil).Add(&config.MaintenanceWindow{Provider: "demo", Schedule: "0 3 * * *", Duration: "1h"}); err == nil {
+		t.Fatal("expected error without storage")
+	}
+
+	store := storage.NewTestSQLite(t)
+	c := configuredCalendar(store, window(t, config.MaintenanceWindow{Provider: "demo", Reason: "config", Schedule: "0 3 * * *", Duration: "1h"}))
+
+	if err := c.Add(&config.MaintenanceWindow{Provider: "demo", Start: "2026-03-10T03:00:00Z"}); err == nil {
+		t.Fatal("expected error for window without end")
+	}
+	if n := len(c.Windows()); n != 1 {
+		t.Fatalf("invalid window should not be added, got %d windows", n)
+	}
+
+	w := &config.MaintenanceWindow{Provider: "demo", Reason: "api", Start: "2026-03-10T03:00:00Z", End: "2026-03-10T04:00:00Z"}
+	if err := c.Add(w); err != nil {
+		t.Fatalf("add window: %v", err)
+	}
+	if w.ID == 0 || w.Mode != config.MaintenanceModeRecord {
+		t.Fatalf("expected saved and normalized window, got %+v", w)
+	}
+	windows := c.Windows()
+	if len(windows) != 2 || windows[0].Reason != "config" || windows[1].ID != w.ID {
+		t.Fatalf("expected configured window first and the new window after it, got %+v", windows)
+	}
+
+	// 不存在的窗口不报错，返回 false
+	if found, err := c.Delete(w.ID + 1); err != nil || found {
+		t.Fatalf("expected unknown window to be reported missing, got found=%v err=%v", found, err)
+	}
+	if found, err := c.Delete(w.ID); err != nil || !found {
+		t.Fatalf("delete window: found=%v err=%v", found, err)
+	}
+	if windows := c.Windows(); len(windows) != 1 || windows[0].Reason != "config" {
+		t.Fatalf("expected only the configured window after delete, got %+v", windows)
+	}
+	if records, err := store.GetMaintenanceWindows(); err != nil || len(records) != 0 {
+		t.Fatalf("expected window to be removed from storage, got %d (err=%v)", len(records), err)
+	}
+}
+
+func TestCalendarReloadSkipsInvalidWindows(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	for _, r := range []*storage.MaintenanceWindow{
+		{Provider: "demo", Reason: "valid", Start: "2026-03-10T03:00:00Z", End: "2026-03-10T04:00:00Z"},
+		{Provider: "demo", Reason: "bad mode", Start: "2026-03-10T03:00:00Z", End: "2026-03-10T04:00:00Z", Mode: "pause"},
+		{Provider: "demo", Reason: "bad cron", Schedule: "not a cron", Duration: "1h"},
+	} {
+		if err := store.SaveMaintenanceWindow(r); err != nil {
+			t.Fatalf("save window: %v", err)
+		}
+	}
+
+	c := NewCalendar(store)
+	if err := c.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	windows := c.Windows()
+	if len(windows) != 1 || windows[0].Reason != "valid" || windows[0].ID == 0 {
+		t.Fatalf("expected only the valid window, got %+v", windows)
+	}
+}
@@ -0,0 +1,366 @@
+// Package metrics 以 Prometheus 文本格式导出探测结果与调度器运行指标
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+)
+
+// 指标名前缀
+const namespace = "relay_pulse"
+
+// latencyBuckets 探测延迟直方图桶（秒）
+var latencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// roundBuckets 调度轮次耗时直方图桶（秒）
+var roundBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// seriesKey 监控项标签
+type seriesKey struct {
+	provider string
+	service  string
+	channel  string
+	category string
+	region   string // 探测节点上报结果的地域（本地探测为空，不输出 region 标签）
+}
+
+// histogram 累积直方图
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// series 单个监控项的指标
+type series struct {
+	status        int
+	latency       float64 // 秒
+	lastTimestamp int64
+	certNotAfter  int64 // 最近观察到的证书过期时间（Unix 秒），0 表示未知
+	latencyHist   *histogram
+	probes        map[probeKey]uint64
+}
+
+// probeKey 探测计数的状态标签
+type probeKey struct {
+	status    string
+	subStatus string
+}
+
+// Collector 指标收集器（实现 monitor.ResultObserver 与 scheduler.Metrics）
+type Collector struct {
+	mu     sync.Mutex
+	series map[seriesKey]*series
+
+	rounds       *histogram
+	lastRound    float64
+	skipped      map[seriesKey]uint64 // 因上一轮未完成而跳过的次数（不含 category）
+	startTime    time.Time
+	monitorCount int
+
+	// 主节点选举状态（开启选举后才输出）
+	election bool
+	leader   bool
+}
+
+// NewCollector 创建指标收集器
+func NewCollector() *Collector {
+	return &Collector{
+		series:    make(map[seriesKey]*series),
+		rounds:    newHistogram(roundBuckets),
+		skipped:   make(map[seriesKey]uint64),
+		startTime: time.Now(),
+	}
+}
+
+// OnProbeResult 记录一次探测结果（实现 monitor.ResultObserver）
+func (c *Collector) OnProbeResult(cfg *config.ServiceConfig, result *monitor.ProbeResult) {
+	key := seriesKey{cfg.Provider, cfg.Service, cfg.Channel, cfg.Category, result.Region}
+	latency := float64(result.Latency) / 1000
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &series{latencyHist: newHistogram(latencyBuckets), probes: make(map[probeKey]uint64)}
+		c.series[key] = s
+	}
+	s.status = result.Status
+	s.latency = latency
+	s.lastTimestamp = result.Timestamp
+	s.latencyHist.observe(latency)
+	if result.Certificate != nil {
+		s.certNotAfter = result.Certificate.NotAfter.Unix()
+	}
+
+	sub := string(result.SubStatus)
+	if sub == "" {
+		sub = "none"
+	}
+	s.probes[probeKey{statusLabel(result.Status), sub}]++
+}
+
+// ObserveRound 记录一轮巡检的耗时
+func (c *Collector) ObserveRound(probes int, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastRound = duration.Seconds()
+	c.rounds.observe(duration.Seconds())
+}
+
+// IncSkipped 记录一次因上一轮检查尚未完成而跳过的巡检
+func (c *Collector) IncSkipped(cfg *config.ServiceConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.skipped[seriesKey{cfg.Provider, cfg.Service, cfg.Channel, "", ""}]++
+}
+
+// SetLeader 记录当前副本是否为主节点（开启选举时由角色变化回调调用）
+func (c *Collector) SetLeader(leader bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.election = true
+	c.leader = leader
+}
+
+// UpdateConfig 更新配置（热更新时调用），移除已删除监控项的指标
+func (c *Collector) UpdateConfig(cfg *config.AppConfig) {
+	active := make(map[seriesKey]bool, len(cfg.Monitors))
+	activeSkipped := make(map[seriesKey]bool, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		active[seriesKey{m.Provider, m.Service, m.Channel, m.Category, ""}] = true
+		activeSkipped[seriesKey{m.Provider, m.Service, m.Channel, "", ""}] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.monitorCount = len(cfg.Monitors)
+	for key := range c.series {
+		monitorKey := key
+		monitorKey.region = ""
+		if !active[monitorKey] {
+			delete(c.series, key)
+		}
+	}
+	for key := range c.skipped {
+		if !activeSkipped[key] {
+			delete(c.skipped, key)
+		}
+	}
+}
+
+// Handler 返回 /metrics 的 HTTP 处理器
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := c.Write(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Write 以 Prometheus 文本格式输出所有指标
+func (c *Collector) Write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+
+	keys := make([]seriesKey, 0, len(c.series))
+	for k := range c.series {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	writeHeader(&b, "monitor_status", "gauge", "最近一次探测状态（1=绿, 2=黄, 0=红, 4=维护中）")
+	for _, k := range keys {
+		writeSample(&b, "monitor_status", monitorLabels(k), float64(c.series[k].status))
+	}
+
+	writeHeader(&b, "probe_latency_seconds", "gauge", "最近一次探测延迟（秒）")
+	for _, k := range keys {
+		writeSample(&b, "probe_latency_seconds", monitorLabels(k), c.series[k].latency)
+	}
+
+	writeHeader(&b, "probe_last_timestamp_seconds", "gauge", "最近一次探测时间（Unix 秒）")
+	for _, k := range keys {
+		writeSample(&b, "probe_last_timestamp_seconds", monitorLabels(k), float64(c.series[k].lastTimestamp))
+	}
+
+	writeHeader(&b, "cert_expiry_timestamp_seconds", "gauge", "TLS 证书链最早过期时间（Unix 秒）")
+	for _, k := range keys {
+		if notAfter := c.series[k].certNotAfter; notAfter > 0 {
+			writeSample(&b, "cert_expiry_timestamp_seconds", monitorLabels(k), float64(notAfter))
+		}
+	}
+
+	writeHeader(&b, "probe_duration_seconds", "histogram", "探测延迟分布（秒）")
+	for _, k := range keys {
+		writeHistogram(&b, "probe_duration_seconds", monitorLabels(k), c.series[k].latencyHist)
+	}
+
+	writeHeader(&b, "probes_total", "counter", "探测次数（按状态与细分状态）")
+	for _, k := range keys {
+		s := c.series[k]
+		probeKeys := make([]probeKey, 0, len(s.probes))
+		for pk := range s.probes {
+			probeKeys = append(probeKeys, pk)
+		}
+		sort.Slice(probeKeys, func(i, j int) bool {
+			if probeKeys[i].status != probeKeys[j].status {
+				return probeKeys[i].status < probeKeys[j].status
+			}
+			return probeKeys[i].subStatus < probeKeys[j].subStatus
+		})
+		for _, pk := range probeKeys {
+			labels := append(monitorLabels(k), "status", pk.status, "sub_status", pk.subStatus)
+			writeSample(&b, "probes_total", labels, float64(s.probes[pk]))
+		}
+	}
+
+	writeHeader(&b, "scheduler_round_duration_seconds", "histogram", "调度器每轮巡检耗时（秒）")
+	writeHistogram(&b, "scheduler_round_duration_seconds", nil, c.rounds)
+
+	writeHeader(&b, "scheduler_last_round_duration_seconds", "gauge", "调度器最近一轮巡检耗时（秒）")
+	writeSample(&b, "scheduler_last_round_duration_seconds", nil, c.lastRound)
+
+	skippedKeys := make([]seriesKey, 0, len(c.skipped))
+	for k := range c.skipped {
+		skippedKeys = append(skippedKeys, k)
+	}
+	sortKeys(skippedKeys)
+
+	writeHeader(&b, "scheduler_skipped_total", "counter", "因上一轮检查尚未完成而跳过的巡检次数")
+	for _, k := range skippedKeys {
+		writeSample(&b, "scheduler_skipped_total", []string{"provider", k.provider, "service", k.service, "channel", k.channel}, float64(c.skipped[k]))
+	}
+
+	if c.election {
+		leader := 0.0
+		if c.leader {
+			leader = 1
+		}
+		writeHeader(&b, "scheduler_leader", "gauge", "当前副本是否为执行探测的主节点（1=是, 0=否）")
+		writeSample(&b, "scheduler_leader", nil, leader)
+	}
+
+	writeHeader(&b, "monitors", "gauge", "当前配置的监控项数量")
+	writeSample(&b, "monitors", nil, float64(c.monitorCount))
+
+	writeHeader(&b, "process_start_time_seconds", "gauge", "进程启动时间（Unix 秒）")
+	writeSample(&b, "process_start_time_seconds", nil, float64(c.startTime.Unix()))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// statusLabel 状态码对应的标签值
+func statusLabel(status int) string {
+	switch status {
+	case 1:
+		return "green"
+	case 2:
+		return "yellow"
+	case 0:
+		return "red"
+	case 4:
+		return "maintenance"
+	default:
+		return strconv.Itoa(status)
+	}
+}
+
+func sortKeys(keys []seriesKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.provider != b.provider {
+			return a.provider < b.provider
+		}
+		if a.service != b.service {
+			return a.service < b.service
+		}
+		if a.channel != b.channel {
+			return a.channel < b.channel
+		}
+		if a.category != b.category {
+			return a.category < b.category
+		}
+		return a.region < b.region
+	})
+}
+
+func monitorLabels(k seriesKey) []string {
+	labels := []string{"provider", k.provider, "service", k.service, "channel", k.channel, "category", k.category}
+	if k.region != "" {
+		labels = append(labels, "region", k.region)
+	}
+	return labels
+}
+
+func writeHeader(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s_%s %s\n", namespace, name, help)
+	fmt.Fprintf(b, "# TYPE %s_%s %s\n", namespace, name, typ)
+}
+
+// writeSample 输出一行样本，labels 为 name/value 交替排列
+func writeSample(b *strings.Builder, name string, labels []string, value float64) {
+	b.WriteString(namespace + "_" + name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i] + `="` + escapeLabel(labels[i+1]) + `"`)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+}
+
+func writeHistogram(b *strings.Builder, name string, labels []string, h *histogram) {
+	for i, bound := range h.buckets {
+		le := append(append([]string{}, labels...), "le", strconv.FormatFloat(bound, 'g', -1, 64))
+		writeSample(b, name+"_bucket", le, float64(h.counts[i]))
+	}
+	writeSample(b, name+"_bucket", append(append([]string{}, labels...), "le", "+Inf"), float64(h.count))
+	writeSample(b, name+"_sum", labels, h.sum)
+	writeSample(b, name+"_count", labels, float64(h.count))
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
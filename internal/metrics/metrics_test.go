@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+func TestCollectorExposition(t *testing.T) {
+	t.Parallel()
+
+	c := NewCollector()
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc", Channel: "vip", Category: "commercial"}
+	c.UpdateConfig(&config.AppConfig{Monitors: []config.ServiceConfig{*cfg}})
+
+	c.OnProbeResult(cfg, &monitor.ProbeResult{Status: 1, Latency: 300, Timestamp: 1700000000,
+		Certificate: &monitor.CertInfo{NotAfter: time.Unix(1800000000, 0)}})
+	c.OnProbeResult(cfg, &monitor.ProbeResult{Status: 0, SubStatus: storage.SubStatusServerError, Latency: 1500, Timestamp: 1700000060})
+	c.OnProbeResult(cfg, &monitor.ProbeResult{Status: 1, Latency: 200, Timestamp: 1700000030, Region: "shanghai"})
+	c.ObserveRound(1, 2*time.Second)
+	c.IncSkipped(cfg)
+
+	var b strings.Builder
+	if err := c.Write(&b); err != nil {
+		t.Fatalf("write metrics: %v", err)
+	}
+	out := b.String()
+
+	labels := `provider="demo",service="cc",channel="vip",category="commercial"`
+	for _, want := range []string{
+		"relay_pulse_monitor_status{" + labels + "} 0",
+		"relay_pulse_probe_latency_seconds{" + labels + "} 1.5",
+		"relay_pulse_probe_duration_seconds_bucket{" + labels + `,le="0.5"} 1`,
+		"relay_pulse_probe_duration_seconds_bucket{" + labels + `,le="+Inf"} 2`,
+		"relay_pulse_probe_duration_seconds_count{" + labels + "} 2",
+		"relay_pulse_probes_total{" + labels + `,status="green",sub_status="none"} 1`,
+		"relay_pulse_cert_expiry_timestamp_seconds{" + labels + "} 1.8e+09",
+		"relay_pulse_probes_total{" + labels + `,status="red",sub_status="server_error"} 1`,
+		`relay_pulse_scheduler_round_duration_seconds_bucket{le="2.5"} 1`,
+		"relay_pulse_scheduler_last_round_duration_seconds 2",
+		`relay_pulse_scheduler_skipped_total{provider="demo",service="cc",channel="vip"} 1`,
+		"relay_pulse_monitors 1",
+		"relay_pulse_monitor_status{" + labels + `,region="shanghai"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("metrics output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCollectorPrunesRemovedMonitors(t *testing.T) {
+	t.Parallel()
+
+	c := NewCollector()
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+	c.OnProbeResult(cfg, &monitor.ProbeResult{Status: 1, Latency: 100})
+	c.IncSkipped(cfg)
+	c.UpdateConfig(&config.AppConfig{})
+
+	var b strings.Builder
+	_ = c.Write(&b)
+	if strings.Contains(b.String(), `provider="demo"`) {
+		t.Fatalf("expected removed monitor series to be pruned:\n%s", b.String())
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	t.Parallel()
+
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("unexpected escape result: %s", got)
+	}
+}
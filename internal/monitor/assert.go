@@ -0,0 +1,267 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"monitor/internal/config"
+	"monitor/internal/jsonpath"
+	"monitor/internal/storage"
+)
+
+// responseSnapshot 断言所需的响应信息
+type responseSnapshot struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// needsBody 判断断言列表是否需要读取响应体
+func needsBody(assertions []config.AssertionConfig) bool {
+	for _, a := range assertions {
+		if a.Type == config.AssertionJSON || a.Type == config.AssertionBody {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateAssertions 在基础状态上按顺序执行响应断言
+// 对所有 2xx 响应（绿色和慢速黄色）进行校验，红色（已失败）和 429 黄色（非正常响应）不做校验；
+// 例外是 status_code 白名单显式允许的非 2xx/3xx 状态码（如 404），此时按绿色继续校验其余断言
+// 返回第一个失败断言对应的状态，以及失败原因（全部通过时为 nil）
+func evaluateAssertions(baseStatus int, baseSubStatus storage.SubStatus, resp *responseSnapshot, assertions []config.AssertionConfig) (int, storage.SubStatus, error) {
+	if len(assertions) == 0 {
+		return baseStatus, baseSubStatus, nil
+	}
+
+	// 状态码判定的失败可由白名单覆盖（流式错误等与状态码无关的失败不受影响）
+	if (resp.StatusCode < 200 || resp.StatusCode >= 400) && allowsStatusCode(assertions, resp.StatusCode) {
+		baseStatus, baseSubStatus = 1, storage.SubStatusNone
+	}
+
+	// 红色已是最差状态，不需要校验
+	if baseStatus == 0 {
+		return baseStatus, baseSubStatus, nil
+	}
+
+	// 429 限流：响应体是错误信息，不做内容校验
+	if baseStatus == 2 && baseSubStatus == storage.SubStatusRateLimit {
+		return baseStatus, baseSubStatus, nil
+	}
+
+	// JSON 文档按需解析一次
+	var doc any
+	var docErr error
+	docParsed := false
+
+	for i, a := range assertions {
+		var err error
+		switch a.Type {
+		case config.AssertionStatusCode:
+			if !slices.Contains(a.Codes, resp.StatusCode) {
+				err = fmt.Errorf("状态码 %d 不在允许列表 %v 中", resp.StatusCode, a.Codes)
+			}
+		case config.AssertionHeader:
+			headerValues := resp.Header.Values(a.Header)
+			values := make([]any, len(headerValues))
+			for j, v := range headerValues {
+				values[j] = v
+			}
+			err = checkValues(a, values, "header "+a.Header)
+		case config.AssertionBody:
+			if len(resp.Body) == 0 {
+				err = fmt.Errorf("响应体为空")
+			} else {
+				err = checkValues(a, []any{string(resp.Body)}, "body")
+			}
+		case config.AssertionJSON:
+			if !docParsed {
+				docParsed = true
+				if len(resp.Body) == 0 {
+					docErr = fmt.Errorf("响应体为空")
+				} else if e := json.Unmarshal(resp.Body, &doc); e != nil {
+					docErr = fmt.Errorf("响应体不是合法 JSON: %w", e)
+				}
+			}
+			if docErr != nil {
+				err = docErr
+				break
+			}
+			matches, e := jsonpath.Eval(doc, a.Path)
+			if e != nil {
+				err = e
+				break
+			}
+			err = checkValues(a, matches, a.Path)
+		default:
+			err = fmt.Errorf("未知断言类型: %s", a.Type)
+		}
+
+		if err != nil {
+			return 0, assertionSubStatus(a.Type), fmt.Errorf("assertions[%d] 失败: %w", i, err)
+		}
+	}
+
+	return baseStatus, baseSubStatus, nil
+}
+
+// allowsStatusCode 判断状态码是否被显式允许（至少有一个 status_code 断言，且全部包含该状态码）
+func allowsStatusCode(assertions []config.AssertionConfig, code int) bool {
+	found := false
+	for _, a := range assertions {
+		if a.Type != config.AssertionStatusCode {
+			continue
+		}
+		if !slices.Contains(a.Codes, code) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// assertionSubStatus 断言失败时对应的细分状态
+func assertionSubStatus(assertionType string) storage.SubStatus {
+	switch assertionType {
+	case config.AssertionStatusCode:
+		return storage.SubStatusUnexpectedStatus
+	case config.AssertionHeader:
+		return storage.SubStatusHeaderMismatch
+	default:
+		return storage.SubStatusContentMismatch
+	}
+}
+
+// checkValues 对匹配到的值执行断言操作
+// exists/not_exists 只看是否存在；not_equals 要求所有值都不相等；其余操作任一值满足即通过
+func checkValues(a config.AssertionConfig, values []any, target string) error {
+	switch a.Op {
+	case config.AssertOpExists:
+		if len(values) == 0 {
+			return fmt.Errorf("%s 不存在", target)
+		}
+		return nil
+	case config.AssertOpNotExists:
+		if len(values) > 0 {
+			return fmt.Errorf("%s 不应存在", target)
+		}
+		return nil
+	case config.AssertOpNotEquals:
+		for _, v := range values {
+			if valueEquals(v, a.Value) {
+				return fmt.Errorf("%s 不应等于 %v", target, a.Value)
+			}
+		}
+		return nil
+	}
+
+	if len(values) == 0 {
+		return fmt.Errorf("%s 不存在", target)
+	}
+
+	for _, v := range values {
+		ok, err := matchValue(a.Op, v, a.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s 不满足 %s %v", target, a.Op, a.Value)
+}
+
+// matchValue 执行单个值的比较操作
+func matchValue(op string, actual, expected any) (bool, error) {
+	switch op {
+	case config.AssertOpEquals:
+		return valueEquals(actual, expected), nil
+	case config.AssertOpContains:
+		return strings.Contains(stringifyValue(actual), fmt.Sprint(expected)), nil
+	case config.AssertOpRegex:
+		re, err := regexp.Compile(fmt.Sprint(expected))
+		if err != nil {
+			return false, fmt.Errorf("正则表达式无效: %w", err)
+		}
+		return re.MatchString(stringifyValue(actual)), nil
+	case config.AssertOpGT, config.AssertOpGTE, config.AssertOpLT, config.AssertOpLTE:
+		a, ok := toFloat(actual)
+		if !ok {
+			return false, nil
+		}
+		e, ok := toFloat(expected)
+		if !ok {
+			return false, fmt.Errorf("期望值不是数值: %v", expected)
+		}
+		switch op {
+		case config.AssertOpGT:
+			return a > e, nil
+		case config.AssertOpGTE:
+			return a >= e, nil
+		case config.AssertOpLT:
+			return a < e, nil
+		default:
+			return a <= e, nil
+		}
+	default:
+		return false, fmt.Errorf("不支持的操作符: %s", op)
+	}
+}
+
+// valueEquals 比较实际值与期望值（数值按浮点比较，其余按字符串比较）
+func valueEquals(actual, expected any) bool {
+	if a, ok := actual.(float64); ok {
+		if e, ok := toFloat(expected); ok {
+			return a == e
+		}
+	}
+	if a, ok := actual.(bool); ok {
+		if e, ok := expected.(bool); ok {
+			return a == e
+		}
+	}
+	if actual == nil {
+		return expected == nil || fmt.Sprint(expected) == "null"
+	}
+	return stringifyValue(actual) == fmt.Sprint(expected)
+}
+
+// stringifyValue 将 JSON 值转为字符串（字符串原样返回，其余序列化为 JSON）
+func stringifyValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// toFloat 尝试将值转换为 float64
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
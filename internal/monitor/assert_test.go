@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"net/http"
+	"testing"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+func TestEvaluateAssertionsJSONPath(t *testing.T) {
+	t.Parallel()
+
+	assertions := []config.AssertionConfig{
+		{Type: config.AssertionJSON, Path: "$.choices[0].message.content", Op: config.AssertOpExists},
+		{Type: config.AssertionJSON, Path: "$.usage.completion_tokens", Op: config.AssertOpGTE, Value: 1},
+	}
+
+	ok := &responseSnapshot{StatusCode: 200, Body: []byte(`{"choices":[{"message":{"content":"pong"}}],"usage":{"completion_tokens":1}}`)}
+	status, subStatus, err := evaluateAssertions(1, storage.SubStatusNone, ok, assertions)
+	if status != 1 || subStatus != storage.SubStatusNone || err != nil {
+		t.Fatalf("expected assertions to pass, got %d/%s err=%v", status, subStatus, err)
+	}
+
+	// 错误 JSON 中恰好包含关键字，也不应判为成功
+	errBody := &responseSnapshot{StatusCode: 200, Body: []byte(`{"error":{"message":"choices content unavailable"}}`)}
+	status, subStatus, err = evaluateAssertions(1, storage.SubStatusNone, errBody, assertions)
+	if status != 0 || subStatus != storage.SubStatusContentMismatch || err == nil {
+		t.Fatalf("expected content_mismatch, got %d/%s err=%v", status, subStatus, err)
+	}
+}
+
+func TestEvaluateAssertionsStatusCodeAndHeader(t *testing.T) {
+	t.Parallel()
+
+	resp := &responseSnapshot{
+		StatusCode: 202,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}
+
+	status, subStatus, _ := evaluateAssertions(1, storage.SubStatusNone, resp, []config.AssertionConfig{
+		{Type: config.AssertionStatusCode, Codes: []int{200}},
+	})
+	if status != 0 || subStatus != storage.SubStatusUnexpectedStatus {
+		t.Fatalf("expected unexpected_status, got %d/%s", status, subStatus)
+	}
+
+	status, subStatus, _ = evaluateAssertions(1, storage.SubStatusNone, resp, []config.AssertionConfig{
+		{Type: config.AssertionHeader, Header: "Content-Type", Op: config.AssertOpContains, Value: "text/event-stream"},
+	})
+	if status != 0 || subStatus != storage.SubStatusHeaderMismatch {
+		t.Fatalf("expected header_mismatch, got %d/%s", status, subStatus)
+	}
+}
+
+func TestEvaluateAssertionsSkipsRateLimit(t *testing.T) {
+	t.Parallel()
+
+	resp := &responseSnapshot{StatusCode: 429, Body: []byte(`{"error":"too many requests"}`)}
+	status, subStatus, _ := evaluateAssertions(2, storage.SubStatusRateLimit, resp, []config.AssertionConfig{
+		{Type: config.AssertionJSON, Path: "$.choices", Op: config.AssertOpExists},
+	})
+	if status != 2 || subStatus != storage.SubStatusRateLimit {
+		t.Fatalf("expected rate limit status to be kept, got %d/%s", status, subStatus)
+	}
+}
+
+func TestEvaluateAssertionsStatusCodeAllowList(t *testing.T) {
+	t.Parallel()
+
+	allow404 := []config.AssertionConfig{
+		{Type: config.AssertionStatusCode, Codes: []int{200, 404}},
+		{Type: config.AssertionBody, Op: config.AssertOpContains, Value: "not found"},
+	}
+
+	resp := &responseSnapshot{StatusCode: 404, Body: []byte(`{"error":"not found"}`)}
+	status, subStatus, err := evaluateAssertions(0, storage.SubStatusClientError, resp, allow404)
+	if status != 1 || subStatus != storage.SubStatusNone || err != nil {
+		t.Fatalf("expected allow-listed 404 to be green, got %d/%s (%v)", status, subStatus, err)
+	}
+
+	// 放行后其余断言照常校验
+	resp.Body = []byte(`{"error":"gone"}`)
+	if status, subStatus, _ := evaluateAssertions(0, storage.SubStatusClientError, resp, allow404); status != 0 || subStatus != storage.SubStatusContentMismatch {
+		t.Fatalf("expected content_mismatch for allow-listed 404, got %d/%s", status, subStatus)
+	}
+
+	// 429 同样可以显式放行
+	resp = &responseSnapshot{StatusCode: 429}
+	if status, _, _ := evaluateAssertions(2, storage.SubStatusRateLimit, resp, []config.AssertionConfig{
+		{Type: config.AssertionStatusCode, Codes: []int{429}},
+	}); status != 1 {
+		t.Fatalf("expected allow-listed 429 to be green, got %d", status)
+	}
+
+	// 未列入白名单的错误状态码保持原状态
+	resp = &responseSnapshot{StatusCode: 500}
+	if status, subStatus, _ := evaluateAssertions(0, storage.SubStatusServerError, resp, allow404); status != 0 || subStatus != storage.SubStatusServerError {
+		t.Fatalf("expected server_error to be kept, got %d/%s", status, subStatus)
+	}
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// CertInfo 探测握手时观察到的对端证书信息
+type CertInfo struct {
+	Subject    string    // 叶子证书主题
+	Issuer     string    // 叶子证书签发者
+	NotBefore  time.Time // 叶子证书生效时间
+	NotAfter   time.Time // 证书链中最早的过期时间（中间证书先过期同样会导致校验失败）
+	TLSVersion string    // 协商的 TLS 版本（握手失败时为空）
+}
+
+// certInfoFromState 从已完成的 TLS 连接中提取证书信息（非 HTTPS 时返回 nil）
+func certInfoFromState(state *tls.ConnectionState) *CertInfo {
+	if state == nil {
+		return nil
+	}
+	info := certInfoFromChain(state.PeerCertificates)
+	if info != nil {
+		info.TLSVersion = tls.VersionName(state.Version)
+	}
+	return info
+}
+
+// certInfoFromChain 从对端证书链提取证书信息（证书链为空时返回 nil）
+func certInfoFromChain(chain []*x509.Certificate) *CertInfo {
+	if len(chain) == 0 {
+		return nil
+	}
+	leaf := chain[0]
+	info := &CertInfo{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}
+	for _, cert := range chain[1:] {
+		if cert.NotAfter.Before(info.NotAfter) {
+			info.NotAfter = cert.NotAfter
+		}
+	}
+	return info
+}
+
+// classifyCertError 识别证书校验失败，返回对应的细分状态及未通过校验的证书信息
+// 非证书错误返回 SubStatusNone
+func classifyCertError(err error) (storage.SubStatus, *CertInfo) {
+	var subStatus storage.SubStatus
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var authorityErr x509.UnknownAuthorityError
+	switch {
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		subStatus = storage.SubStatusCertExpired
+	case errors.As(err, &hostnameErr):
+		subStatus = storage.SubStatusCertHostnameMismatch
+	case errors.As(err, &authorityErr):
+		subStatus = storage.SubStatusCertUnknownAuthority
+	default:
+		return storage.SubStatusNone, nil
+	}
+
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return subStatus, certInfoFromChain(verifyErr.UnverifiedCertificates)
+	}
+	return subStatus, nil
+}
+
+// evaluateCertExpiry 证书剩余有效期不足 warningDays 天时将绿色降级为黄色（warningDays < 0 表示不预警）
+func evaluateCertExpiry(status int, subStatus storage.SubStatus, info *CertInfo, warningDays int, now time.Time) (int, storage.SubStatus) {
+	if status != 1 || info == nil || warningDays < 0 {
+		return status, subStatus
+	}
+	if info.NotAfter.Sub(now) <= time.Duration(warningDays)*24*time.Hour {
+		return 2, storage.SubStatusCertExpiring
+	}
+	return status, subStatus
+}
@@ -0,0 +1,140 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// newCertServer 启动使用自签名证书（有效期为 [notBefore, notAfter]，签发给 127.0.0.1）的 HTTPS 服务
+func newCertServer(t *testing.T, notBefore, notAfter time.Time) *httptest.Server {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "relay.test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// trustingTransport 返回信任 srv 证书的传输配置
+func trustingTransport(t *testing.T, srv *httptest.Server) *config.TransportConfig {
+	t.Helper()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, data, 0o600); err != nil {
+		t.Fatalf("write CA file: %v", err)
+	}
+	return &config.TransportConfig{CAFile: caFile}
+}
+
+func TestProbeCertificateErrors(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	valid := newCertServer(t, now.Add(-time.Hour), now.Add(90*24*time.Hour))
+	expired := newCertServer(t, now.Add(-30*24*time.Hour), now.Add(-time.Hour))
+
+	tests := []struct {
+		name    string
+		srv     *httptest.Server
+		trusted bool
+		url     string
+		want    storage.SubStatus
+	}{
+		{"unknown authority", valid, false, valid.URL, storage.SubStatusCertUnknownAuthority},
+		{"hostname mismatch", valid, true, strings.Replace(valid.URL, "127.0.0.1", "localhost", 1), storage.SubStatusCertHostnameMismatch},
+		{"expired", expired, true, expired.URL, storage.SubStatusCertExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProber(nil)
+			t.Cleanup(p.Close)
+			cfg := &config.ServiceConfig{
+				Provider: "demo", Service: "cc", URL: tt.url, Method: "GET", TimeoutDuration: 5 * time.Second,
+			}
+			if tt.trusted {
+				cfg.Transport = trustingTransport(t, tt.srv)
+			}
+
+			result := p.Probe(context.Background(), cfg)
+			if result.Status != 0 || result.SubStatus != tt.want {
+				t.Fatalf("expected red %s, got status=%d sub_status=%s err=%v", tt.want, result.Status, result.SubStatus, result.Error)
+			}
+			if result.Certificate == nil || result.Certificate.Subject != "CN=relay.test" {
+				t.Fatalf("expected unverified certificate info, got %+v", result.Certificate)
+			}
+		})
+	}
+}
+
+func TestProbeWarnsBeforeCertificateExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	srv := newCertServer(t, now.Add(-time.Hour), now.Add(3*24*time.Hour))
+	p := NewProber(nil)
+	t.Cleanup(p.Close)
+	cfg := &config.ServiceConfig{
+		Provider: "demo", Service: "cc", URL: srv.URL, Method: "GET", TimeoutDuration: 5 * time.Second,
+		CertExpiryWarningDays: 7, Transport: trustingTransport(t, srv),
+	}
+
+	result := p.Probe(context.Background(), cfg)
+	if result.Status != 2 || result.SubStatus != storage.SubStatusCertExpiring {
+		t.Fatalf("expected yellow cert_expiring, got status=%d sub_status=%s", result.Status, result.SubStatus)
+	}
+	if result.Certificate == nil || result.Certificate.TLSVersion != "TLS 1.3" ||
+		result.Certificate.NotAfter.Unix() != srv.Certificate().NotAfter.Unix() {
+		t.Fatalf("unexpected certificate info: %+v", result.Certificate)
+	}
+
+	// 剩余天数大于预警阈值或关闭预警时保持绿色
+	for _, days := range []int{2, -1} {
+		cfg.CertExpiryWarningDays = days
+		if result := p.Probe(context.Background(), cfg); result.Status != 1 {
+			t.Fatalf("warning days %d: expected green, got status=%d sub_status=%s", days, result.Status, result.SubStatus)
+		}
+	}
+}
@@ -1,12 +1,22 @@
 package monitor
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"sync"
 	"time"
+
+	"monitor/internal/config"
 )
 
-// ClientPool HTTP客户端池（按provider管理，复用连接）
+// ClientPool HTTP客户端池（按 provider + 传输配置管理，复用连接）
 type ClientPool struct {
 	mu      sync.RWMutex
 	clients map[string]*http.Client
@@ -19,14 +29,21 @@ func NewClientPool() *ClientPool {
 	}
 }
 
-// GetClient 获取或创建客户端
-func (p *ClientPool) GetClient(provider string) *http.Client {
+// clientKey 客户端标识：同一 provider 下传输配置相同的监控项共享连接池
+func clientKey(cfg *config.ServiceConfig) string {
+	return cfg.Provider + "\x00" + cfg.Transport.Key()
+}
+
+// GetClient 获取或创建监控项对应的客户端
+func (p *ClientPool) GetClient(cfg *config.ServiceConfig) (*http.Client, error) {
+	key := clientKey(cfg)
+
 	p.mu.RLock()
-	client, exists := p.clients[provider]
+	client, exists := p.clients[key]
 	p.mu.RUnlock()
 
 	if exists {
-		return client
+		return client, nil
 	}
 
 	// 创建新客户端
@@ -34,23 +51,39 @@ func (p *ClientPool) GetClient(provider string) *http.Client {
 	defer p.mu.Unlock()
 
 	// 双重检查
-	if client, exists := p.clients[provider]; exists {
-		return client
+	if client, exists := p.clients[key]; exists {
+		return client, nil
 	}
 
-	// 创建带连接池的HTTP客户端
-	client = &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableKeepAlives:   false,
-		},
+	transport, err := newTransport(cfg.Transport)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Transport != nil && cfg.Transport.InsecureSkipVerify {
+		log.Printf("[Probe] ⚠️  警告: %s 已开启 insecure_skip_verify，TLS 证书校验已关闭，存在中间人攻击风险，请仅用于排查问题", cfg.Provider)
+	}
+
+	// 超时由每次探测的 context 控制（支持监控项级别的 timeout），这里不设置全局 Timeout
+	client = &http.Client{Transport: transport}
+	p.clients[key] = client
+	return client, nil
+}
+
+// UpdateConfig 移除新配置中不再使用的客户端（热更新时调用），修改过传输配置的监控项会使用新建的客户端
+func (p *ClientPool) UpdateConfig(cfg *config.AppConfig) {
+	active := make(map[string]bool, len(cfg.Monitors))
+	for i := range cfg.Monitors {
+		active[clientKey(&cfg.Monitors[i])] = true
 	}
 
-	p.clients[provider] = client
-	return client
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, client := range p.clients {
+		if !active[key] {
+			client.CloseIdleConnections()
+			delete(p.clients, key)
+		}
+	}
 }
 
 // Close 关闭所有客户端
@@ -64,3 +97,88 @@ func (p *ClientPool) Close() {
 
 	p.clients = make(map[string]*http.Client)
 }
+
+// newTransport 按传输配置创建带连接池的 Transport（tc 为 nil 时使用默认配置）
+func newTransport(tc *config.TransportConfig) (*http.Transport, error) {
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   false,
+	}
+	if tc == nil {
+		return transport, nil
+	}
+
+	if tc.Proxy != "" {
+		proxyURL, err := url.Parse(tc.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("解析代理地址失败: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(tc)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if addr := tc.DNSServerAddr(); addr != "" {
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	hosts := tc.Hosts
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			if ip, ok := hosts[host]; ok {
+				address = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	if tc.ForceHTTP1 {
+		// 非 nil 的空 TLSNextProto 会禁用 HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else {
+		// 自定义 DialContext/TLSClientConfig 后需显式开启 HTTP/2，与默认客户端行为保持一致
+		transport.ForceAttemptHTTP2 = true
+	}
+	return transport, nil
+}
+
+// newTLSConfig 加载自定义 CA、客户端证书，并按需关闭证书校验
+func newTLSConfig(tc *config.TransportConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: tc.InsecureSkipVerify}
+
+	if tc.CAFile != "" {
+		pem, err := os.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书文件中没有有效的 PEM 证书: %s", tc.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if tc.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(tc.ClientCert, tc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+func TestClientPoolKeysByTransport(t *testing.T) {
+	t.Parallel()
+
+	pool := NewClientPool()
+	defer pool.Close()
+
+	plain := &config.ServiceConfig{Provider: "demo", Service: "cc"}
+	proxied := &config.ServiceConfig{Provider: "demo", Service: "cx", Transport: &config.TransportConfig{Proxy: "socks5://127.0.0.1:1080"}}
+
+	get := func(cfg *config.ServiceConfig) *http.Client {
+		client, err := pool.GetClient(cfg)
+		if err != nil {
+			t.Fatalf("get client: %v", err)
+		}
+		return client
+	}
+
+	a, b := get(plain), get(proxied)
+	if a == b {
+		t.Fatal("monitors with different transports should not share a client")
+	}
+	if get(&config.ServiceConfig{Provider: "demo", Service: "other"}) != a {
+		t.Fatal("monitors with the same provider and transport should share a client")
+	}
+
+	// 热更新后不再使用的传输配置会被移除，再次获取时重建
+	pool.UpdateConfig(&config.AppConfig{Monitors: []config.ServiceConfig{*plain}})
+	if get(plain) != a {
+		t.Fatal("client still in use should be kept after reload")
+	}
+	if get(proxied) == b {
+		t.Fatal("client for removed transport should be rebuilt")
+	}
+}
+
+func TestClientPoolRebuildsOnCertificateRotation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	oldSrv := newCertServer(t, now.Add(-time.Hour), now.Add(time.Hour))
+	newSrv := newCertServer(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	pool := NewClientPool()
+	defer pool.Close()
+
+	cfg := &config.ServiceConfig{Provider: "demo", Service: "cc", Transport: trustingTransport(t, oldSrv)}
+	before, err := pool.GetClient(cfg)
+	if err != nil {
+		t.Fatalf("get client: %v", err)
+	}
+
+	// 同一路径下替换 CA 证书
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newSrv.Certificate().Raw})
+	if err := os.WriteFile(cfg.Transport.CAFile, data, 0o600); err != nil {
+		t.Fatalf("rotate CA file: %v", err)
+	}
+	if err := os.Chtimes(cfg.Transport.CAFile, now.Add(time.Minute), now.Add(time.Minute)); err != nil {
+		t.Fatalf("touch CA file: %v", err)
+	}
+
+	after, err := pool.GetClient(cfg)
+	if err != nil {
+		t.Fatalf("get client after rotation: %v", err)
+	}
+	if after == before {
+		t.Fatal("client should be rebuilt after the CA file changes")
+	}
+	resp, err := after.Get(newSrv.URL)
+	if err != nil {
+		t.Fatalf("rebuilt client should trust the rotated CA: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestProbeUsesTransportSettings(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	// httptest 证书签发给 example.com，通过 hosts 映射到本地服务
+	url := strings.Replace(srv.URL, "127.0.0.1", "example.com", 1)
+	transport := trustingTransport(t, srv)
+	transport.Hosts = map[string]string{"example.com": "127.0.0.1"}
+
+	p := NewProber(nil)
+	defer p.Close()
+	for _, forceHTTP1 := range []bool{false, true} {
+		tc := *transport
+		tc.ForceHTTP1 = forceHTTP1
+		result, body := p.probe(context.Background(), &config.ServiceConfig{
+			Provider: "demo", Service: "cc", URL: url, Method: "GET", TimeoutDuration: 5 * time.Second,
+			Transport: &tc, SuccessContains: "HTTP",
+		})
+		if result.Status != 1 {
+			t.Fatalf("force_http1=%v: unexpected result %+v", forceHTTP1, result)
+		}
+		want := "HTTP/2.0"
+		if forceHTTP1 {
+			want = "HTTP/1.1"
+		}
+		if string(body) != want {
+			t.Fatalf("force_http1=%v: expected %s, got %s", forceHTTP1, want, body)
+		}
+	}
+}
+
+func TestProbeUsesProxy(t *testing.T) {
+	t.Parallel()
+
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 经代理的请求使用绝对 URI
+		if r.URL.Host == "relay.invalid" {
+			proxied.Add(1)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+
+	p := NewProber(nil)
+	defer p.Close()
+	result := p.Probe(context.Background(), &config.ServiceConfig{
+		Provider: "demo", Service: "cc", URL: "http://relay.invalid/v1/models", Method: "GET",
+		TimeoutDuration: 5 * time.Second, Transport: &config.TransportConfig{Proxy: proxy.URL},
+	})
+	if result.Status != 1 || proxied.Load() != 1 {
+		t.Fatalf("expected request through proxy, got status=%d proxied=%d err=%v", result.Status, proxied.Load(), result.Error)
+	}
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"io"
+	"maps"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"monitor/internal/config"
+)
+
+const (
+	// maxDiagnosticBodySize 保存的响应体片段上限（字节）
+	maxDiagnosticBodySize = 1024
+
+	// maxDiagnosticMessageSize 保存的错误信息上限（字节）
+	maxDiagnosticMessageSize = 512
+
+	// diagnosticReadSize 读取响应体用于诊断的上限（多读一些，避免密钥恰好被截断在边界而漏掉脱敏）
+	diagnosticReadSize = 4 * maxDiagnosticBodySize
+)
+
+// secretPatterns 常见密钥格式（第 1 个分组为需要脱敏的部分）
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)bearer\s+([A-Za-z0-9._~+/=-]{8,})`),
+	regexp.MustCompile(`\b(sk-[A-Za-z0-9_-]{8,})`),
+	regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{20,})`),
+	regexp.MustCompile(`(?i)[?&](?:key|api_key|apikey|token|access_token)=([^&\s"']+)`),
+}
+
+// readDiagnosticBody 读取响应体开头部分用于失败诊断，其余内容丢弃
+func readDiagnosticBody(r io.Reader) []byte {
+	data, _ := io.ReadAll(io.LimitReader(r, diagnosticReadSize))
+	_, _ = io.Copy(io.Discard, r)
+	return data
+}
+
+// attachDiagnostics 为非绿色探测附加脱敏后的错误信息和响应体片段
+func attachDiagnostics(result *ProbeResult, body []byte, cfg *config.ServiceConfig) {
+	if result.Status == 1 {
+		return
+	}
+	if result.Error != nil {
+		result.ErrorMessage = SanitizeDiagnostic(result.Error.Error(), cfg, maxDiagnosticMessageSize)
+	}
+	if len(body) > 0 {
+		result.ResponseSnippet = SanitizeDiagnostic(string(body), cfg, maxDiagnosticBodySize)
+	}
+}
+
+// SanitizeDiagnostic 脱敏文本中的 API Key、认证头及常见密钥格式，并截断到 limit 字节（按 UTF-8 字符边界）
+func SanitizeDiagnostic(text string, cfg *config.ServiceConfig, limit int) string {
+	text = strings.ToValidUTF8(text, "�")
+	if cfg != nil {
+		text = RedactSecret(text, cfg.APIKey)
+		for k, v := range cfg.Headers {
+			// 认证类请求头中可能直接写了密钥（未使用 {{API_KEY}} 占位符）
+			if len(v) >= 8 && isSensitiveHeader(k) {
+				text = RedactSecret(text, v)
+			}
+		}
+	}
+	for _, re := range secretPatterns {
+		text = re.ReplaceAllStringFunc(text, func(m string) string {
+			loc := re.FindStringSubmatchIndex(m)
+			return m[:loc[2]] + MaskSensitiveInfo(m[loc[2]:loc[3]]) + m[loc[3]:]
+		})
+	}
+
+	text = strings.TrimSpace(text)
+	if limit > 0 && len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "…"
+	}
+	return text
+}
+
+// RedactServiceConfig 返回脱敏后的监控项配置副本（用于管理 API 展示）：认证类请求头整体隐藏，url、其余请求头和请求体中的密钥打码
+func RedactServiceConfig(cfg *config.ServiceConfig) config.ServiceConfig {
+	redacted := *cfg
+	redacted.APIKey = ""
+	redacted.Transport = redactTransport(cfg.Transport)
+	redacted.URL = SanitizeDiagnostic(cfg.URL, cfg, 0)
+	redacted.Body = SanitizeDiagnostic(cfg.Body, cfg, 0)
+	if cfg.Headers != nil {
+		redacted.Headers = make(map[string]string, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			if isSensitiveHeader(k) {
+				v = "***"
+			} else {
+				v = SanitizeDiagnostic(v, cfg, 0)
+			}
+			redacted.Headers[k] = v
+		}
+	}
+	return redacted
+}
+
+// redactTransport 返回脱敏后的传输配置副本：代理地址中的凭据打码，私钥文件路径隐藏，其余设置原样保留
+func redactTransport(t *config.TransportConfig) *config.TransportConfig {
+	if t == nil {
+		return nil
+	}
+	redacted := *t
+	redacted.Hosts = maps.Clone(t.Hosts)
+	if u, err := url.Parse(t.Proxy); err == nil && u.User != nil {
+		userinfo := "***" // 只有用户名时通常就是令牌
+		if _, ok := u.User.Password(); ok {
+			userinfo = url.User(u.User.Username()).String() + ":***"
+		}
+		u.User = nil
+		redacted.Proxy = strings.Replace(u.String(), "://", "://"+userinfo+"@", 1)
+	}
+	if t.ClientKey != "" {
+		redacted.ClientKey = "***"
+	}
+	return &redacted
+}
+
+// isSensitiveHeader 请求头名称看起来承载认证凭据
+func isSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, kw := range []string{"auth", "key", "token", "secret", "cookie"} {
+		if strings.Contains(name, kw) {
+			return true
+		}
+	}
+	return false
+}
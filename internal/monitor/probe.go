@@ -3,11 +3,14 @@ package monitor
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"monitor/internal/config"
@@ -19,29 +22,103 @@ type ProbeResult struct {
 	Provider  string
 	Service   string
 	Channel   string
-	Status    int               // 1=绿, 0=红, 2=黄
+	Status    int               // 1=绿, 0=红, 2=黄, 4=维护中
 	SubStatus storage.SubStatus // 细分状态（黄色/红色原因）
 	Latency   int               // ms
 	Timestamp int64
 	Error     error
+
+	// 失败诊断信息（仅非绿色探测，已脱敏并截断）
+	HTTPStatus      int    // HTTP 状态码（未收到响应时为 0）
+	ErrorMessage    string // 错误信息
+	ResponseSnippet string // 响应体开头片段
+
+	FirstByteLatency  int // 首字节延迟（ms）
+	FirstTokenLatency int // 首 token 延迟（ms，仅流式探测）
+	StreamDuration    int // 流式响应总耗时（ms，仅流式探测）
+
+	// 网络阶段耗时（ms，复用已有连接时 DNS/连接/TLS 为 0）
+	DNSDuration      int
+	ConnectDuration  int
+	TLSDuration      int
+	BodyReadDuration int // 从收到响应头到读取完响应体
+
+	Certificate *CertInfo // 对端 TLS 证书信息（非 HTTPS 或握手前失败时为 nil）
+
+	Attempts int // 尝试次数（含重试，1 表示未重试）
+
+	Region string // 探测节点上报结果的地域（本地探测为空，观察者按地域分别跟踪状态）
+}
+
+// ResultFromRecord 由已保存的探测记录还原探测结果（中心节点通知观察者探测节点上报的结果）
+func ResultFromRecord(record *storage.ProbeRecord) *ProbeResult {
+	result := &ProbeResult{
+		Provider:          record.Provider,
+		Service:           record.Service,
+		Channel:           record.Channel,
+		Status:            record.Status,
+		SubStatus:         record.SubStatus,
+		Latency:           record.Latency,
+		Timestamp:         record.Timestamp,
+		HTTPStatus:        record.HTTPStatus,
+		ErrorMessage:      record.ErrorMessage,
+		ResponseSnippet:   record.ResponseSnippet,
+		FirstByteLatency:  record.FirstByteLatency,
+		FirstTokenLatency: record.FirstTokenLatency,
+		StreamDuration:    record.StreamDuration,
+		DNSDuration:       record.DNSDuration,
+		ConnectDuration:   record.ConnectDuration,
+		TLSDuration:       record.TLSDuration,
+		BodyReadDuration:  record.BodyReadDuration,
+		Attempts:          record.Attempts,
+		Region:            record.Region,
+	}
+	if record.ErrorMessage != "" {
+		result.Error = errors.New(record.ErrorMessage)
+	}
+	return result
+}
+
+// ResultObserver 探测结果观察者（如告警），在探测结果保存后由调度器调用
+// 实现需自行保证并发安全，且不应长时间阻塞
+type ResultObserver interface {
+	OnProbeResult(cfg *config.ServiceConfig, result *ProbeResult)
+}
+
+// ResultSink 探测结果的保存目标（本地存储，或探测节点模式下上报到中心节点）
+type ResultSink interface {
+	SaveRecord(record *storage.ProbeRecord) error
+	SaveCertificate(cert *storage.Certificate) error
 }
 
 // Prober 探测器
 type Prober struct {
 	clientPool *ClientPool
-	storage    storage.Storage
+	storage    ResultSink
+
+	// 本实例的探测地域（热更新时替换）
+	region atomic.Value
 }
 
 // NewProber 创建探测器
-func NewProber(storage storage.Storage) *Prober {
-	return &Prober{
+func NewProber(sink ResultSink) *Prober {
+	p := &Prober{
 		clientPool: NewClientPool(),
-		storage:    storage,
+		storage:    sink,
 	}
+	p.region.Store("")
+	return p
 }
 
-// Probe 执行单次探测
-func (p *Prober) Probe(ctx context.Context, cfg *config.ServiceConfig) *ProbeResult {
+// probeOnce 执行一次探测请求（非绿色结果附带脱敏后的诊断信息）
+func (p *Prober) probeOnce(ctx context.Context, cfg *config.ServiceConfig) *ProbeResult {
+	result, body := p.probe(ctx, cfg)
+	attachDiagnostics(result, body, cfg)
+	return result
+}
+
+// probe 执行探测，返回结果及读取到的响应体（用于失败诊断）
+func (p *Prober) probe(ctx context.Context, cfg *config.ServiceConfig) (*ProbeResult, []byte) {
 	result := &ProbeResult{
 		Provider:  cfg.Provider,
 		Service:   cfg.Service,
@@ -50,6 +127,13 @@ func (p *Prober) Probe(ctx context.Context, cfg *config.ServiceConfig) *ProbeRes
 		SubStatus: storage.SubStatusNone,
 	}
 
+	// 请求超时（覆盖连接、响应头和响应体/流式读取的全过程）
+	if cfg.TimeoutDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.TimeoutDuration)
+		defer cancel()
+	}
+
 	// 准备请求体
 	reqBody := bytes.NewBuffer([]byte(cfg.Body))
 	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, reqBody)
@@ -57,7 +141,7 @@ func (p *Prober) Probe(ctx context.Context, cfg *config.ServiceConfig) *ProbeRes
 		result.Error = fmt.Errorf("创建请求失败: %w", err)
 		result.Status = 0
 		result.SubStatus = storage.SubStatusNetworkError
-		return result
+		return result, nil
 	}
 
 	// 设置Headers（已处理过占位符）
@@ -65,76 +149,126 @@ func (p *Prober) Probe(ctx context.Context, cfg *config.ServiceConfig) *ProbeRes
 		req.Header.Set(k, v)
 	}
 
-	// 获取对应provider的客户端
-	client := p.clientPool.GetClient(cfg.Provider)
+	// 获取对应 provider 及传输配置的客户端
+	client, err := p.clientPool.GetClient(cfg)
+	if err != nil {
+		result.Error = fmt.Errorf("创建 HTTP 客户端失败: %w", err)
+		result.Status = 0
+		result.SubStatus = storage.SubStatusNetworkError
+		return result, nil
+	}
 
 	// 发送请求并计时
 	start := time.Now()
+	timer := newPhaseTimer(start)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timer.trace()))
+
 	resp, err := client.Do(req)
-	latency := int(time.Since(start).Milliseconds())
+	headersAt := time.Now()
+	latency := int(headersAt.Sub(start).Milliseconds())
 	result.Latency = latency
 
+	// 网络阶段耗时（请求失败时同样记录已完成的阶段，便于区分 DNS/连接/TLS 故障）
+	phases := timer.durations()
+	result.DNSDuration = phaseMillis(phases.DNS)
+	result.ConnectDuration = phaseMillis(phases.Connect)
+	result.TLSDuration = phaseMillis(phases.TLS)
+	result.FirstByteLatency = phaseMillis(phases.FirstByte)
+
 	if err != nil {
 		log.Printf("[Probe] ERROR %s-%s-%s: %v", cfg.Provider, cfg.Service, cfg.Channel, err)
 		result.Error = err
 		result.Status = 0
 		result.SubStatus = storage.SubStatusNetworkError
-		return result
+		// 证书校验失败单独归类（过期、域名不匹配、签发者不受信任）
+		if subStatus, cert := classifyCertError(err); subStatus != storage.SubStatusNone {
+			result.SubStatus = subStatus
+			result.Certificate = cert
+		}
+		return result, nil
 	}
 	defer resp.Body.Close()
+	result.HTTPStatus = resp.StatusCode
+	result.Certificate = certInfoFromState(resp.TLS)
+
+	// 流式探测：解析 SSE，记录首 token 延迟和流总耗时
+	var stream *streamResult
+	statusLatency := latency
+	if cfg.Stream && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		stream = readEventStream(resp.Body, start, cfg.StreamDoneMarker)
+		result.FirstTokenLatency = int(stream.FirstToken.Milliseconds())
+		result.StreamDuration = int(stream.Duration.Milliseconds())
+		// 流式场景下慢请求以首 token 延迟判定
+		if stream.FirstToken > 0 {
+			statusLatency = result.FirstTokenLatency
+		}
+	}
 
-	// 完整读取响应体（避免连接泄漏），在需要内容匹配时保留文本
+	// 完整读取响应体（避免连接泄漏），在需要内容匹配时保留文本，非 2xx 响应保留开头部分用于失败诊断
+	assertions := cfg.EffectiveAssertions()
 	var bodyBytes []byte
-	if cfg.SuccessContains != "" {
+	if stream != nil {
+		bodyBytes = stream.Body
+		_, _ = io.Copy(io.Discard, resp.Body)
+	} else if needsBody(assertions) {
 		if data, readErr := io.ReadAll(resp.Body); readErr == nil {
 			bodyBytes = data
 		} else {
 			log.Printf("[Probe] 读取响应体失败 %s-%s-%s: %v", cfg.Provider, cfg.Service, cfg.Channel, readErr)
 		}
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes = readDiagnosticBody(resp.Body)
 	} else {
 		_, _ = io.Copy(io.Discard, resp.Body)
 	}
+	result.BodyReadDuration = phaseMillis(time.Since(headersAt))
 
 	// 判定状态（先按 HTTP/延迟，再根据响应内容做二次判断）
-	status, subStatus := p.determineStatus(resp.StatusCode, latency, cfg.SlowLatencyDuration)
+	status, subStatus := p.determineStatus(resp.StatusCode, statusLatency, cfg.SlowLatencyDuration)
 	result.Status = status
 	result.SubStatus = subStatus
-	result.Status, result.SubStatus = evaluateStatus(result.Status, result.SubStatus, bodyBytes, cfg.SuccessContains)
+	if stream != nil {
+		result.Status, result.SubStatus = evaluateStream(result.Status, result.SubStatus, stream, resp.Header.Get("Content-Type"))
+		if stream.Err != nil {
+			result.Error = stream.Err
+		}
+	}
+	snapshot := &responseSnapshot{StatusCode: resp.StatusCode, Header: resp.Header, Body: bodyBytes}
+	var assertErr error
+	result.Status, result.SubStatus, assertErr = evaluateAssertions(result.Status, result.SubStatus, snapshot, assertions)
+	if assertErr != nil {
+		result.Error = assertErr
+		log.Printf("[Probe] 断言失败 %s-%s-%s: %v", cfg.Provider, cfg.Service, cfg.Channel, assertErr)
+	}
+	result.Status, result.SubStatus = evaluateCertExpiry(result.Status, result.SubStatus, result.Certificate, cfg.CertExpiryWarningDays, time.Now())
 
 	// 日志（不打印敏感信息）
-	log.Printf("[Probe] %s-%s-%s | Code: %d | Latency: %dms | Status: %d | SubStatus: %s",
-		cfg.Provider, cfg.Service, cfg.Channel, resp.StatusCode, latency, result.Status, result.SubStatus)
+	if stream != nil {
+		log.Printf("[Probe] %s-%s-%s | Code: %d | Latency: %dms | TTFT: %dms | Stream: %dms | Status: %d | SubStatus: %s",
+			cfg.Provider, cfg.Service, cfg.Channel, resp.StatusCode, latency, result.FirstTokenLatency, result.StreamDuration, result.Status, result.SubStatus)
+	} else {
+		log.Printf("[Probe] %s-%s-%s | Code: %d | Latency: %dms | Status: %d | SubStatus: %s",
+			cfg.Provider, cfg.Service, cfg.Channel, resp.StatusCode, latency, result.Status, result.SubStatus)
+	}
 
-	return result
+	return result, bodyBytes
 }
 
-// evaluateStatus 在基础状态上叠加响应内容匹配规则
-// 对所有 2xx 响应（绿色和慢速黄色）进行内容校验
-// 红色（已失败）和 429 黄色（非正常响应）不做校验
-func evaluateStatus(baseStatus int, baseSubStatus storage.SubStatus, body []byte, successContains string) (int, storage.SubStatus) {
-	if successContains == "" {
-		return baseStatus, baseSubStatus
-	}
-
-	// 红色已是最差状态，不需要校验
+// evaluateStream 校验流式响应：必须是 SSE 且正常结束，否则判定为红色
+func evaluateStream(baseStatus int, baseSubStatus storage.SubStatus, stream *streamResult, contentType string) (int, storage.SubStatus) {
+	// 仅对 2xx 响应（绿色或慢速黄色）做流式校验
 	if baseStatus == 0 {
 		return baseStatus, baseSubStatus
 	}
 
-	// 429 限流：响应体是错误信息，不做内容校验
-	if baseStatus == 2 && baseSubStatus == storage.SubStatusRateLimit {
-		return baseStatus, baseSubStatus
+	// 上游忽略 stream 参数直接返回 JSON 也视为流式异常
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "text/event-stream") {
+		return 0, storage.SubStatusStreamError
 	}
 
-	// 对 2xx 响应（绿色或慢速黄色）做内容校验
-	if len(body) == 0 {
-		// 没有响应内容，降级为红
-		return 0, storage.SubStatusContentMismatch
-	}
-
-	if !strings.Contains(string(body), successContains) {
-		// 未包含预期内容，认为请求语义失败
-		return 0, storage.SubStatusContentMismatch
+	// 中途断流、超时或未收到结束标记
+	if stream.Err != nil || !stream.Completed {
+		return 0, storage.SubStatusStreamError
 	}
 
 	return baseStatus, baseSubStatus
@@ -200,9 +334,48 @@ func (p *Prober) SaveResult(result *ProbeResult) error {
 		SubStatus: result.SubStatus,
 		Latency:   result.Latency,
 		Timestamp: result.Timestamp,
+		Attempts:  result.Attempts,
+		Region:    p.region.Load().(string),
+
+		FirstByteLatency:  result.FirstByteLatency,
+		FirstTokenLatency: result.FirstTokenLatency,
+		StreamDuration:    result.StreamDuration,
+
+		DNSDuration:      result.DNSDuration,
+		ConnectDuration:  result.ConnectDuration,
+		TLSDuration:      result.TLSDuration,
+		BodyReadDuration: result.BodyReadDuration,
+	}
+	if result.Status != 1 {
+		record.HTTPStatus = result.HTTPStatus
+		record.ErrorMessage = result.ErrorMessage
+		record.ResponseSnippet = result.ResponseSnippet
+	}
+
+	if err := p.storage.SaveRecord(record); err != nil {
+		return err
 	}
 
-	return p.storage.SaveRecord(record)
+	if cert := result.Certificate; cert != nil {
+		return p.storage.SaveCertificate(&storage.Certificate{
+			Provider:   result.Provider,
+			Service:    result.Service,
+			Channel:    result.Channel,
+			Subject:    cert.Subject,
+			Issuer:     cert.Issuer,
+			NotBefore:  cert.NotBefore.Unix(),
+			NotAfter:   cert.NotAfter.Unix(),
+			TLSVersion: cert.TLSVersion,
+			CheckedAt:  result.Timestamp,
+		})
+	}
+	return nil
+}
+
+// UpdateConfig 更新配置（热更新时调用），按新的传输配置重建 HTTP 客户端
+func (p *Prober) UpdateConfig(cfg *config.AppConfig) {
+	p.clientPool.UpdateConfig(cfg)
+	p.region.Store(cfg.Region)
 }
 
 // Close 关闭探测器
@@ -218,3 +391,11 @@ func MaskSensitiveInfo(s string) string {
 	// 只显示前4位和后4位
 	return s[:4] + "***" + s[len(s)-4:]
 }
+
+// RedactSecret 将文本中出现的密钥替换为脱敏形式（用于日志、告警等对外输出）
+func RedactSecret(text, secret string) string {
+	if secret == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, secret, MaskSensitiveInfo(secret))
+}
@@ -3,13 +3,23 @@ package monitor
 import (
 	"testing"
 
+	"monitor/internal/config"
 	"monitor/internal/storage"
 )
 
-func TestEvaluateStatusWithoutSuccessContains(t *testing.T) {
+// evaluateSuccessContains 按 success_contains 生成的断言校验响应体
+func evaluateSuccessContains(t *testing.T, body []byte, successContains string) (int, storage.SubStatus) {
+	t.Helper()
+
+	m := config.ServiceConfig{SuccessContains: successContains}
+	status, subStatus, _ := evaluateAssertions(1, storage.SubStatusNone, &responseSnapshot{StatusCode: 200, Body: body}, m.EffectiveAssertions())
+	return status, subStatus
+}
+
+func TestSuccessContainsWithoutSuccessContains(t *testing.T) {
 	t.Parallel()
 
-	status, subStatus := evaluateStatus(1, storage.SubStatusNone, []byte("anything"), "")
+	status, subStatus := evaluateSuccessContains(t, []byte("anything"), "")
 	if status != 1 {
 		t.Fatalf("expected status 1 when success_contains is empty, got %d", status)
 	}
@@ -18,11 +28,11 @@ func TestEvaluateStatusWithoutSuccessContains(t *testing.T) {
 	}
 }
 
-func TestEvaluateStatusWithMatchingContent(t *testing.T) {
+func TestSuccessContainsWithMatchingContent(t *testing.T) {
 	t.Parallel()
 
 	body := []byte(`{"ok":true,"message":"pong"}`)
-	status, subStatus := evaluateStatus(1, storage.SubStatusNone, body, "pong")
+	status, subStatus := evaluateSuccessContains(t, body, "pong")
 	if status != 1 {
 		t.Fatalf("expected status 1 when body contains keyword, got %d", status)
 	}
@@ -31,11 +41,11 @@ func TestEvaluateStatusWithMatchingContent(t *testing.T) {
 	}
 }
 
-func TestEvaluateStatusWithNonMatchingContent(t *testing.T) {
+func TestSuccessContainsWithNonMatchingContent(t *testing.T) {
 	t.Parallel()
 
 	body := []byte(`{"ok":false,"message":"error"}`)
-	status, subStatus := evaluateStatus(1, storage.SubStatusNone, body, "pong")
+	status, subStatus := evaluateSuccessContains(t, body, "pong")
 	if status != 0 {
 		t.Fatalf("expected status 0 when body does not contain keyword, got %d", status)
 	}
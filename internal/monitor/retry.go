@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// Probe 执行探测，红色结果按 cfg.Retry 重试后再记录（非绿色结果附带脱敏后的诊断信息）
+// 重试总耗时不超过监控项的巡检间隔，避免拖慢下一轮巡检
+func (p *Prober) Probe(ctx context.Context, cfg *config.ServiceConfig) *ProbeResult {
+	start := time.Now()
+	result := p.probeOnce(ctx, cfg)
+	attempts := 1
+
+	var deadline time.Time
+	if cfg.IntervalDuration > 0 {
+		deadline = start.Add(cfg.IntervalDuration)
+	}
+
+	var lastFailure *ProbeResult
+	backoff := cfg.Retry.BackoffDuration
+	for attempts <= cfg.Retry.Count && isRetryable(result) {
+		// 剩余时间不足以完成一次等待加完整超时，放弃重试
+		if !deadline.IsZero() && time.Now().Add(backoff+cfg.TimeoutDuration).After(deadline) {
+			log.Printf("[Probe] %s-%s-%s 剩余时间不足，放弃重试（已尝试 %d 次）", cfg.Provider, cfg.Service, cfg.Channel, attempts)
+			break
+		}
+		log.Printf("[Probe] %s-%s-%s 探测失败（%s），%v 后进行第 %d 次重试",
+			cfg.Provider, cfg.Service, cfg.Channel, result.SubStatus, backoff, attempts)
+		if !sleepContext(ctx, backoff) {
+			break
+		}
+
+		lastFailure = result
+		result = p.probeOnce(ctx, cfg)
+		attempts++
+		backoff *= 2
+	}
+
+	result.Attempts = attempts
+	result.Timestamp = start.Unix()
+
+	// 重试后成功：按配置记为黄色 flaky，并保留最近一次失败的诊断信息
+	if lastFailure != nil && result.Status == 1 && cfg.Retry.FlakyDegraded() {
+		result.Status = 2
+		result.SubStatus = storage.SubStatusFlaky
+		result.Error = lastFailure.Error
+		result.HTTPStatus = lastFailure.HTTPStatus
+		result.ErrorMessage = lastFailure.ErrorMessage
+		result.ResponseSnippet = lastFailure.ResponseSnippet
+	}
+	return result
+}
+
+// isRetryable 仅重试可能是偶发的失败（网络错误、5xx、流中断），认证/参数/内容等确定性错误重试无意义
+func isRetryable(result *ProbeResult) bool {
+	if result.Status != 0 {
+		return false
+	}
+	switch result.SubStatus {
+	case storage.SubStatusNetworkError, storage.SubStatusServerError, storage.SubStatusStreamError:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
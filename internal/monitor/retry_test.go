@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// newFlakyServer 前 failures 次请求返回 status，之后返回 200
+func newFlakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"upstream overloaded"}`))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestProbeRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	flaky := true
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		retry        config.RetryConfig
+		interval     time.Duration
+		wantStatus   int
+		wantSub      storage.SubStatus
+		wantAttempts int
+	}{
+		{"recovers on retry", 1, http.StatusBadGateway, config.RetryConfig{Count: 2}, 0, 1, storage.SubStatusNone, 2},
+		{"flaky as degraded", 1, http.StatusBadGateway, config.RetryConfig{Count: 2, FlakyAsDegraded: &flaky}, 0, 2, storage.SubStatusFlaky, 2},
+		{"retries exhausted", 5, http.StatusBadGateway, config.RetryConfig{Count: 2}, 0, 0, storage.SubStatusServerError, 3},
+		{"deterministic error", 1, http.StatusUnauthorized, config.RetryConfig{Count: 2}, 0, 0, storage.SubStatusAuthError, 1},
+		{"interval budget exhausted", 1, http.StatusBadGateway, config.RetryConfig{Count: 2}, 3 * time.Second, 0, storage.SubStatusServerError, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv, calls := newFlakyServer(t, tt.failures, tt.status)
+			p := NewProber(nil)
+			t.Cleanup(p.Close)
+
+			tt.retry.BackoffDuration = 10 * time.Millisecond
+			result := p.Probe(context.Background(), &config.ServiceConfig{
+				Provider: "demo", Service: "cc", URL: srv.URL, Method: "GET",
+				TimeoutDuration: 5 * time.Second, IntervalDuration: tt.interval, Retry: tt.retry,
+			})
+			if result.Status != tt.wantStatus || result.SubStatus != tt.wantSub || result.Attempts != tt.wantAttempts {
+				t.Fatalf("expected status=%d sub_status=%q attempts=%d, got status=%d sub_status=%q attempts=%d",
+					tt.wantStatus, tt.wantSub, tt.wantAttempts, result.Status, result.SubStatus, result.Attempts)
+			}
+			if int(calls.Load()) != tt.wantAttempts {
+				t.Fatalf("expected %d requests, got %d", tt.wantAttempts, calls.Load())
+			}
+			if tt.wantSub == storage.SubStatusFlaky && result.HTTPStatus != http.StatusBadGateway {
+				t.Fatalf("flaky result should keep the failed attempt's diagnostics: %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxStreamLineSize SSE 单行最大长度（超出视为流异常）
+const maxStreamLineSize = 1024 * 1024
+
+// streamResult 流式响应解析结果
+type streamResult struct {
+	FirstToken time.Duration // 首 token 延迟（从请求发出算起），未收到 token 时为 0
+	Duration   time.Duration // 流总耗时（从请求发出到流结束）
+	Completed  bool          // 是否收到结束标记
+	Body       []byte        // 原始响应内容（用于 success_contains 校验）
+	Err        error         // 读取过程中的错误（超时、连接中断等）
+}
+
+// readEventStream 读取并解析 text/event-stream 响应
+// start 为请求发出时间，doneMarker 为自定义结束标记（为空时使用内置规则）
+func readEventStream(r io.Reader, start time.Time, doneMarker string) *streamResult {
+	result := &streamResult{}
+	var body strings.Builder
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		body.WriteString(line)
+		body.WriteByte('\n')
+
+		// 空行表示一个事件结束
+		if line == "" {
+			event = ""
+			continue
+		}
+
+		// 注释行（如心跳 ": ping"）
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			if result.FirstToken == 0 && hasTokenContent(value) {
+				result.FirstToken = time.Since(start)
+			}
+			if isStreamDone(event, value, doneMarker) {
+				result.Completed = true
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		result.Err = fmt.Errorf("读取流式响应失败: %w", err)
+	}
+
+	result.Duration = time.Since(start)
+	result.Body = []byte(body.String())
+	return result
+}
+
+// isStreamDone 判断 data 行是否表示流正常结束
+func isStreamDone(event, data, doneMarker string) bool {
+	if doneMarker != "" {
+		return strings.Contains(data, doneMarker)
+	}
+
+	// OpenAI: data: [DONE]
+	if strings.TrimSpace(data) == "[DONE]" {
+		return true
+	}
+
+	// Anthropic: event: message_stop / {"type":"message_stop"}
+	if event == "message_stop" {
+		return true
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return false
+	}
+	if t, _ := payload["type"].(string); t == "message_stop" {
+		return true
+	}
+
+	// Gemini: 没有单独的结束事件，最后一个分片带 finishReason
+	if candidates, ok := payload["candidates"].([]any); ok {
+		for _, c := range candidates {
+			if m, ok := c.(map[string]any); ok {
+				if reason, _ := m["finishReason"].(string); reason != "" {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// hasTokenContent 判断 data 行是否携带模型输出的内容
+// 用于跳过 message_start、ping、role 声明等不含 token 的事件
+func hasTokenContent(data string) bool {
+	trimmed := strings.TrimSpace(data)
+	if trimmed == "" || trimmed == "[DONE]" {
+		return false
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		// 非 JSON 的 data 行视为内容
+		return true
+	}
+
+	// OpenAI: choices[].delta.content / choices[].text
+	if choices, ok := payload["choices"].([]any); ok {
+		for _, c := range choices {
+			m, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, _ := m["text"].(string); text != "" {
+				return true
+			}
+			if delta, ok := m["delta"].(map[string]any); ok {
+				if content, _ := delta["content"].(string); content != "" {
+					return true
+				}
+				if reasoning, _ := delta["reasoning_content"].(string); reasoning != "" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// Anthropic: content_block_delta 的 delta.text / delta.thinking
+	if t, _ := payload["type"].(string); t != "" {
+		if t != "content_block_delta" {
+			return false
+		}
+		delta, _ := payload["delta"].(map[string]any)
+		for _, key := range []string{"text", "thinking", "partial_json"} {
+			if v, _ := delta[key].(string); v != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Gemini: candidates[].content.parts[].text
+	if candidates, ok := payload["candidates"].([]any); ok {
+		for _, c := range candidates {
+			m, _ := c.(map[string]any)
+			content, _ := m["content"].(map[string]any)
+			parts, _ := content["parts"].([]any)
+			for _, p := range parts {
+				part, _ := p.(map[string]any)
+				if text, _ := part["text"].(string); text != "" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+func TestReadEventStreamOpenAI(t *testing.T) {
+	t.Parallel()
+
+	raw := strings.Join([]string{
+		`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"pong"}}]}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	result := readEventStream(strings.NewReader(raw), time.Now(), "")
+	if !result.Completed {
+		t.Fatalf("expected stream to be completed")
+	}
+	if result.FirstToken <= 0 {
+		t.Fatalf("expected first token latency to be recorded")
+	}
+	if !strings.Contains(string(result.Body), "pong") {
+		t.Fatalf("expected body to keep stream content, got %q", result.Body)
+	}
+}
+
+func TestReadEventStreamAnthropic(t *testing.T) {
+	t.Parallel()
+
+	raw := strings.Join([]string{
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"msg_1"}}`,
+		``,
+		`event: ping`,
+		`data: {"type":"ping"}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"hi"}}`,
+		``,
+		`event: message_stop`,
+		`data: {"type":"message_stop"}`,
+		``,
+	}, "\n")
+
+	result := readEventStream(strings.NewReader(raw), time.Now(), "")
+	if !result.Completed {
+		t.Fatalf("expected stream to be completed")
+	}
+	if result.FirstToken <= 0 {
+		t.Fatalf("expected first token latency to be recorded")
+	}
+}
+
+func TestReadEventStreamWithoutTerminator(t *testing.T) {
+	t.Parallel()
+
+	raw := `data: {"choices":[{"delta":{"content":"po"}}]}` + "\n\n"
+
+	result := readEventStream(strings.NewReader(raw), time.Now(), "")
+	if result.Completed {
+		t.Fatalf("expected stream without [DONE] to be incomplete")
+	}
+
+	status, subStatus := evaluateStream(1, storage.SubStatusNone, result, "text/event-stream")
+	if status != 0 || subStatus != storage.SubStatusStreamError {
+		t.Fatalf("expected red stream_error, got %d/%s", status, subStatus)
+	}
+}
+
+func TestEvaluateStreamRejectsNonSSE(t *testing.T) {
+	t.Parallel()
+
+	result := &streamResult{Completed: true}
+	status, subStatus := evaluateStream(1, storage.SubStatusNone, result, "application/json")
+	if status != 0 || subStatus != storage.SubStatusStreamError {
+		t.Fatalf("expected red stream_error for non-SSE response, got %d/%s", status, subStatus)
+	}
+}
+
+func TestReadEventStreamCustomDoneMarker(t *testing.T) {
+	t.Parallel()
+
+	raw := "data: hello\n\ndata: END-OF-STREAM\n\n"
+
+	result := readEventStream(strings.NewReader(raw), time.Now(), "END-OF-STREAM")
+	if !result.Completed {
+		t.Fatalf("expected custom done marker to complete the stream")
+	}
+}
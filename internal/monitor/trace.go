@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// phaseTimer 通过 httptrace 记录一次请求的网络阶段耗时
+// 回调可能在拨号协程中并发触发（如 Happy Eyeballs 同时尝试多个地址），需加锁
+type phaseTimer struct {
+	mu    sync.Mutex
+	start time.Time
+
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+
+	dns       time.Duration
+	connect   time.Duration
+	tls       time.Duration
+	firstByte time.Duration
+}
+
+// phaseDurations 网络阶段耗时快照
+type phaseDurations struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+}
+
+// newPhaseTimer 以 start 为请求起点创建计时器
+func newPhaseTimer(start time.Time) *phaseTimer {
+	return &phaseTimer{start: start}
+}
+
+// trace 生成 httptrace 回调（复用已有连接时不会触发 DNS/连接/TLS 回调，对应耗时为 0）
+func (t *phaseTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			if !t.dnsStart.IsZero() {
+				t.dns = time.Since(t.dnsStart)
+			}
+			t.mu.Unlock()
+		},
+		ConnectStart: func(_, _ string) {
+			t.mu.Lock()
+			// 多地址并发拨号时以最早的一次为准
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			if err == nil && t.connect == 0 && !t.connectStart.IsZero() {
+				t.connect = time.Since(t.connectStart)
+			}
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			if !t.tlsStart.IsZero() {
+				t.tls = time.Since(t.tlsStart)
+			}
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.firstByte = time.Since(t.start)
+			t.mu.Unlock()
+		},
+	}
+}
+
+// durations 返回当前已记录的阶段耗时
+func (t *phaseTimer) durations() phaseDurations {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return phaseDurations{DNS: t.dns, Connect: t.connect, TLS: t.tls, FirstByte: t.firstByte}
+}
+
+// phaseMillis 将阶段耗时转换为毫秒（不足 1ms 但确有耗时的记为 1，以便与"未发生"区分）
+func phaseMillis(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return max(int(d.Milliseconds()), 1)
+}
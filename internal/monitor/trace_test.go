@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+func TestPhaseTimerRecordsNewAndReusedConnections(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	client := srv.Client()
+
+	get := func() phaseDurations {
+		timer := newPhaseTimer(time.Now())
+		req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), timer.trace()), "GET", srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return timer.durations()
+	}
+
+	first := get()
+	if first.Connect <= 0 || first.TLS <= 0 || first.FirstByte <= 0 {
+		t.Fatalf("expected connect/TLS/first byte timings on new connection, got %+v", first)
+	}
+
+	// 复用连接时不会触发连接和 TLS 回调
+	second := get()
+	if second.Connect != 0 || second.TLS != 0 || second.FirstByte <= 0 {
+		t.Fatalf("expected only first byte timing on reused connection, got %+v", second)
+	}
+}
+
+func TestProbeRecordsBodyReadDuration(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("pong"))
+	}))
+	defer srv.Close()
+
+	p := NewProber(nil)
+	defer p.Close()
+
+	result := p.Probe(context.Background(), &config.ServiceConfig{
+		Provider: "demo", Service: "cc", URL: srv.URL, Method: "GET", TimeoutDuration: 5 * time.Second,
+	})
+	if result.Status != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.BodyReadDuration < 25 || result.ConnectDuration <= 0 || result.FirstByteLatency <= 0 {
+		t.Fatalf("unexpected phase timings: %+v", result)
+	}
+	if result.TLSDuration != 0 || result.DNSDuration != 0 {
+		t.Fatalf("plain HTTP to an IP literal should not record TLS/DNS: %+v", result)
+	}
+}
@@ -0,0 +1,177 @@
+// Package retention 按保留策略定期清理原始探测记录和聚合数据
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// Result 一次清理的结果
+type Result struct {
+	Raw      int64 // 删除的原始探测记录数
+	Hourly   int64 // 删除的小时聚合行数
+	Daily    int64 // 删除的天聚合行数
+	Duration time.Duration
+}
+
+// Total 删除的总行数
+func (r Result) Total() int64 {
+	return r.Raw + r.Hourly + r.Daily
+}
+
+// Cleaner 数据清理任务：启动时执行一次，之后按 retention.interval 定期执行
+type Cleaner struct {
+	store storage.Storage
+
+	mu      sync.Mutex
+	cfg     config.RetentionConfig
+	lastRun time.Time
+
+	// 唤醒清理循环（配置变更后重新计算下一次执行时间）
+	wake chan struct{}
+
+	// 主节点判定（可选，设置后只有主节点执行清理）
+	elector Elector
+}
+
+// Elector 多副本部署时的主节点判定
+type Elector interface {
+	IsLeader() bool
+	RenewInterval() time.Duration // 从节点重新检查角色的间隔
+}
+
+// NewCleaner 创建数据清理任务
+func NewCleaner(store storage.Storage) *Cleaner {
+	return &Cleaner{
+		store: store,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// SetElector 设置主节点判定（需在 Start 之前调用）
+func (c *Cleaner) SetElector(e Elector) {
+	c.elector = e
+}
+
+// Start 启动清理循环（立即执行一次）
+func (c *Cleaner) Start(ctx context.Context, cfg *config.AppConfig) {
+	c.mu.Lock()
+	c.cfg = cfg.Retention
+	c.mu.Unlock()
+
+	go c.loop(ctx)
+}
+
+// UpdateConfig 更新保留策略（热更新时调用），新的间隔从上一次清理时间起算
+func (c *Cleaner) UpdateConfig(cfg *config.AppConfig) {
+	c.mu.Lock()
+	changed := c.cfg != cfg.Retention
+	c.cfg = cfg.Retention
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	log.Printf("[Retention] 保留策略已更新: 原始记录 %s，小时聚合 %s，天聚合 %s，间隔 %v",
+		formatDays(cfg.Retention.RawDays), formatDays(cfg.Retention.HourlyDays),
+		formatDays(cfg.Retention.DailyDays), cfg.Retention.IntervalDuration)
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop 清理循环
+func (c *Cleaner) loop(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-c.wake:
+		}
+
+		c.mu.Lock()
+		next := c.lastRun.Add(c.cfg.IntervalDuration)
+		c.mu.Unlock()
+
+		if !time.Now().Before(next) && c.elector != nil && !c.elector.IsLeader() {
+			// 从节点跳过本轮清理且不记录执行时间，按续期间隔重新检查，成为主节点后立即补上到期的清理
+			next = time.Now().Add(c.elector.RenewInterval())
+		} else if !time.Now().Before(next) {
+			if _, err := c.RunOnce(); err != nil {
+				log.Printf("⚠️  [Retention] 清理旧数据失败: %v", err)
+			}
+			c.mu.Lock()
+			next = c.lastRun.Add(c.cfg.IntervalDuration)
+			c.mu.Unlock()
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// RunOnce 按当前保留策略执行一次清理（单个步骤失败不影响其余步骤）
+func (c *Cleaner) RunOnce() (Result, error) {
+	c.mu.Lock()
+	cfg := c.cfg
+	c.lastRun = time.Now()
+	c.mu.Unlock()
+
+	start := time.Now()
+	var result Result
+	var errs []error
+
+	if cfg.RawDays > 0 {
+		n, err := c.store.CleanOldRecords(cfg.RawDays)
+		result.Raw = n
+		errs = append(errs, err)
+	}
+	if cfg.HourlyDays > 0 {
+		n, err := c.store.CleanOldRollups(storage.RollupHourly, cfg.HourlyDays)
+		result.Hourly = n
+		errs = append(errs, err)
+	}
+	if cfg.DailyDays > 0 {
+		n, err := c.store.CleanOldRollups(storage.RollupDaily, cfg.DailyDays)
+		result.Daily = n
+		errs = append(errs, err)
+	}
+
+	// 没有删除数据时 VACUUM 无空间可回收，跳过
+	vacuum := cfg.Vacuum && result.Total() > 0
+	if vacuum || cfg.Analyze {
+		errs = append(errs, c.store.Optimize(vacuum, cfg.Analyze))
+	}
+
+	result.Duration = time.Since(start)
+	log.Printf("[Retention] 已清理原始记录 %d 条、小时聚合 %d 行、天聚合 %d 行，耗时 %v",
+		result.Raw, result.Hourly, result.Daily, result.Duration.Round(time.Millisecond))
+
+	return result, errors.Join(errs...)
+}
+
+// formatDays 保留天数的日志描述
+func formatDays(days int) string {
+	if days < 0 {
+		return "永久保留"
+	}
+	return fmt.Sprintf("%d 天", days)
+}
@@ -0,0 +1,109 @@
+package retention
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+func retentionConfig(t *testing.T, r config.RetentionConfig) *config.AppConfig {
+	t.Helper()
+
+	if err := r.Validate(); err != nil {
+		t.Fatalf("validate retention: %v", err)
+	}
+	if err := r.Normalize(); err != nil {
+		t.Fatalf("normalize retention: %v", err)
+	}
+	return &config.AppConfig{Retention: r}
+}
+
+func TestRunOnceAppliesPolicy(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewTestSQLite(t)
+	now := time.Now()
+	for _, age := range []int{0, 10, 40, 100, 400} {
+		r := &storage.ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Latency: 100,
+			Timestamp: now.AddDate(0, 0, -age).Unix()}
+		if err := store.SaveRecord(r); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+	}
+
+	cleaner := NewCleaner(store)
+	cleaner.cfg = retentionConfig(t, config.RetentionConfig{Vacuum: true, Analyze: true}).Retention
+
+	result, err := cleaner.RunOnce()
+	if err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	// 默认保留：原始 30 天、小时聚合 90 天、天聚合 365 天
+	if result.Raw != 3 || result.Hourly != 2 || result.Daily != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	history, err := store.GetHistory("demo", "cc", "", "", now.AddDate(-2, 0, 0), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 raw records left, got %d", len(history))
+	}
+
+	// 热更新：原始记录永久保留，天聚合缩短为 7 天
+	cleaner.UpdateConfig(retentionConfig(t, config.RetentionConfig{RawDays: -1, DailyDays: 7}))
+	result, err = cleaner.RunOnce()
+	if err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if result.Raw != 0 || result.Hourly != 0 || result.Daily != 3 {
+		t.Fatalf("unexpected result after update: %+v", result)
+	}
+}
+
+// fakeElector 可切换角色的主节点判定
+type fakeElector struct {
+	leader atomic.Bool
+}
+
+func (e *fakeElector) IsLeader() bool               { return e.leader.Load() }
+func (e *fakeElector) RenewInterval() time.Duration { return 10 * time.Millisecond }
+
+func TestFollowerRunsDueCleanupAfterPromotion(t *testing.T) {
+	t.Parallel()
+
+	elector := &fakeElector{}
+	cleaner := NewCleaner(storage.NewTestSQLite(t))
+	cleaner.SetElector(elector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cleaner.Start(ctx, retentionConfig(t, config.RetentionConfig{Interval: "24h"}))
+
+	lastRun := func() time.Time {
+		cleaner.mu.Lock()
+		defer cleaner.mu.Unlock()
+		return cleaner.lastRun
+	}
+
+	// 从节点跳过清理时不记录执行时间
+	time.Sleep(50 * time.Millisecond)
+	if !lastRun().IsZero() {
+		t.Fatalf("follower should not record a cleanup run")
+	}
+
+	// 成为主节点后在下一次检查时执行到期的清理，而不是等待完整的清理间隔
+	elector.leader.Store(true)
+	deadline := time.Now().Add(2 * time.Second)
+	for lastRun().IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatalf("cleanup did not run after promotion")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -2,20 +2,59 @@ package scheduler
 
 import (
 	"context"
+	"hash/fnv"
 	"log"
+	"math/rand/v2"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"monitor/internal/config"
 	"monitor/internal/monitor"
-	"monitor/internal/storage"
 )
 
-// Scheduler 调度器
+// defaultMaxConcurrentProbes 未配置 scheduler.max_concurrency 时同时进行的探测数量上限
+const defaultMaxConcurrentProbes = 10
+
+// idleWait 没有任何监控项时调度循环的等待时间
+const idleWait = time.Minute
+
+// Metrics 调度器运行指标接收者
+type Metrics interface {
+	// ObserveRound 记录一轮巡检（probes 个监控项）的耗时
+	ObserveRound(probes int, duration time.Duration)
+
+	// IncSkipped 记录一次因上一轮检查尚未完成而跳过的巡检
+	IncSkipped(cfg *config.ServiceConfig)
+}
+
+// Elector 多副本部署时的主节点判定
+type Elector interface {
+	// IsLeader 当前副本是否负责执行探测
+	IsLeader() bool
+}
+
+// Maintenance 计划维护判定
+type Maintenance interface {
+	// Active 返回监控项在 t 时刻所处的维护窗口（不在维护中时返回 nil）
+	Active(provider, service, channel string, t time.Time) *config.MaintenanceWindow
+}
+
+// task 单个监控项的调度状态
+type task struct {
+	cfg      config.ServiceConfig
+	interval time.Duration
+	slot     time.Time // 当前周期的基准执行时间（错峰偏移后、未加抖动）
+	nextRun  time.Time // 实际执行时间（slot 加随机抖动，手动触发时提前到当前时间）
+	inFlight bool      // 上一次探测尚未完成
+	paused   bool      // 已通过管理 API 暂停（仍推进调度进度，恢复后按原时间槽继续）
+}
+
+// Scheduler 调度器（每个监控项按自己的 interval 独立调度）
 type Scheduler struct {
 	prober   *monitor.Prober
-	interval time.Duration
-	ticker   *time.Ticker
+	interval time.Duration // 默认巡检间隔（监控项未解析出 interval 时使用）
 	running  bool
 	mu       sync.Mutex
 
@@ -23,22 +62,85 @@ type Scheduler struct {
 	cfg   *config.AppConfig
 	cfgMu sync.RWMutex
 
-	// 防止重复触发
-	checkInProgress bool
-	checkMu         sync.Mutex
+	// 调度状态（受 mu 保护），key 为 provider/service/channel
+	tasks map[string]*task
+
+	// 唤醒调度循环（配置变更或手动触发时重新计算下一次执行时间）
+	wake chan struct{}
+
+	// 错峰与抖动策略（受 mu 保护）
+	stagger bool
+	jitter  time.Duration
+
+	// 全局与按目标主机的并发限制（受 mu 保护，限制变更时重建，进行中的探测仍释放到旧的信号量）
+	sem      chan struct{}
+	perHost  int
+	hostSems map[string]chan struct{}
+
+	// 探测结果观察者（告警、指标等）
+	observers []monitor.ResultObserver
+
+	// 调度器运行指标（可选）
+	metrics Metrics
+
+	// 主节点判定（可选，设置后只有主节点执行探测，从节点仅推进调度进度）
+	elector Elector
+
+	// 计划维护（可选，维护中的监控项跳过探测或将结果记为维护状态）
+	maintenance Maintenance
 
 	// 保存context用于TriggerNow
 	ctx context.Context
 }
 
-// NewScheduler 创建调度器
-func NewScheduler(store storage.Storage, interval time.Duration) *Scheduler {
+// NewScheduler 创建调度器（sink 为探测结果的保存目标，通常为存储）
+func NewScheduler(sink monitor.ResultSink, interval time.Duration) *Scheduler {
 	return &Scheduler{
-		prober:   monitor.NewProber(store),
+		prober:   monitor.NewProber(sink),
 		interval: interval,
+		tasks:    make(map[string]*task),
+		wake:     make(chan struct{}, 1),
+		sem:      make(chan struct{}, defaultMaxConcurrentProbes),
+		hostSems: make(map[string]chan struct{}),
+	}
+}
+
+// AddObserver 注册探测结果观察者（需在 Start 之前调用）
+func (s *Scheduler) AddObserver(o monitor.ResultObserver) {
+	s.observers = append(s.observers, o)
+}
+
+// OnProbeResult 将调度器之外产生的探测结果（探测节点上报）分发给已注册的观察者（实现 monitor.ResultObserver）
+// 开启选举时与本地探测一致，只有主节点分发，避免多个副本重复告警
+func (s *Scheduler) OnProbeResult(cfg *config.ServiceConfig, result *monitor.ProbeResult) {
+	if !s.IsLeader() {
+		return
+	}
+	for _, o := range s.observers {
+		o.OnProbeResult(cfg, result)
 	}
 }
 
+// SetMetrics 设置调度器运行指标接收者（需在 Start 之前调用）
+func (s *Scheduler) SetMetrics(m Metrics) {
+	s.metrics = m
+}
+
+// SetElector 设置主节点判定（需在 Start 之前调用）
+func (s *Scheduler) SetElector(e Elector) {
+	s.elector = e
+}
+
+// IsLeader 当前副本是否执行探测（未开启选举时始终为 true）
+func (s *Scheduler) IsLeader() bool {
+	return s.elector == nil || s.elector.IsLeader()
+}
+
+// SetMaintenance 设置计划维护判定（需在 Start 之前调用）
+func (s *Scheduler) SetMaintenance(m Maintenance) {
+	s.maintenance = m
+}
+
 // Start 启动调度器
 func (s *Scheduler) Start(ctx context.Context, cfg *config.AppConfig) {
 	s.mu.Lock()
@@ -47,78 +149,125 @@ func (s *Scheduler) Start(ctx context.Context, cfg *config.AppConfig) {
 		return
 	}
 	s.running = true
-	s.ticker = time.NewTicker(s.interval)
 	s.ctx = ctx // 保存context用于TriggerNow
-
-	// 保存初始配置
-	s.cfgMu.Lock()
-	s.cfg = cfg
-	s.cfgMu.Unlock()
 	s.mu.Unlock()
 
-	// 立即执行一次
-	go s.runChecks(ctx)
+	// 保存初始配置（新监控项会立即执行一次）
+	s.UpdateConfig(cfg)
 
-	// 定时执行
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				log.Println("[Scheduler] 调度器已停止")
-				s.mu.Lock()
-				s.ticker.Stop()
-				s.running = false
-				s.mu.Unlock()
-				return
+	go s.loop(ctx)
 
-			case <-s.ticker.C:
-				s.runChecks(ctx)
-			}
+	log.Printf("[Scheduler] 调度器已启动，默认间隔: %v", s.interval)
+}
+
+// loop 调度循环：执行到期的监控项，然后等待下一个最早到期的时间点
+func (s *Scheduler) loop(ctx context.Context) {
+	timer := time.NewTimer(idleWait)
+	defer timer.Stop()
+
+	for {
+		due, next := s.collectDue(time.Now())
+		if len(due) > 0 {
+			go s.runTasks(ctx, due)
 		}
-	}()
 
-	log.Printf("[Scheduler] 调度器已启动，间隔: %v", s.interval)
+		wait := idleWait
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Scheduler] 调度器已停止")
+			s.mu.Lock()
+			s.running = false
+			s.mu.Unlock()
+			return
+		case <-timer.C:
+		case <-s.wake:
+		}
+	}
 }
 
-// runChecks 执行所有检查（防重复）
-func (s *Scheduler) runChecks(ctx context.Context) {
-	// 防止重复执行
-	s.checkMu.Lock()
-	if s.checkInProgress {
-		log.Println("[Scheduler] 上一轮检查尚未完成，跳过本次")
-		s.checkMu.Unlock()
-		return
-	}
-	s.checkInProgress = true
-	s.checkMu.Unlock()
+// collectDue 取出所有到期的监控项，并计算下一个最早到期时间
+func (s *Scheduler) collectDue(now time.Time) ([]config.ServiceConfig, time.Time) {
+	leader := s.IsLeader()
 
-	defer func() {
-		s.checkMu.Lock()
-		s.checkInProgress = false
-		s.checkMu.Unlock()
-	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// 获取当前配置（支持热更新）
-	s.cfgMu.RLock()
-	cfg := s.cfg
-	s.cfgMu.RUnlock()
+	var due []config.ServiceConfig
+	var next time.Time
+	for key, t := range s.tasks {
+		if !t.nextRun.After(now) {
+			// 跳过已错过的周期，保持固定节奏（以未加抖动的 slot 推进，避免抖动累积导致相位漂移）
+			for !t.slot.After(now) {
+				t.slot = t.slot.Add(t.interval)
+			}
+			t.nextRun = t.slot.Add(s.randomJitter(t.interval))
+
+			switch {
+			case !leader:
+				// 从节点只推进调度进度，与主节点保持相同节奏，接管后按原时间槽继续执行
+			case t.paused:
+				// 已暂停的监控项同样只推进调度进度
+			case t.inFlight:
+				log.Printf("[Scheduler] %s 上一轮检查尚未完成，跳过本次", key)
+				if s.metrics != nil {
+					s.metrics.IncSkipped(&t.cfg)
+				}
+			default:
+				t.inFlight = true
+				due = append(due, t.cfg)
+			}
+		}
 
-	if cfg == nil || len(cfg.Monitors) == 0 {
-		return
+		if next.IsZero() || t.nextRun.Before(next) {
+			next = t.nextRun
+		}
 	}
 
-	log.Printf("[Scheduler] 开始巡检 %d 个监控项", len(cfg.Monitors))
+	return due, next
+}
 
-	var wg sync.WaitGroup
-	// 限制并发数
-	sem := make(chan struct{}, 10)
+// runTasks 并发执行一批监控项的探测
+func (s *Scheduler) runTasks(ctx context.Context, tasks []config.ServiceConfig) {
+	log.Printf("[Scheduler] 开始巡检 %d 个监控项", len(tasks))
+	start := time.Now()
 
-	for _, task := range cfg.Monitors {
+	var wg sync.WaitGroup
+	for _, t := range tasks {
 		wg.Add(1)
 		go func(t config.ServiceConfig) {
 			defer wg.Done()
+			defer s.finishTask(t)
+
+			var window *config.MaintenanceWindow
+			if s.maintenance != nil {
+				window = s.maintenance.Active(t.Provider, t.Service, t.Channel, time.Now())
+			}
+			if window != nil && window.Mode == config.MaintenanceModeSkip {
+				log.Printf("[Scheduler] %s 处于计划维护中，跳过探测", taskKey(&t))
+				return
+			}
 
-			// 获取信号量
+			// 先获取目标主机的信号量，再占用全局并发槽位，避免等待同一主机时占住全局槽位
+			sem, hostSem := s.semaphores(&t)
+			if hostSem != nil {
+				select {
+				case hostSem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				defer func() { <-hostSem }()
+			}
 			select {
 			case sem <- struct{}{}:
 			case <-ctx.Done():
@@ -128,51 +277,164 @@ func (s *Scheduler) runChecks(ctx context.Context) {
 
 			// 执行探测
 			result := s.prober.Probe(ctx, &t)
+			if window != nil {
+				// 维护期间的结果记为维护状态（保留原始细分状态和诊断信息），不计入可用率、不触发告警
+				result.Status = 4
+			}
 
 			// 保存结果
 			if err := s.prober.SaveResult(result); err != nil {
 				log.Printf("[Scheduler] 保存结果失败 %s-%s-%s: %v",
 					t.Provider, t.Service, t.Channel, err)
 			}
-		}(task)
+
+			// 通知观察者
+			for _, o := range s.observers {
+				o.OnProbeResult(&t, result)
+			}
+		}(t)
 	}
 
 	wg.Wait()
+	if s.metrics != nil {
+		s.metrics.ObserveRound(len(tasks), time.Since(start))
+	}
 	log.Println("[Scheduler] 巡检完成")
 }
 
+// finishTask 标记监控项本次探测已完成
+func (s *Scheduler) finishTask(cfg config.ServiceConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tasks[taskKey(&cfg)]; ok {
+		t.inFlight = false
+	}
+}
+
 // UpdateConfig 更新配置（热更新时调用）
+// 新增的监控项立即执行，已有监控项保留调度进度，间隔缩短时提前下一次执行
 func (s *Scheduler) UpdateConfig(cfg *config.AppConfig) {
 	s.cfgMu.Lock()
 	s.cfg = cfg
 	s.cfgMu.Unlock()
+	s.prober.UpdateConfig(cfg)
+
+	now := time.Now()
 
-	// 如果配置中带有新的巡检间隔，动态调整 ticker
-	if cfg.IntervalDuration > 0 {
-		s.mu.Lock()
-		if s.interval != cfg.IntervalDuration {
-			s.interval = cfg.IntervalDuration
-			if s.ticker != nil {
-				s.ticker.Reset(s.interval)
-				log.Printf("[Scheduler] 巡检间隔已更新为: %v", s.interval)
+	s.mu.Lock()
+	if cfg.IntervalDuration > 0 && s.interval != cfg.IntervalDuration {
+		s.interval = cfg.IntervalDuration
+		log.Printf("[Scheduler] 默认巡检间隔已更新为: %v", s.interval)
+	}
+	s.applySchedulerConfig(&cfg.Scheduler)
+
+	next := make(map[string]*task, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		key := taskKey(&m)
+		if _, dup := next[key]; dup {
+			continue
+		}
+
+		interval := m.IntervalDuration
+		if interval <= 0 {
+			interval = s.interval
+		}
+
+		if existing, ok := s.tasks[key]; ok {
+			existing.cfg = m
+			if interval < existing.interval {
+				earliest := now.Add(interval)
+				if s.stagger {
+					earliest = staggerSlot(now, interval, staggerOffset(key, interval))
+				}
+				if existing.slot.After(earliest) {
+					existing.slot = earliest
+					existing.nextRun = earliest.Add(s.randomJitter(interval))
+				}
 			}
+			existing.interval = interval
+			next[key] = existing
+			continue
 		}
-		s.mu.Unlock()
+
+		// 新监控项：开启错峰时在属于自己的时间槽执行，否则立即执行
+		slot := now
+		if s.stagger {
+			slot = staggerSlot(now, interval, staggerOffset(key, interval))
+		}
+		next[key] = &task{cfg: m, interval: interval, slot: slot, nextRun: slot.Add(s.randomJitter(interval))}
 	}
+	s.tasks = next
+	s.mu.Unlock()
 
-	log.Printf("[Scheduler] 配置已更新，下次巡检将使用新配置")
+	s.notify()
+	log.Printf("[Scheduler] 配置已更新，共 %d 个监控项", len(next))
 }
 
-// TriggerNow 立即触发一次巡检（热更新后调用）
-func (s *Scheduler) TriggerNow() {
+// TriggerNow 立即触发一次巡检（热更新后调用），指定 monitors 时只触发这些监控项
+// 已暂停的监控项不会被触发，从节点不执行探测，返回实际触发的监控项数量
+func (s *Scheduler) TriggerNow(monitors ...*config.ServiceConfig) int {
+	if !s.IsLeader() {
+		return 0
+	}
 	s.mu.Lock()
 	running := s.running
 	ctx := s.ctx
+	triggered := 0
+	if running && ctx != nil {
+		now := time.Now()
+		trigger := func(t *task) {
+			if t != nil && !t.paused {
+				t.nextRun = now
+				triggered++
+			}
+		}
+		if len(monitors) == 0 {
+			for _, t := range s.tasks {
+				trigger(t)
+			}
+		}
+		for _, m := range monitors {
+			trigger(s.tasks[taskKey(m)])
+		}
+	}
 	s.mu.Unlock()
 
-	if running && ctx != nil {
-		go s.runChecks(ctx)
-		log.Printf("[Scheduler] 已触发即时巡检")
+	if triggered > 0 {
+		s.notify()
+		log.Printf("[Scheduler] 已触发即时巡检，%d 个监控项", triggered)
+	}
+	return triggered
+}
+
+// SetPaused 暂停或恢复监控项的探测（暂停状态保存在内存中，随配置热更新保留），监控项不存在时返回 false
+func (s *Scheduler) SetPaused(m *config.ServiceConfig, paused bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[taskKey(m)]
+	if !ok {
+		return false
+	}
+	t.paused = paused
+	return true
+}
+
+// Paused 监控项是否已暂停
+func (s *Scheduler) Paused(m *config.ServiceConfig) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[taskKey(m)]
+	return ok && t.paused
+}
+
+// notify 唤醒调度循环（非阻塞）
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
 	}
 }
 
@@ -181,10 +443,89 @@ func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.running && s.ticker != nil {
-		s.ticker.Stop()
-		s.running = false
+	s.running = false
+	s.prober.Close()
+}
+
+// applySchedulerConfig 应用错峰、抖动与并发限制配置（调用方需持有 mu）
+func (s *Scheduler) applySchedulerConfig(sc *config.SchedulerConfig) {
+	s.stagger = sc.StaggerEnabled()
+	s.jitter = sc.JitterDuration
+
+	limit := sc.MaxConcurrency
+	if limit <= 0 {
+		limit = defaultMaxConcurrentProbes
+	}
+	if cap(s.sem) != limit {
+		s.sem = make(chan struct{}, limit)
+		log.Printf("[Scheduler] 全局并发上限已更新为: %d", limit)
 	}
+	if s.perHost != sc.MaxConcurrencyPerHost {
+		s.perHost = sc.MaxConcurrencyPerHost
+		s.hostSems = make(map[string]chan struct{})
+		log.Printf("[Scheduler] 单主机并发上限已更新为: %d", s.perHost)
+	}
+}
 
-	s.prober.Close()
+// semaphores 返回全局信号量及监控项目标主机的信号量（未限制单主机并发时为 nil）
+func (s *Scheduler) semaphores(cfg *config.ServiceConfig) (chan struct{}, chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.perHost <= 0 {
+		return s.sem, nil
+	}
+	host := hostKey(cfg.URL)
+	hostSem, ok := s.hostSems[host]
+	if !ok {
+		hostSem = make(chan struct{}, s.perHost)
+		s.hostSems[host] = hostSem
+	}
+	return s.sem, hostSem
+}
+
+// randomJitter 返回 [0, jitter) 内的随机延迟，上限不超过 interval 的一半（调用方需持有 mu）
+func (s *Scheduler) randomJitter(interval time.Duration) time.Duration {
+	jitter := min(s.jitter, interval/2)
+	if jitter <= 0 {
+		return 0
+	}
+	return rand.N(jitter)
+}
+
+// staggerOffset 监控项在巡检间隔内的固定偏移（由 key 哈希得出，重启或多实例间保持一致）
+func staggerOffset(key string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// staggerSlot 返回不早于 now 的第一个时间槽（Unix 纳秒时间减去 offset 后是 interval 的整数倍）
+func staggerSlot(now time.Time, interval, offset time.Duration) time.Time {
+	if interval <= 0 {
+		return now
+	}
+	n, iv := now.UnixNano(), int64(interval)
+	slot := n - ((n-int64(offset))%iv+iv)%iv
+	if slot < n {
+		slot += iv
+	}
+	return time.Unix(0, slot)
+}
+
+// hostKey 监控项目标主机（host:port，解析失败时使用原始 URL）
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Host)
+}
+
+// taskKey 监控项唯一标识
+func taskKey(m *config.ServiceConfig) string {
+	return m.Provider + "/" + m.Service + "/" + m.Channel
 }
@@ -0,0 +1,366 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/election"
+	"monitor/internal/maintenance"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+func TestCollectDueUsesPerMonitorInterval(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(nil, time.Minute)
+	s.UpdateConfig(&config.AppConfig{
+		IntervalDuration: time.Minute,
+		Monitors: []config.ServiceConfig{
+			{Provider: "fast", Service: "cc", IntervalDuration: 15 * time.Second},
+			{Provider: "slow", Service: "cc"},
+		},
+	})
+
+	start := time.Now()
+	due, next := s.collectDue(start)
+	if len(due) != 2 {
+		t.Fatalf("expected both monitors to run immediately, got %d", len(due))
+	}
+	if want := start.Add(15 * time.Second); next.After(want) {
+		t.Fatalf("expected next run within 15s, got %v", next.Sub(start))
+	}
+	for _, m := range due {
+		s.finishTask(m)
+	}
+
+	due, _ = s.collectDue(start.Add(20 * time.Second))
+	if len(due) != 1 || due[0].Provider != "fast" {
+		t.Fatalf("expected only the fast monitor to be due after 20s, got %+v", due)
+	}
+
+	// 上一次探测未完成时跳过本次
+	due, _ = s.collectDue(start.Add(35 * time.Second))
+	if len(due) != 0 {
+		t.Fatalf("expected in-flight monitor to be skipped, got %+v", due)
+	}
+}
+
+func TestUpdateConfigKeepsScheduleForExistingMonitors(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(nil, time.Minute)
+	cfg := &config.AppConfig{
+		IntervalDuration: time.Minute,
+		Monitors:         []config.ServiceConfig{{Provider: "a", Service: "cc", IntervalDuration: time.Minute}},
+	}
+	s.UpdateConfig(cfg)
+
+	now := time.Now()
+	due, _ := s.collectDue(now)
+	for _, m := range due {
+		s.finishTask(m)
+	}
+
+	cfg = &config.AppConfig{
+		IntervalDuration: time.Minute,
+		Monitors: []config.ServiceConfig{
+			{Provider: "a", Service: "cc", IntervalDuration: time.Minute},
+			{Provider: "b", Service: "cc", IntervalDuration: time.Minute},
+		},
+	}
+	s.UpdateConfig(cfg)
+
+	due, _ = s.collectDue(time.Now())
+	if len(due) != 1 || due[0].Provider != "b" {
+		t.Fatalf("expected only the newly added monitor to run, got %+v", due)
+	}
+}
+
+func TestStaggerSpreadsMonitorsAcrossInterval(t *testing.T) {
+	t.Parallel()
+
+	stagger := true
+	s := NewScheduler(nil, time.Minute)
+	cfg := &config.AppConfig{IntervalDuration: time.Minute, Scheduler: config.SchedulerConfig{Stagger: &stagger}}
+	for i := 0; i < 60; i++ {
+		cfg.Monitors = append(cfg.Monitors, config.ServiceConfig{
+			Provider: "relay", Service: fmt.Sprintf("svc-%d", i), IntervalDuration: time.Minute,
+		})
+	}
+	s.UpdateConfig(cfg)
+
+	now := time.Now()
+	seconds := make(map[int64]int)
+	for key, task := range s.tasks {
+		if task.slot.Before(now) || !task.slot.Before(now.Add(time.Minute)) {
+			t.Fatalf("%s: first slot %v outside the next interval", key, task.slot.Sub(now))
+		}
+		offset := staggerOffset(key, time.Minute)
+		if (task.slot.UnixNano()-int64(offset))%int64(time.Minute) != 0 {
+			t.Fatalf("%s: slot not aligned to its offset", key)
+		}
+		seconds[task.slot.Unix()]++
+	}
+	if len(seconds) < 20 {
+		t.Fatalf("expected monitors spread across the interval, got %d distinct seconds", len(seconds))
+	}
+
+	// 偏移由 key 决定，重启后保持不变
+	if staggerOffset("relay/svc-1/", time.Minute) != staggerOffset("relay/svc-1/", time.Minute) {
+		t.Fatal("stagger offset should be deterministic")
+	}
+
+	// 执行后按 interval 推进，保持相位
+	task := s.tasks["relay/svc-0/"]
+	slot := task.slot
+	if due, _ := s.collectDue(slot); len(due) == 0 {
+		t.Fatal("expected monitor to be due at its slot")
+	}
+	if !task.slot.Equal(slot.Add(time.Minute)) {
+		t.Fatalf("expected next slot one interval later, got %v", task.slot.Sub(slot))
+	}
+}
+
+func TestJitterAndPerHostLimit(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(nil, time.Minute)
+	s.UpdateConfig(&config.AppConfig{
+		IntervalDuration: time.Minute,
+		Scheduler:        config.SchedulerConfig{JitterDuration: time.Hour, MaxConcurrency: 3, MaxConcurrencyPerHost: 2},
+	})
+
+	for i := 0; i < 100; i++ {
+		if j := s.randomJitter(10 * time.Second); j < 0 || j >= 5*time.Second {
+			t.Fatalf("jitter %v exceeds half of the interval", j)
+		}
+	}
+
+	a := &config.ServiceConfig{URL: "https://API.relay.test/v1/chat"}
+	b := &config.ServiceConfig{URL: "https://api.relay.test/v1/models"}
+	c := &config.ServiceConfig{URL: "https://other.test/v1/chat"}
+	sem, hostA := s.semaphores(a)
+	_, hostB := s.semaphores(b)
+	_, hostC := s.semaphores(c)
+	if cap(sem) != 3 || cap(hostA) != 2 || hostA != hostB || hostA == hostC {
+		t.Fatalf("unexpected semaphores: global=%d hostA=%d shared=%v", cap(sem), cap(hostA), hostA == hostB)
+	}
+
+	// 关闭单主机限制
+	s.UpdateConfig(&config.AppConfig{IntervalDuration: time.Minute})
+	if sem, host := s.semaphores(a); cap(sem) != defaultMaxConcurrentProbes || host != nil {
+		t.Fatalf("expected default global limit without per-host limit, got %d %v", cap(sem), host)
+	}
+}
+
+func TestOnlyLeaderDispatchesProbes(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "monitor.db")
+	cfg := &config.AppConfig{
+		IntervalDuration: time.Minute,
+		Monitors: []config.ServiceConfig{
+			{Provider: "a", Service: "cc", IntervalDuration: time.Minute},
+			{Provider: "b", Service: "cc", IntervalDuration: time.Minute},
+		},
+	}
+
+	// 两个副本共享同一个数据库
+	var schedulers []*Scheduler
+	var electors []*election.Elector
+	for _, id := range []string{"replica-1", "replica-2"} {
+		store, err := storage.NewSQLiteStorage(path)
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		if err := store.Init(); err != nil {
+			t.Fatalf("init sqlite: %v", err)
+		}
+		e := election.NewElector(store, id, time.Minute)
+		e.Tick()
+		s := NewScheduler(store, time.Minute)
+		s.SetElector(e)
+		s.UpdateConfig(cfg)
+		schedulers = append(schedulers, s)
+		electors = append(electors, e)
+	}
+
+	now := time.Now()
+	leaderDue, _ := schedulers[0].collectDue(now)
+	followerDue, _ := schedulers[1].collectDue(now)
+	if len(leaderDue) != 2 || len(followerDue) != 0 {
+		t.Fatalf("expected only the leader to probe, got leader=%d follower=%d", len(leaderDue), len(followerDue))
+	}
+
+	// 主节点退出后，从节点在下一个周期接管
+	electors[0].Release()
+	if !electors[1].Tick() {
+		t.Fatalf("follower should take over the released lease")
+	}
+	next := now.Add(time.Minute)
+	if due, _ := schedulers[0].collectDue(next); len(due) != 0 {
+		t.Fatalf("former leader still dispatched %d probes", len(due))
+	}
+	if due, _ := schedulers[1].collectDue(next); len(due) != 2 {
+		t.Fatalf("new leader dispatched %d probes, want 2", len(due))
+	}
+
+	// 从节点不响应即时触发（管理 API 据此提示请求主节点）
+	for _, s := range schedulers {
+		s.mu.Lock()
+		s.running, s.ctx = true, context.Background()
+		s.mu.Unlock()
+	}
+	if n := schedulers[0].TriggerNow(&cfg.Monitors[0]); n != 0 || schedulers[0].IsLeader() {
+		t.Fatalf("follower triggered %d probes", n)
+	}
+	if n := schedulers[1].TriggerNow(&cfg.Monitors[0]); n != 1 || !schedulers[1].IsLeader() {
+		t.Fatalf("leader triggered %d probes, want 1", n)
+	}
+
+	// 探测节点上报的结果同样只由主节点通知观察者
+	observers := make([]*countingObserver, len(schedulers))
+	for i, s := range schedulers {
+		observers[i] = &countingObserver{}
+		s.AddObserver(observers[i])
+		s.OnProbeResult(&cfg.Monitors[0], &monitor.ProbeResult{Status: 0, Region: "shanghai"})
+	}
+	if observers[0].n != 0 || observers[1].n != 1 {
+		t.Fatalf("expected only the leader to notify observers, got follower=%d leader=%d", observers[0].n, observers[1].n)
+	}
+}
+
+// countingObserver 统计收到的探测结果数量
+type countingObserver struct{ n int }
+
+func (o *countingObserver) OnProbeResult(*config.ServiceConfig, *monitor.ProbeResult) { o.n++ }
+
+func TestPausedMonitorsSkipProbesAndTargetedTrigger(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(nil, time.Minute)
+	a := config.ServiceConfig{Provider: "a", Service: "cc", IntervalDuration: time.Minute}
+	b := config.ServiceConfig{Provider: "b", Service: "cc", IntervalDuration: time.Minute}
+	s.UpdateConfig(&config.AppConfig{IntervalDuration: time.Minute, Monitors: []config.ServiceConfig{a, b}})
+
+	if !s.SetPaused(&a, true) || !s.Paused(&a) {
+		t.Fatal("expected monitor a to be paused")
+	}
+	if s.SetPaused(&config.ServiceConfig{Provider: "unknown", Service: "cc"}, true) {
+		t.Fatal("pausing an unknown monitor should fail")
+	}
+
+	now := time.Now()
+	due, _ := s.collectDue(now)
+	if len(due) != 1 || due[0].Provider != "b" {
+		t.Fatalf("expected only the unpaused monitor to run, got %+v", due)
+	}
+	s.finishTask(due[0])
+
+	// 暂停状态在配置热更新后保留
+	s.UpdateConfig(&config.AppConfig{IntervalDuration: time.Minute, Monitors: []config.ServiceConfig{a, b}})
+	if !s.Paused(&a) {
+		t.Fatal("pause should survive config reload")
+	}
+
+	// 调度器未运行时不触发
+	if n := s.TriggerNow(&b); n != 0 {
+		t.Fatalf("expected no trigger before start, got %d", n)
+	}
+	s.mu.Lock()
+	s.running, s.ctx = true, context.Background()
+	s.mu.Unlock()
+
+	if n := s.TriggerNow(&a); n != 0 {
+		t.Fatalf("paused monitor should not be triggered, got %d", n)
+	}
+	if n := s.TriggerNow(&b); n != 1 {
+		t.Fatalf("expected targeted trigger of b, got %d", n)
+	}
+	if n := s.TriggerNow(); n != 1 {
+		t.Fatalf("expected full trigger to skip paused monitors, got %d", n)
+	}
+
+	s.SetPaused(&a, false)
+	due, _ = s.collectDue(time.Now())
+	if len(due) != 1 || due[0].Provider != "b" {
+		t.Fatalf("expected only the triggered monitor to run, got %+v", due)
+	}
+}
+
+// recordingSink 记录保存的探测结果
+type recordingSink struct {
+	mu      sync.Mutex
+	records []*storage.ProbeRecord
+}
+
+func (s *recordingSink) SaveRecord(r *storage.ProbeRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, r)
+	return nil
+}
+
+func (s *recordingSink) SaveCertificate(*storage.Certificate) error { return nil }
+
+func TestMaintenanceWindowsSkipOrMarkProbes(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	requested := make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path] = true
+		mu.Unlock()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	monitor := func(provider string) config.ServiceConfig {
+		return config.ServiceConfig{Provider: provider, Service: "cc", URL: srv.URL + "/" + provider, Method: "GET", TimeoutDuration: 5 * time.Second}
+	}
+	cfg := &config.AppConfig{
+		IntervalDuration: time.Minute,
+		Monitors:         []config.ServiceConfig{monitor("record"), monitor("skip"), monitor("normal")},
+		Maintenance: config.MaintenanceConfig{Windows: []config.MaintenanceWindow{
+			{Provider: "record", Start: now.Add(-time.Hour).Format(time.RFC3339), End: now.Add(time.Hour).Format(time.RFC3339)},
+			{Provider: "skip", Start: now.Add(-time.Hour).Format(time.RFC3339), End: now.Add(time.Hour).Format(time.RFC3339), Mode: config.MaintenanceModeSkip},
+			{Provider: "normal", Start: now.Add(time.Hour).Format(time.RFC3339), End: now.Add(2 * time.Hour).Format(time.RFC3339)},
+		}},
+	}
+	if err := cfg.Maintenance.Normalize(); err != nil {
+		t.Fatalf("normalize maintenance: %v", err)
+	}
+
+	calendar := maintenance.NewCalendar(nil)
+	calendar.UpdateConfig(cfg)
+	sink := &recordingSink{}
+	s := NewScheduler(sink, time.Minute)
+	s.SetMaintenance(calendar)
+	s.UpdateConfig(cfg)
+	s.runTasks(context.Background(), cfg.Monitors)
+
+	got := make(map[string]*storage.ProbeRecord)
+	for _, r := range sink.records {
+		got[r.Provider] = r
+	}
+	if r := got["record"]; r == nil || r.Status != 4 || r.SubStatus != storage.SubStatusServerError {
+		t.Fatalf("expected maintenance record keeping the original sub status, got %+v", r)
+	}
+	if _, ok := got["skip"]; ok || requested["/skip"] {
+		t.Fatal("skip mode should not probe or record")
+	}
+	if r := got["normal"]; r == nil || r.Status != 0 {
+		t.Fatalf("monitor outside the window should be probed normally, got %+v", r)
+	}
+}
@@ -0,0 +1,106 @@
+// Package secret 敏感配置的加密：AES-256-GCM，主密钥来自环境变量 MONITOR_MASTER_KEY
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MasterKeyEnv 主密钥环境变量（base64 编码的 32 字节，可用 openssl rand -base64 32 生成）
+const MasterKeyEnv = "MONITOR_MASTER_KEY"
+
+// encryptedPrefix 加密值前缀（v1 为 AES-256-GCM，随机 12 字节 nonce 置于密文之前）
+const encryptedPrefix = "enc:v1:"
+
+// keySize 主密钥长度（AES-256）
+const keySize = 32
+
+// ErrNoMasterKey 未设置主密钥
+var ErrNoMasterKey = errors.New("未设置主密钥环境变量 " + MasterKeyEnv)
+
+// ParseKey 解析 base64 编码的主密钥
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%s 不是有效的 base64: %w", MasterKeyEnv, err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("%s 长度必须为 %d 字节，当前为 %d 字节", MasterKeyEnv, keySize, len(key))
+	}
+	return key, nil
+}
+
+// KeyFromEnv 读取主密钥（未设置时返回 ErrNoMasterKey）
+func KeyFromEnv() ([]byte, error) {
+	encoded := os.Getenv(MasterKeyEnv)
+	if encoded == "" {
+		return nil, ErrNoMasterKey
+	}
+	return ParseKey(encoded)
+}
+
+// IsEncrypted 值是否为加密格式
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt 加密明文，返回 enc:v1:<base64> 格式（空字符串原样返回）
+func Encrypt(key []byte, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成 nonce 失败: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 Encrypt 生成的值（空字符串原样返回）
+func Decrypt(key []byte, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("不是加密值（缺少 %s 前缀）", encryptedPrefix)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("加密值不是有效的 base64: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("加密值长度无效")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("解密失败（主密钥不匹配或数据已损坏）")
+	}
+	return string(plaintext), nil
+}
+
+// newAEAD 由主密钥创建 AES-GCM
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("主密钥长度必须为 %d 字节", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,48 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if err != nil {
+		t.Fatalf("parse key: %v", err)
+	}
+
+	sealed, err := Encrypt(key, "sk-secret")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !IsEncrypted(sealed) || strings.Contains(sealed, "sk-secret") {
+		t.Fatalf("unexpected encrypted value: %s", sealed)
+	}
+	if again, _ := Encrypt(key, "sk-secret"); again == sealed {
+		t.Fatal("expected a random nonce per encryption")
+	}
+
+	plain, err := Decrypt(key, sealed)
+	if err != nil || plain != "sk-secret" {
+		t.Fatalf("decrypt: %q (err=%v)", plain, err)
+	}
+
+	other := bytes.Repeat([]byte{8}, 32)
+	if _, err := Decrypt(other, sealed); err == nil {
+		t.Fatal("expected decrypt with a different key to fail")
+	}
+	if _, err := Decrypt(key, "sk-plain"); err == nil {
+		t.Fatal("expected error for a value without prefix")
+	}
+	if v, err := Encrypt(key, ""); err != nil || v != "" {
+		t.Fatalf("empty value should stay empty, got %q (err=%v)", v, err)
+	}
+
+	if _, err := ParseKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Fatal("expected error for a short key")
+	}
+}
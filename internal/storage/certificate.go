@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Certificate 监控项最近一次探测观察到的 TLS 证书信息（每个监控项保留一条）
+type Certificate struct {
+	Provider   string `json:"provider"`
+	Service    string `json:"service"`
+	Channel    string `json:"channel"`
+	Subject    string `json:"subject"`     // 叶子证书主题
+	Issuer     string `json:"issuer"`      // 叶子证书签发者
+	NotBefore  int64  `json:"not_before"`  // 叶子证书生效时间（Unix 秒）
+	NotAfter   int64  `json:"not_after"`   // 证书链中最早的过期时间（Unix 秒）
+	TLSVersion string `json:"tls_version"` // 协商的 TLS 版本（握手失败时为空）
+	CheckedAt  int64  `json:"checked_at"`  // 观察到该证书的探测时间（Unix 秒）
+}
+
+// ExpiresIn 距离证书过期的剩余时间（秒），已过期时为负数
+func (c *Certificate) ExpiresIn(now int64) int64 {
+	return c.NotAfter - now
+}
+
+// certificateColumns tls_certificates 查询列（顺序需与 scanCertificate 保持一致）
+var certificateColumns = []string{
+	"provider", "service", "channel", "subject", "issuer",
+	"not_before", "not_after", "tls_version", "checked_at",
+}
+
+// scanCertificate 按 certificateColumns 的顺序扫描一条证书信息
+func scanCertificate(row rowScanner) (*Certificate, error) {
+	var cert Certificate
+	if err := row.Scan(
+		&cert.Provider,
+		&cert.Service,
+		&cert.Channel,
+		&cert.Subject,
+		&cert.Issuer,
+		&cert.NotBefore,
+		&cert.NotAfter,
+		&cert.TLSVersion,
+		&cert.CheckedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// certificateTableSQL 证书表建表语句，bigint 为时间戳列类型
+func certificateTableSQL(bigint string) string {
+	return fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS tls_certificates (
+		provider TEXT NOT NULL,
+		service TEXT NOT NULL,
+		channel TEXT NOT NULL DEFAULT '',
+		subject TEXT NOT NULL DEFAULT '',
+		issuer TEXT NOT NULL DEFAULT '',
+		not_before %[1]s NOT NULL DEFAULT 0,
+		not_after %[1]s NOT NULL DEFAULT 0,
+		tls_version TEXT NOT NULL DEFAULT '',
+		checked_at %[1]s NOT NULL DEFAULT 0,
+		PRIMARY KEY (provider, service, channel)
+	);
+	`, bigint)
+}
+
+// certificateUpsertSQL 写入或覆盖监控项的证书信息
+func certificateUpsertSQL(placeholder func(n int) string) string {
+	args := make([]string, len(certificateColumns))
+	var updates []string
+	for i, col := range certificateColumns {
+		args[i] = placeholder(i + 1)
+		if i >= 3 {
+			updates = append(updates, fmt.Sprintf("%[1]s = excluded.%[1]s", col))
+		}
+	}
+	return fmt.Sprintf(`
+		INSERT INTO tls_certificates (%s)
+		VALUES (%s)
+		ON CONFLICT (provider, service, channel) DO UPDATE SET
+			%s
+	`, strings.Join(certificateColumns, ", "), strings.Join(args, ", "), strings.Join(updates, ",\n\t\t\t"))
+}
+
+// certificateUpsertArgs 证书信息对应的参数（顺序与 certificateColumns 一致）
+func certificateUpsertArgs(cert *Certificate) []any {
+	return []any{
+		cert.Provider, cert.Service, cert.Channel, cert.Subject, cert.Issuer,
+		cert.NotBefore, cert.NotAfter, cert.TLSVersion, cert.CheckedAt,
+	}
+}
+
+// certificateQuerySQL 按监控项查询证书信息
+func certificateQuerySQL(placeholder func(n int) string) string {
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM tls_certificates
+		WHERE provider = %s AND service = %s AND channel = %s
+	`, strings.Join(certificateColumns, ", "), placeholder(1), placeholder(2), placeholder(3))
+}
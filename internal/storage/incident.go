@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 故障事件状态过滤
+const (
+	IncidentStateOpen   = "open"   // 进行中
+	IncidentStateClosed = "closed" // 已恢复
+)
+
+// Incident 故障事件（一段连续的红色探测）
+type Incident struct {
+	ID          int64     `json:"id"`
+	Provider    string    `json:"provider"`
+	Service     string    `json:"service"`
+	Channel     string    `json:"channel"`
+	StartTime   int64     `json:"start_time"`   // 首次红色探测时间（Unix 秒）
+	EndTime     int64     `json:"end_time"`     // 恢复时间（Unix 秒），0 表示进行中
+	SubStatus   SubStatus `json:"sub_status"`   // 主要原因（出现次数最多的细分状态）
+	PeakLatency int       `json:"peak_latency"` // 故障期间最大延迟（毫秒）
+	ProbeCount  int       `json:"probe_count"`  // 故障期间的红色探测次数
+
+	Region string `json:"region,omitempty"` // 探测节点上报结果触发的故障所在地域（本地探测为空）
+}
+
+// IsOpen 故障是否仍在进行中
+func (i *Incident) IsOpen() bool {
+	return i.EndTime == 0
+}
+
+// Duration 故障持续时长（秒），进行中的故障计算到 now
+func (i *Incident) Duration(now int64) int64 {
+	end := i.EndTime
+	if end == 0 {
+		end = now
+	}
+	if end < i.StartTime {
+		return 0
+	}
+	return end - i.StartTime
+}
+
+// IncidentFilter 故障事件查询条件（零值字段表示不过滤）
+type IncidentFilter struct {
+	Provider string
+	Service  string
+	Channel  string
+	Since    int64  // 与 [Since, Until] 有交集的故障（Unix 秒）
+	Until    int64  // 0 表示不限
+	State    string // open / closed / 空
+	Limit    int    // 0 表示不限
+}
+
+// incidentColumnDefs 后续版本新增的 incidents 列，Init 时逐一补齐
+var incidentColumnDefs = []columnDef{
+	{"region", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// incidentColumns incidents 查询列（顺序需与 scanIncident 保持一致）
+const incidentColumns = `id, provider, service, channel, region, start_time, end_time, sub_status, peak_latency, probe_count`
+
+// scanIncident 按 incidentColumns 的顺序扫描一条故障事件
+func scanIncident(row rowScanner) (*Incident, error) {
+	var inc Incident
+	var subStatusStr string
+	if err := row.Scan(
+		&inc.ID,
+		&inc.Provider,
+		&inc.Service,
+		&inc.Channel,
+		&inc.Region,
+		&inc.StartTime,
+		&inc.EndTime,
+		&subStatusStr,
+		&inc.PeakLatency,
+		&inc.ProbeCount,
+	); err != nil {
+		return nil, err
+	}
+	inc.SubStatus = SubStatus(subStatusStr)
+	return &inc, nil
+}
+
+// buildIncidentQuery 根据过滤条件构建查询语句，placeholder 生成第 n 个参数占位符
+func buildIncidentQuery(filter IncidentFilter, placeholder func(n int) string) (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, placeholder(len(args))))
+	}
+
+	if filter.Provider != "" {
+		add("provider = %s", filter.Provider)
+	}
+	if filter.Service != "" {
+		add("service = %s", filter.Service)
+	}
+	if filter.Channel != "" {
+		add("channel = %s", filter.Channel)
+	}
+	if filter.Since > 0 {
+		add("(end_time = 0 OR end_time >= %s)", filter.Since)
+	}
+	if filter.Until > 0 {
+		add("start_time <= %s", filter.Until)
+	}
+	switch filter.State {
+	case IncidentStateOpen:
+		conds = append(conds, "end_time = 0")
+	case IncidentStateClosed:
+		conds = append(conds, "end_time > 0")
+	}
+
+	query := "SELECT " + incidentColumns + " FROM incidents"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY start_time DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT " + placeholder(len(args))
+	}
+	return query, args
+}
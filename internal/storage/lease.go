@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lease 分布式租约（多副本部署时用于选举主节点）
+type Lease struct {
+	Name      string `json:"name"`
+	Holder    string `json:"holder"`     // 当前持有者标识
+	ExpiresAt int64  `json:"expires_at"` // 过期时间（Unix 毫秒）
+}
+
+// leaseTableSQL 租约表建表语句，bigint 为时间戳列类型
+func leaseTableSQL(bigint string) string {
+	return fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS leases (
+		name TEXT PRIMARY KEY,
+		holder TEXT NOT NULL,
+		expires_at %s NOT NULL
+	);
+	`, bigint)
+}
+
+// leaseAcquireSQL 获取或续期租约：不存在时插入，已过期或由同一持有者持有时覆盖，否则不修改（影响行数为 0）
+func leaseAcquireSQL(placeholder func(n int) string) string {
+	return fmt.Sprintf(`
+		INSERT INTO leases (name, holder, expires_at)
+		VALUES (%s, %s, %s)
+		ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+		WHERE leases.holder = excluded.holder OR leases.expires_at <= %s
+	`, placeholder(1), placeholder(2), placeholder(3), placeholder(4))
+}
+
+// leaseAcquireArgs 获取租约的参数（顺序与 leaseAcquireSQL 一致）
+func leaseAcquireArgs(name, holder string, now time.Time, ttl time.Duration) []any {
+	return []any{name, holder, now.Add(ttl).UnixMilli(), now.UnixMilli()}
+}
+
+// leaseReleaseSQL 主动释放租约（仅当前持有者可释放），其他节点可立即接管
+func leaseReleaseSQL(placeholder func(n int) string) string {
+	return fmt.Sprintf(`UPDATE leases SET expires_at = 0 WHERE name = %s AND holder = %s`, placeholder(1), placeholder(2))
+}
+
+// leaseQuerySQL 查询租约
+func leaseQuerySQL(placeholder func(n int) string) string {
+	return fmt.Sprintf(`SELECT name, holder, expires_at FROM leases WHERE name = %s`, placeholder(1))
+}
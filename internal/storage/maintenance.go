@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaintenanceWindow 通过 API 创建的维护窗口（字段含义与配置文件中的 maintenance.windows 一致）
+type MaintenanceWindow struct {
+	ID        int64
+	Provider  string
+	Service   string
+	Channel   string
+	Start     string // 一次性窗口的起止时间
+	End       string
+	Schedule  string // 周期性窗口的 cron 表达式与每次持续时间
+	Duration  string
+	Timezone  string
+	Mode      string
+	Reason    string
+	CreatedAt int64 // 创建时间（Unix 秒）
+}
+
+// maintenanceColumns maintenance_windows 写入列（不含自增 id，顺序需与 maintenanceInsertArgs 保持一致）
+var maintenanceColumns = []string{
+	"provider", "service", "channel", "start_at", "end_at",
+	"schedule", "duration", "timezone", "mode", "reason", "created_at",
+}
+
+// maintenanceTableSQL 维护窗口表建表语句，idColumn 为自增主键定义，bigint 为时间戳列类型
+func maintenanceTableSQL(idColumn, bigint string) string {
+	return fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS maintenance_windows (
+		id %s,
+		provider TEXT NOT NULL,
+		service TEXT NOT NULL DEFAULT '',
+		channel TEXT NOT NULL DEFAULT '',
+		start_at TEXT NOT NULL DEFAULT '',
+		end_at TEXT NOT NULL DEFAULT '',
+		schedule TEXT NOT NULL DEFAULT '',
+		duration TEXT NOT NULL DEFAULT '',
+		timezone TEXT NOT NULL DEFAULT '',
+		mode TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT '',
+		created_at %s NOT NULL DEFAULT 0
+	);
+	`, idColumn, bigint)
+}
+
+// maintenanceInsertSQL 新建维护窗口
+func maintenanceInsertSQL(placeholder func(n int) string) string {
+	args := make([]string, len(maintenanceColumns))
+	for i := range maintenanceColumns {
+		args[i] = placeholder(i + 1)
+	}
+	return fmt.Sprintf(`
+		INSERT INTO maintenance_windows (%s)
+		VALUES (%s)
+	`, strings.Join(maintenanceColumns, ", "), strings.Join(args, ", "))
+}
+
+// maintenanceInsertArgs 维护窗口对应的参数（顺序与 maintenanceColumns 一致）
+func maintenanceInsertArgs(w *MaintenanceWindow) []any {
+	return []any{
+		w.Provider, w.Service, w.Channel, w.Start, w.End,
+		w.Schedule, w.Duration, w.Timezone, w.Mode, w.Reason, w.CreatedAt,
+	}
+}
+
+// maintenanceQuerySQL 查询全部维护窗口（按 ID 升序）
+var maintenanceQuerySQL = fmt.Sprintf(`
+		SELECT id, %s
+		FROM maintenance_windows
+		ORDER BY id ASC
+	`, strings.Join(maintenanceColumns, ", "))
+
+// scanMaintenanceWindow 按 id + maintenanceColumns 的顺序扫描一条维护窗口
+func scanMaintenanceWindow(row rowScanner) (*MaintenanceWindow, error) {
+	var w MaintenanceWindow
+	if err := row.Scan(
+		&w.ID,
+		&w.Provider,
+		&w.Service,
+		&w.Channel,
+		&w.Start,
+		&w.End,
+		&w.Schedule,
+		&w.Duration,
+		&w.Timezone,
+		&w.Mode,
+		&w.Reason,
+		&w.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// MaintenanceNote 时间轴上的维护标注
+type MaintenanceNote struct {
+	Reason string `json:"reason"`
+	Start  int64  `json:"start"` // 本次维护开始时间（Unix 秒）
+	End    int64  `json:"end"`   // 本次维护结束时间（Unix 秒）
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MonitorDefinition 保存在数据库中的监控项定义（monitor_store 模式）
+type MonitorDefinition struct {
+	ID         int64
+	Provider   string
+	Service    string
+	Channel    string
+	Definition string // 监控项定义（YAML，与配置文件中 monitors 条目格式相同，不含 api_key）
+	APIKey     string // 加密后的 API Key（为空表示未配置）
+	CreatedAt  int64  // 创建时间（Unix 秒）
+	UpdatedAt  int64  // 最后修改时间（Unix 秒）
+}
+
+// monitorColumns monitors 写入列（不含自增 id，顺序需与 monitorUpsertArgs 保持一致）
+var monitorColumns = []string{
+	"provider", "service", "channel", "definition", "api_key", "created_at", "updated_at",
+}
+
+// monitorTableSQL 监控项表建表语句，idColumn 为自增主键定义，bigint 为时间戳列类型
+func monitorTableSQL(idColumn, bigint string) string {
+	return fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS monitors (
+		id %s,
+		provider TEXT NOT NULL,
+		service TEXT NOT NULL,
+		channel TEXT NOT NULL DEFAULT '',
+		definition TEXT NOT NULL,
+		api_key TEXT NOT NULL DEFAULT '',
+		created_at %[2]s NOT NULL DEFAULT 0,
+		updated_at %[2]s NOT NULL DEFAULT 0,
+		UNIQUE (provider, service, channel)
+	);
+	`, idColumn, bigint)
+}
+
+// monitorUpsertSQL 新建或覆盖监控项定义（已存在时保留 id 和 created_at）
+func monitorUpsertSQL(placeholder func(n int) string) string {
+	args := make([]string, len(monitorColumns))
+	for i := range monitorColumns {
+		args[i] = placeholder(i + 1)
+	}
+	return fmt.Sprintf(`
+		INSERT INTO monitors (%s)
+		VALUES (%s)
+		ON CONFLICT (provider, service, channel) DO UPDATE SET
+			definition = excluded.definition,
+			api_key = excluded.api_key,
+			updated_at = excluded.updated_at
+	`, strings.Join(monitorColumns, ", "), strings.Join(args, ", "))
+}
+
+// monitorUpsertArgs 监控项定义对应的参数（顺序与 monitorColumns 一致）
+func monitorUpsertArgs(m *MonitorDefinition) []any {
+	return []any{m.Provider, m.Service, m.Channel, m.Definition, m.APIKey, m.CreatedAt, m.UpdatedAt}
+}
+
+// monitorQuerySQL 查询全部监控项定义（按 ID 升序，即创建顺序）
+var monitorQuerySQL = fmt.Sprintf(`
+		SELECT id, %s
+		FROM monitors
+		ORDER BY id ASC
+	`, strings.Join(monitorColumns, ", "))
+
+// monitorDeleteSQL 删除监控项定义
+func monitorDeleteSQL(placeholder func(n int) string) string {
+	return fmt.Sprintf(`DELETE FROM monitors WHERE provider = %s AND service = %s AND channel = %s`,
+		placeholder(1), placeholder(2), placeholder(3))
+}
+
+// scanMonitorDefinition 按 id + monitorColumns 的顺序扫描一条监控项定义
+func scanMonitorDefinition(row rowScanner) (*MonitorDefinition, error) {
+	var m MonitorDefinition
+	if err := row.Scan(
+		&m.ID,
+		&m.Provider,
+		&m.Service,
+		&m.Channel,
+		&m.Definition,
+		&m.APIKey,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
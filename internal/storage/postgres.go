@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,6 +18,9 @@ type PostgresStorage struct {
 	ctx  context.Context
 }
 
+// postgresPlaceholder PostgreSQL 参数占位符（$1, $2, ...）
+func postgresPlaceholder(n int) string { return fmt.Sprintf("$%d", n) }
+
 // NewPostgresStorage 创建 PostgreSQL 存储
 func NewPostgresStorage(cfg *config.PostgresConfig) (*PostgresStorage, error) {
 	// 构建连接字符串
@@ -82,7 +86,19 @@ func (s *PostgresStorage) Init() error {
 		status INTEGER NOT NULL,
 		sub_status TEXT NOT NULL DEFAULT '',
 		latency INTEGER NOT NULL,
-		timestamp BIGINT NOT NULL
+		timestamp BIGINT NOT NULL,
+		first_byte_latency INTEGER NOT NULL DEFAULT 0,
+		first_token_latency INTEGER NOT NULL DEFAULT 0,
+		stream_duration INTEGER NOT NULL DEFAULT 0,
+		dns_duration INTEGER NOT NULL DEFAULT 0,
+		connect_duration INTEGER NOT NULL DEFAULT 0,
+		tls_duration INTEGER NOT NULL DEFAULT 0,
+		body_read_duration INTEGER NOT NULL DEFAULT 0,
+		http_status INTEGER NOT NULL DEFAULT 0,
+		error_message TEXT NOT NULL DEFAULT '',
+		response_snippet TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 1,
+		region TEXT NOT NULL DEFAULT 'local'
 	);
 	`
 
@@ -92,11 +108,10 @@ func (s *PostgresStorage) Init() error {
 	}
 
 	// 兼容旧数据库：添加缺失的列
-	if err := s.ensureSubStatusColumn(); err != nil {
-		return err
-	}
-	if err := s.ensureChannelColumn(); err != nil {
-		return err
+	for _, col := range probeHistoryColumns {
+		if err := s.ensureColumn("probe_history", col.name, col.definition); err != nil {
+			return err
+		}
 	}
 
 	// 在列迁移完成后创建索引
@@ -108,48 +123,140 @@ func (s *PostgresStorage) Init() error {
 		return fmt.Errorf("创建索引失败: %w", err)
 	}
 
+	// 故障事件表
+	incidentSQL := `
+	CREATE TABLE IF NOT EXISTS incidents (
+		id BIGSERIAL PRIMARY KEY,
+		provider TEXT NOT NULL,
+		service TEXT NOT NULL,
+		channel TEXT NOT NULL DEFAULT '',
+		start_time BIGINT NOT NULL,
+		end_time BIGINT NOT NULL DEFAULT 0,
+		sub_status TEXT NOT NULL DEFAULT '',
+		peak_latency INTEGER NOT NULL DEFAULT 0,
+		probe_count INTEGER NOT NULL DEFAULT 0,
+		region TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_incidents_provider_start
+	ON incidents(provider, service, channel, start_time DESC);
+	CREATE INDEX IF NOT EXISTS idx_incidents_start ON incidents(start_time DESC);
+	`
+	if _, err := s.pool.Exec(s.ctx, incidentSQL); err != nil {
+		return fmt.Errorf("创建故障事件表失败: %w", err)
+	}
+	for _, col := range incidentColumnDefs {
+		if err := s.ensureColumn("incidents", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	// TLS 证书表（每个监控项保留最近一次观察到的证书）
+	if _, err := s.pool.Exec(s.ctx, certificateTableSQL("BIGINT")); err != nil {
+		return fmt.Errorf("创建 PostgreSQL 证书表失败: %w", err)
+	}
+
+	// 租约表（多副本主节点选举）
+	if _, err := s.pool.Exec(s.ctx, leaseTableSQL("BIGINT")); err != nil {
+		return fmt.Errorf("创建 PostgreSQL 租约表失败: %w", err)
+	}
+
+	// 维护窗口表（通过 API 创建的窗口）
+	if _, err := s.pool.Exec(s.ctx, maintenanceTableSQL("BIGSERIAL PRIMARY KEY", "BIGINT")); err != nil {
+		return fmt.Errorf("创建 PostgreSQL 维护窗口表失败: %w", err)
+	}
+
+	// 监控项表（monitor_store 模式）
+	if _, err := s.pool.Exec(s.ctx, monitorTableSQL("BIGSERIAL PRIMARY KEY", "BIGINT")); err != nil {
+		return fmt.Errorf("创建 PostgreSQL 监控项表失败: %w", err)
+	}
+
+	// 小时/天聚合表（长时间范围查询直接读取聚合数据）
+	for _, g := range rollupGranularities {
+		if err := s.initRollupTable(g); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// ensureSubStatusColumn 在旧表上添加 sub_status 列（向后兼容）
-func (s *PostgresStorage) ensureSubStatusColumn() error {
-	// PostgreSQL 使用 information_schema 查询列是否存在
-	checkQuery := `
-		SELECT COUNT(*)
-		FROM information_schema.columns
-		WHERE table_name = 'probe_history' AND column_name = 'sub_status'
-	`
+// initRollupTable 创建聚合表，新建时从 probe_history 回填历史数据
+func (s *PostgresStorage) initRollupTable(g RollupGranularity) error {
+	table := rollupTable(g)
 
-	var count int
-	err := s.pool.QueryRow(s.ctx, checkQuery).Scan(&count)
+	var exists int
+	if err := s.pool.QueryRow(s.ctx, `SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1`, table).Scan(&exists); err != nil {
+		return fmt.Errorf("检查 PostgreSQL 聚合表失败: %w", err)
+	}
+
+	if _, err := s.pool.Exec(s.ctx, rollupSchema(g, "BIGINT")); err != nil {
+		return fmt.Errorf("创建 PostgreSQL 聚合表 %s 失败: %w", table, err)
+	}
+
+	// 兼容旧聚合表：添加后续版本新增的分阶段延迟列
+	for _, col := range rollupPhaseColumnDefs("BIGINT") {
+		if err := s.ensureColumn(table, col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	if exists == 0 {
+		if err := s.rebuildRollups(g, "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildRollups 从 probe_history 重建聚合数据（provider 为空时重建全部）
+func (s *PostgresStorage) rebuildRollups(g RollupGranularity, provider, service string) error {
+	filtered := provider != ""
+	var args []any
+	if filtered {
+		args = []any{provider, service}
+	}
+
+	tx, err := s.pool.Begin(s.ctx)
 	if err != nil {
-		return fmt.Errorf("查询 PostgreSQL 表结构失败: %w", err)
+		return fmt.Errorf("开启 PostgreSQL 事务失败: %w", err)
 	}
+	defer tx.Rollback(s.ctx)
 
-	if count > 0 {
-		return nil // 列已存在，无需添加
+	// 天粒度的桶边界按记录时间范围内的每个本地日历日生成
+	var from, to int64
+	if err := tx.QueryRow(s.ctx, rollupRebuildRangeSQL(filtered, postgresPlaceholder), args...).Scan(&from, &to); err != nil {
+		return fmt.Errorf("查询 PostgreSQL 探测记录时间范围失败: %w", err)
 	}
+	deleteSQL, insertSQL := rollupRebuildSQL(g, filtered, from, to, postgresPlaceholder)
 
-	// 添加列
-	alterQuery := `ALTER TABLE probe_history ADD COLUMN sub_status TEXT NOT NULL DEFAULT ''`
-	if _, err := s.pool.Exec(s.ctx, alterQuery); err != nil {
-		return fmt.Errorf("添加 sub_status 列失败: %w", err)
+	if _, err := tx.Exec(s.ctx, deleteSQL, args...); err != nil {
+		return fmt.Errorf("清理 PostgreSQL 聚合表 %s 失败: %w", rollupTable(g), err)
+	}
+	tag, err := tx.Exec(s.ctx, insertSQL, args...)
+	if err != nil {
+		return fmt.Errorf("回填 PostgreSQL 聚合表 %s 失败: %w", rollupTable(g), err)
+	}
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("提交 PostgreSQL 聚合表 %s 失败: %w", rollupTable(g), err)
 	}
 
-	log.Println("[Storage] 已为 probe_history 表添加 sub_status 列 (PostgreSQL)")
+	if rows := tag.RowsAffected(); rows > 0 {
+		log.Printf("[Storage] 已重建 %s 聚合数据 %d 行 (PostgreSQL)", rollupTable(g), rows)
+	}
 	return nil
 }
 
-// ensureChannelColumn 在旧表上添加 channel 列（向后兼容）
-func (s *PostgresStorage) ensureChannelColumn() error {
+// ensureColumn 在旧表上添加缺失的列（向后兼容）
+func (s *PostgresStorage) ensureColumn(table, column, definition string) error {
+	// PostgreSQL 使用 information_schema 查询列是否存在
 	checkQuery := `
 		SELECT COUNT(*)
 		FROM information_schema.columns
-		WHERE table_name = 'probe_history' AND column_name = 'channel'
+		WHERE table_name = $1 AND column_name = $2
 	`
 
 	var count int
-	err := s.pool.QueryRow(s.ctx, checkQuery).Scan(&count)
+	err := s.pool.QueryRow(s.ctx, checkQuery, table, column).Scan(&count)
 	if err != nil {
 		return fmt.Errorf("查询 PostgreSQL 表结构失败: %w", err)
 	}
@@ -159,12 +266,12 @@ func (s *PostgresStorage) ensureChannelColumn() error {
 	}
 
 	// 添加列
-	alterQuery := `ALTER TABLE probe_history ADD COLUMN channel TEXT NOT NULL DEFAULT ''`
+	alterQuery := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)
 	if _, err := s.pool.Exec(s.ctx, alterQuery); err != nil {
-		return fmt.Errorf("添加 channel 列失败: %w", err)
+		return fmt.Errorf("添加 %s 列失败: %w", column, err)
 	}
 
-	log.Println("[Storage] 已为 probe_history 表添加 channel 列 (PostgreSQL)")
+	log.Printf("[Storage] 已为 %s 表添加 %s 列 (PostgreSQL)", table, column)
 	return nil
 }
 
@@ -208,6 +315,12 @@ func (s *PostgresStorage) MigrateChannelData(mappings []ChannelMigrationMapping)
 				"[Storage] 已迁移 %d 条记录 -> channel=%s (provider=%s, service=%s, PostgreSQL)",
 				affected, mapping.Channel, mapping.Provider, mapping.Service,
 			)
+			// 聚合数据同样需要迁移到新的 channel
+			for _, g := range rollupGranularities {
+				if err := s.rebuildRollups(g, mapping.Provider, mapping.Service); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -234,52 +347,73 @@ func (s *PostgresStorage) Close() error {
 
 // SaveRecord 保存探测记录
 func (s *PostgresStorage) SaveRecord(record *ProbeRecord) error {
+	return s.SaveRecords([]*ProbeRecord{record})
+}
+
+// SaveRecords 在同一事务中保存多条探测记录（任一失败时全部回滚）
+func (s *PostgresStorage) SaveRecords(records []*ProbeRecord) error {
 	query := `
-		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp,
+			first_byte_latency, first_token_latency, stream_duration,
+			dns_duration, connect_duration, tls_duration, body_read_duration,
+			http_status, error_message, response_snippet, attempts, region)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id
 	`
 
-	err := s.pool.QueryRow(s.ctx, query,
-		record.Provider,
-		record.Service,
-		record.Channel,
-		record.Status,
-		string(record.SubStatus),
-		record.Latency,
-		record.Timestamp,
-	).Scan(&record.ID)
-
+	tx, err := s.pool.Begin(s.ctx)
 	if err != nil {
-		return fmt.Errorf("保存 PostgreSQL 记录失败: %w", err)
+		return fmt.Errorf("开启 PostgreSQL 事务失败: %w", err)
+	}
+	defer tx.Rollback(s.ctx)
+
+	for _, record := range records {
+		err = tx.QueryRow(s.ctx, query,
+			record.Provider,
+			record.Service,
+			record.Channel,
+			record.Status,
+			string(record.SubStatus),
+			record.Latency,
+			record.Timestamp,
+			record.FirstByteLatency,
+			record.FirstTokenLatency,
+			record.StreamDuration,
+			record.DNSDuration,
+			record.ConnectDuration,
+			record.TLSDuration,
+			record.BodyReadDuration,
+			record.HTTPStatus,
+			record.ErrorMessage,
+			record.ResponseSnippet,
+			record.Attempts,
+			recordRegion(record),
+		).Scan(&record.ID)
+
+		if err != nil {
+			return fmt.Errorf("保存 PostgreSQL 记录失败: %w", err)
+		}
+
+		// 累加到聚合表
+		for _, g := range rollupGranularities {
+			if _, err := tx.Exec(s.ctx, rollupUpsertSQL(g, postgresPlaceholder), rollupUpsertArgs(g, record)...); err != nil {
+				return fmt.Errorf("更新 PostgreSQL 聚合表 %s 失败: %w", rollupTable(g), err)
+			}
+		}
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("提交 PostgreSQL 记录失败: %w", err)
 	}
 
 	return nil
 }
 
 // GetLatest 获取最新记录
-func (s *PostgresStorage) GetLatest(provider, service, channel string) (*ProbeRecord, error) {
-	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
-		FROM probe_history
-		WHERE provider = $1 AND service = $2 AND channel = $3
-		ORDER BY timestamp DESC
-		LIMIT 1
-	`
-
-	var record ProbeRecord
-	var subStatusStr string
-	err := s.pool.QueryRow(s.ctx, query, provider, service, channel).Scan(
-		&record.ID,
-		&record.Provider,
-		&record.Service,
-		&record.Channel,
-		&record.Status,
-		&subStatusStr,
-		&record.Latency,
-		&record.Timestamp,
-	)
+func (s *PostgresStorage) GetLatest(provider, service, channel, region string) (*ProbeRecord, error) {
+	query, args := latestQuery(provider, service, channel, region, postgresPlaceholder)
 
+	record, err := scanProbeRecord(s.pool.QueryRow(s.ctx, query, args...))
 	if err != nil {
 		// pgx 使用 ErrNoRows 的方式不同，需要检查错误消息
 		if err.Error() == "no rows in result set" {
@@ -288,68 +422,343 @@ func (s *PostgresStorage) GetLatest(provider, service, channel string) (*ProbeRe
 		return nil, fmt.Errorf("查询 PostgreSQL 最新记录失败: %w", err)
 	}
 
-	record.SubStatus = SubStatus(subStatusStr)
-	return &record, nil
+	return record, nil
 }
 
-// GetHistory 获取历史记录
-func (s *PostgresStorage) GetHistory(provider, service, channel string, since time.Time) ([]*ProbeRecord, error) {
-	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
-		FROM probe_history
-		WHERE provider = $1 AND service = $2 AND channel = $3 AND timestamp >= $4
-		ORDER BY timestamp ASC
-	`
+// GetLatestByRegion 获取各地域的最新记录
+func (s *PostgresStorage) GetLatestByRegion(provider, service, channel string, since time.Time) ([]*ProbeRecord, error) {
+	query, args := latestByRegionQuery(provider, service, channel, since, postgresPlaceholder)
+	records, err := s.queryRecords(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 各地域最新记录失败: %w", err)
+	}
+	return dedupeRegions(records), nil
+}
 
-	rows, err := s.pool.Query(s.ctx, query, provider, service, channel, since.Unix())
+// GetHistory 获取 [since, until) 内的历史记录
+func (s *PostgresStorage) GetHistory(provider, service, channel, region string, since, until time.Time) ([]*ProbeRecord, error) {
+	query, args := historyQuery(provider, service, channel, region, since, until, postgresPlaceholder)
+	records, err := s.queryRecords(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("查询 PostgreSQL 历史记录失败: %w", err)
 	}
+	return records, nil
+}
+
+// GetRecent 获取最近 limit 条探测记录（按时间升序）
+func (s *PostgresStorage) GetRecent(provider, service, channel, region string, limit int) ([]*ProbeRecord, error) {
+	query, args := recentQuery(provider, service, channel, region, limit, postgresPlaceholder)
+	records, err := s.queryRecords(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 最近记录失败: %w", err)
+	}
+	return reverseRecords(records), nil
+}
+
+// GetFailures 获取非绿色探测记录及诊断信息
+func (s *PostgresStorage) GetFailures(provider, service, channel, region string, since, until time.Time, limit int) ([]*ProbeRecord, error) {
+	query, args := failuresQuery(provider, service, channel, region, since, until, limit, postgresPlaceholder)
+	records, err := s.queryRecords(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 诊断记录失败: %w", err)
+	}
+	return records, nil
+}
+
+// queryRecords 执行查询并扫描全部探测记录
+func (s *PostgresStorage) queryRecords(query string, args ...any) ([]*ProbeRecord, error) {
+	rows, err := s.pool.Query(s.ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var records []*ProbeRecord
 	for rows.Next() {
-		var record ProbeRecord
-		var subStatusStr string
-		err := rows.Scan(
-			&record.ID,
-			&record.Provider,
-			&record.Service,
-			&record.Channel,
-			&record.Status,
-			&subStatusStr,
-			&record.Latency,
-			&record.Timestamp,
-		)
+		record, err := scanProbeRecord(rows)
 		if err != nil {
-			return nil, fmt.Errorf("扫描 PostgreSQL 记录失败: %w", err)
+			return nil, fmt.Errorf("扫描记录失败: %w", err)
 		}
-		record.SubStatus = SubStatus(subStatusStr)
-		records = append(records, &record)
+		records = append(records, record)
 	}
 
 	// 检查迭代过程中是否发生错误
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("迭代 PostgreSQL 记录失败: %w", err)
+		return nil, fmt.Errorf("迭代记录失败: %w", err)
+	}
+
+	return records, nil
+}
+
+// GetRollups 获取聚合记录
+func (s *PostgresStorage) GetRollups(provider, service, channel string, granularity RollupGranularity, since, until time.Time) ([]*RollupRecord, error) {
+	rows, err := s.pool.Query(s.ctx, rollupQuerySQL(granularity, postgresPlaceholder), provider, service, channel, since.Unix(), until.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 聚合记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*RollupRecord
+	for rows.Next() {
+		record, err := scanRollupRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描 PostgreSQL 聚合记录失败: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代 PostgreSQL 聚合记录失败: %w", err)
 	}
 
 	return records, nil
 }
 
 // CleanOldRecords 清理旧记录
-func (s *PostgresStorage) CleanOldRecords(days int) error {
+func (s *PostgresStorage) CleanOldRecords(days int) (int64, error) {
 	cutoff := time.Now().AddDate(0, 0, -days).Unix()
 	query := `DELETE FROM probe_history WHERE timestamp < $1`
 
 	result, err := s.pool.Exec(s.ctx, query, cutoff)
 	if err != nil {
-		return fmt.Errorf("清理 PostgreSQL 旧记录失败: %w", err)
+		return 0, fmt.Errorf("清理 PostgreSQL 旧记录失败: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// CleanOldRollups 清理旧聚合数据
+func (s *PostgresStorage) CleanOldRollups(granularity RollupGranularity, days int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -days).Unix()
+
+	result, err := s.pool.Exec(s.ctx, rollupCleanSQL(granularity, postgresPlaceholder), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("清理 PostgreSQL %s 聚合数据失败: %w", rollupTable(granularity), err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// Optimize 对监控相关表执行 VACUUM/ANALYZE（不能在事务中执行）
+func (s *PostgresStorage) Optimize(vacuum, analyze bool) error {
+	var stmt string
+	switch {
+	case vacuum && analyze:
+		stmt = "VACUUM (ANALYZE)"
+	case vacuum:
+		stmt = "VACUUM"
+	case analyze:
+		stmt = "ANALYZE"
+	default:
+		return nil
+	}
+
+	tables := []string{"probe_history", "incidents"}
+	for _, g := range rollupGranularities {
+		tables = append(tables, rollupTable(g))
+	}
+	if _, err := s.pool.Exec(s.ctx, stmt+" "+strings.Join(tables, ", ")); err != nil {
+		return fmt.Errorf("PostgreSQL %s 失败: %w", stmt, err)
+	}
+	return nil
+}
+
+// SaveIncident 保存故障事件
+func (s *PostgresStorage) SaveIncident(incident *Incident) error {
+	if incident.ID == 0 {
+		err := s.pool.QueryRow(s.ctx, `
+			INSERT INTO incidents (provider, service, channel, region, start_time, end_time, sub_status, peak_latency, probe_count)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id
+		`,
+			incident.Provider, incident.Service, incident.Channel, incident.Region,
+			incident.StartTime, incident.EndTime, string(incident.SubStatus),
+			incident.PeakLatency, incident.ProbeCount,
+		).Scan(&incident.ID)
+		if err != nil {
+			return fmt.Errorf("保存 PostgreSQL 故障事件失败: %w", err)
+		}
+		return nil
+	}
+
+	_, err := s.pool.Exec(s.ctx, `
+		UPDATE incidents SET end_time = $1, sub_status = $2, peak_latency = $3, probe_count = $4
+		WHERE id = $5
+	`, incident.EndTime, string(incident.SubStatus), incident.PeakLatency, incident.ProbeCount, incident.ID)
+	if err != nil {
+		return fmt.Errorf("更新 PostgreSQL 故障事件失败: %w", err)
+	}
+	return nil
+}
+
+// SaveCertificate 保存 TLS 证书信息
+func (s *PostgresStorage) SaveCertificate(cert *Certificate) error {
+	if _, err := s.pool.Exec(s.ctx, certificateUpsertSQL(postgresPlaceholder), certificateUpsertArgs(cert)...); err != nil {
+		return fmt.Errorf("保存 PostgreSQL 证书信息失败: %w", err)
+	}
+	return nil
+}
+
+// GetCertificate 获取 TLS 证书信息
+func (s *PostgresStorage) GetCertificate(provider, service, channel string) (*Certificate, error) {
+	cert, err := scanCertificate(s.pool.QueryRow(s.ctx, certificateQuerySQL(postgresPlaceholder), provider, service, channel))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询 PostgreSQL 证书信息失败: %w", err)
 	}
+	return cert, nil
+}
 
-	deleted := result.RowsAffected()
-	if deleted > 0 {
-		log.Printf("[Storage] 已清理 %d 条超过 %d 天的旧记录 (PostgreSQL)\n", deleted, days)
+// AcquireLease 获取或续期租约
+func (s *PostgresStorage) AcquireLease(name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	tag, err := s.pool.Exec(s.ctx, leaseAcquireSQL(postgresPlaceholder), leaseAcquireArgs(name, holder, now, ttl)...)
+	if err != nil {
+		return false, fmt.Errorf("获取 PostgreSQL 租约失败: %w", err)
 	}
+	return tag.RowsAffected() > 0, nil
+}
 
+// ReleaseLease 释放租约
+func (s *PostgresStorage) ReleaseLease(name, holder string) error {
+	if _, err := s.pool.Exec(s.ctx, leaseReleaseSQL(postgresPlaceholder), name, holder); err != nil {
+		return fmt.Errorf("释放 PostgreSQL 租约失败: %w", err)
+	}
 	return nil
 }
+
+// GetLease 查询租约
+func (s *PostgresStorage) GetLease(name string) (*Lease, error) {
+	var lease Lease
+	err := s.pool.QueryRow(s.ctx, leaseQuerySQL(postgresPlaceholder), name).Scan(&lease.Name, &lease.Holder, &lease.ExpiresAt)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询 PostgreSQL 租约失败: %w", err)
+	}
+	return &lease, nil
+}
+
+// SaveMaintenanceWindow 新建维护窗口
+func (s *PostgresStorage) SaveMaintenanceWindow(w *MaintenanceWindow) error {
+	err := s.pool.QueryRow(s.ctx, maintenanceInsertSQL(postgresPlaceholder)+" RETURNING id", maintenanceInsertArgs(w)...).Scan(&w.ID)
+	if err != nil {
+		return fmt.Errorf("保存 PostgreSQL 维护窗口失败: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenanceWindows 查询全部维护窗口
+func (s *PostgresStorage) GetMaintenanceWindows() ([]*MaintenanceWindow, error) {
+	rows, err := s.pool.Query(s.ctx, maintenanceQuerySQL)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 维护窗口失败: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*MaintenanceWindow
+	for rows.Next() {
+		w, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描 PostgreSQL 维护窗口失败: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代 PostgreSQL 维护窗口失败: %w", err)
+	}
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow 删除维护窗口
+func (s *PostgresStorage) DeleteMaintenanceWindow(id int64) (bool, error) {
+	tag, err := s.pool.Exec(s.ctx, `DELETE FROM maintenance_windows WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("删除 PostgreSQL 维护窗口失败: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SaveMonitor 新建或覆盖监控项定义
+func (s *PostgresStorage) SaveMonitor(m *MonitorDefinition) error {
+	return s.SaveMonitors([]*MonitorDefinition{m})
+}
+
+// SaveMonitors 在同一事务中新建或覆盖多个监控项定义
+func (s *PostgresStorage) SaveMonitors(monitors []*MonitorDefinition) error {
+	tx, err := s.pool.Begin(s.ctx)
+	if err != nil {
+		return fmt.Errorf("开启 PostgreSQL 事务失败: %w", err)
+	}
+	defer tx.Rollback(s.ctx)
+
+	query := monitorUpsertSQL(postgresPlaceholder)
+	for _, m := range monitors {
+		if _, err := tx.Exec(s.ctx, query, monitorUpsertArgs(m)...); err != nil {
+			return fmt.Errorf("保存 PostgreSQL 监控项失败: %w", err)
+		}
+	}
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("提交 PostgreSQL 监控项失败: %w", err)
+	}
+	return nil
+}
+
+// GetMonitors 查询全部监控项定义
+func (s *PostgresStorage) GetMonitors() ([]*MonitorDefinition, error) {
+	rows, err := s.pool.Query(s.ctx, monitorQuerySQL)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 监控项失败: %w", err)
+	}
+	defer rows.Close()
+
+	var monitors []*MonitorDefinition
+	for rows.Next() {
+		m, err := scanMonitorDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描 PostgreSQL 监控项失败: %w", err)
+		}
+		monitors = append(monitors, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代 PostgreSQL 监控项失败: %w", err)
+	}
+	return monitors, nil
+}
+
+// DeleteMonitor 删除监控项定义
+func (s *PostgresStorage) DeleteMonitor(provider, service, channel string) (bool, error) {
+	tag, err := s.pool.Exec(s.ctx, monitorDeleteSQL(postgresPlaceholder), provider, service, channel)
+	if err != nil {
+		return false, fmt.Errorf("删除 PostgreSQL 监控项失败: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetIncidents 查询故障事件
+func (s *PostgresStorage) GetIncidents(filter IncidentFilter) ([]*Incident, error) {
+	query, args := buildIncidentQuery(filter, postgresPlaceholder)
+
+	rows, err := s.pool.Query(s.ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 故障事件失败: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []*Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描 PostgreSQL 故障事件失败: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代 PostgreSQL 故障事件失败: %w", err)
+	}
+
+	return incidents, nil
+}
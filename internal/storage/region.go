@@ -0,0 +1,117 @@
+package storage
+
+import "time"
+
+// DefaultRegion 未配置地域时的探测地域标识（旧数据迁移后同样归入该地域）
+const DefaultRegion = "local"
+
+// recordRegion 探测记录的地域（未设置时为 DefaultRegion）
+func recordRegion(record *ProbeRecord) string {
+	if record.Region == "" {
+		return DefaultRegion
+	}
+	return record.Region
+}
+
+// probeFilter 按监控项与地域过滤 probe_history（region 为空表示全部地域），返回条件及参数，占位符从 1 开始编号
+func probeFilter(provider, service, channel, region string, placeholder func(n int) string) (string, []any) {
+	where := `provider = ` + placeholder(1) + ` AND service = ` + placeholder(2) + ` AND channel = ` + placeholder(3)
+	args := []any{provider, service, channel}
+	if region != "" {
+		where += ` AND region = ` + placeholder(4)
+		args = append(args, region)
+	}
+	return where, args
+}
+
+// latestQuery 查询最新一条探测记录
+func latestQuery(provider, service, channel, region string, placeholder func(n int) string) (string, []any) {
+	where, args := probeFilter(provider, service, channel, region, placeholder)
+	return `
+		SELECT ` + probeColumns + `
+		FROM probe_history
+		WHERE ` + where + `
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, args
+}
+
+// historyQuery 查询 [since, until) 内的探测记录（按时间升序）
+func historyQuery(provider, service, channel, region string, since, until time.Time, placeholder func(n int) string) (string, []any) {
+	where, args := probeFilter(provider, service, channel, region, placeholder)
+	n := len(args)
+	args = append(args, since.Unix(), until.Unix())
+	return `
+		SELECT ` + probeColumns + `
+		FROM probe_history
+		WHERE ` + where + ` AND timestamp >= ` + placeholder(n+1) + ` AND timestamp < ` + placeholder(n+2) + `
+		ORDER BY timestamp ASC
+	`, args
+}
+
+// recentQuery 查询最近 limit 条探测记录（按时间倒序）
+func recentQuery(provider, service, channel, region string, limit int, placeholder func(n int) string) (string, []any) {
+	where, args := probeFilter(provider, service, channel, region, placeholder)
+	args = append(args, limit)
+	return `
+		SELECT ` + probeColumns + `
+		FROM probe_history
+		WHERE ` + where + `
+		ORDER BY timestamp DESC, id DESC
+		LIMIT ` + placeholder(len(args)), args
+}
+
+// reverseRecords 原地反转记录顺序（倒序查询结果转为时间升序）
+func reverseRecords(records []*ProbeRecord) []*ProbeRecord {
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records
+}
+
+// failuresQuery 查询 [since, until) 内的非绿色探测记录（按时间倒序，最多 limit 条）
+func failuresQuery(provider, service, channel, region string, since, until time.Time, limit int, placeholder func(n int) string) (string, []any) {
+	where, args := probeFilter(provider, service, channel, region, placeholder)
+	n := len(args)
+	args = append(args, since.Unix(), until.Unix(), limit)
+	return `
+		SELECT ` + probeColumns + `
+		FROM probe_history
+		WHERE ` + where + `
+			AND timestamp >= ` + placeholder(n+1) + ` AND timestamp < ` + placeholder(n+2) + ` AND status <> 1
+		ORDER BY timestamp DESC
+		LIMIT ` + placeholder(n+3), args
+}
+
+// latestByRegionQuery 查询 since 之后各地域的最新一条探测记录（按地域排序）
+func latestByRegionQuery(provider, service, channel string, since time.Time, placeholder func(n int) string) (string, []any) {
+	where, args := probeFilter(provider, service, channel, "", placeholder)
+	args = append(args, since.Unix())
+	return `
+		SELECT ` + probeColumns + `
+		FROM probe_history
+		JOIN (
+			SELECT provider AS latest_provider, service AS latest_service, channel AS latest_channel,
+				region AS latest_region, MAX(timestamp) AS latest_timestamp
+			FROM probe_history
+			WHERE ` + where + ` AND timestamp >= ` + placeholder(len(args)) + `
+			GROUP BY provider, service, channel, region
+		) latest ON provider = latest_provider AND service = latest_service AND channel = latest_channel
+			AND region = latest_region AND timestamp = latest_timestamp
+		ORDER BY region ASC, id DESC
+	`, args
+}
+
+// dedupeRegions 每个地域只保留第一条记录（同一时间戳可能有多条记录）
+func dedupeRegions(records []*ProbeRecord) []*ProbeRecord {
+	result := records[:0]
+	seen := make(map[string]bool, len(records))
+	for _, r := range records {
+		if seen[r.Region] {
+			continue
+		}
+		seen[r.Region] = true
+		result = append(result, r)
+	}
+	return result
+}
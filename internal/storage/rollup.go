@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RollupGranularity 聚合粒度
+type RollupGranularity string
+
+const (
+	RollupHourly RollupGranularity = "hourly" // 按小时聚合（UTC 整点）
+	RollupDaily  RollupGranularity = "daily"  // 按天聚合（服务器本地时区的日历日零点）
+)
+
+// rollupGranularities 所有聚合粒度（写入时逐一更新）
+var rollupGranularities = []RollupGranularity{RollupHourly, RollupDaily}
+
+// RollupRecord 聚合记录：一个 bucket 内同一状态/细分状态的探测统计
+type RollupRecord struct {
+	BucketStart int64 // bucket 起始时间（Unix 秒）
+	Status      int
+	SubStatus   SubStatus
+	Count       int
+
+	LatencySum int64
+	LatencyMin int
+	LatencyMax int
+
+	// 分阶段延迟（仅统计有值的探测）
+	FirstByte      PhaseSum
+	FirstToken     PhaseSum
+	StreamDuration PhaseSum
+	DNS            PhaseSum
+	Connect        PhaseSum
+	TLS            PhaseSum
+	BodyRead       PhaseSum
+
+	LastTimestamp int64 // bucket 内该状态最后一次探测时间
+}
+
+// PhaseSum 分阶段延迟累计值（毫秒）及有值的探测次数
+type PhaseSum struct {
+	Sum   int64
+	Count int
+}
+
+// rollupPhase 聚合表中按"有值才计数"累加的分阶段延迟
+type rollupPhase struct {
+	source string                          // probe_history 中的列名
+	prefix string                          // 聚合表列名前缀（<prefix>_sum / <prefix>_count）
+	record func(r *ProbeRecord) int        // 探测记录中的取值
+	rollup func(r *RollupRecord) *PhaseSum // 聚合记录中的累计字段
+}
+
+// rollupPhases 所有分阶段延迟（顺序决定聚合表的列顺序）
+var rollupPhases = []rollupPhase{
+	{"first_byte_latency", "first_byte", func(r *ProbeRecord) int { return r.FirstByteLatency }, func(r *RollupRecord) *PhaseSum { return &r.FirstByte }},
+	{"first_token_latency", "first_token", func(r *ProbeRecord) int { return r.FirstTokenLatency }, func(r *RollupRecord) *PhaseSum { return &r.FirstToken }},
+	{"stream_duration", "stream_duration", func(r *ProbeRecord) int { return r.StreamDuration }, func(r *RollupRecord) *PhaseSum { return &r.StreamDuration }},
+	{"dns_duration", "dns", func(r *ProbeRecord) int { return r.DNSDuration }, func(r *RollupRecord) *PhaseSum { return &r.DNS }},
+	{"connect_duration", "connect", func(r *ProbeRecord) int { return r.ConnectDuration }, func(r *RollupRecord) *PhaseSum { return &r.Connect }},
+	{"tls_duration", "tls", func(r *ProbeRecord) int { return r.TLSDuration }, func(r *RollupRecord) *PhaseSum { return &r.TLS }},
+	{"body_read_duration", "body_read", func(r *ProbeRecord) int { return r.BodyReadDuration }, func(r *RollupRecord) *PhaseSum { return &r.BodyRead }},
+}
+
+// rollupPhaseColumns 分阶段延迟在聚合表中的列名（每个阶段依次为 sum、count）
+func rollupPhaseColumns() []string {
+	cols := make([]string, 0, 2*len(rollupPhases))
+	for _, p := range rollupPhases {
+		cols = append(cols, p.prefix+"_sum", p.prefix+"_count")
+	}
+	return cols
+}
+
+// rollupPhaseColumnDefs 分阶段延迟列定义（bigint 为累加值的列类型，旧聚合表 Init 时逐一补齐）
+func rollupPhaseColumnDefs(bigint string) []columnDef {
+	defs := make([]columnDef, 0, 2*len(rollupPhases))
+	for _, p := range rollupPhases {
+		defs = append(defs,
+			columnDef{p.prefix + "_sum", bigint + " NOT NULL DEFAULT 0"},
+			columnDef{p.prefix + "_count", "INTEGER NOT NULL DEFAULT 0"},
+		)
+	}
+	return defs
+}
+
+// rollupTable 聚合粒度对应的表名
+func rollupTable(g RollupGranularity) string {
+	if g == RollupDaily {
+		return "probe_rollup_daily"
+	}
+	return "probe_rollup_hourly"
+}
+
+// RollupBucketStart 计算时间戳所在聚合 bucket 的起始时间
+func RollupBucketStart(g RollupGranularity, ts int64) int64 {
+	if g == RollupDaily {
+		return dayStart(ts, time.Local)
+	}
+	return ts - ts%3600
+}
+
+// dayStart 时间戳所在日历日的本地零点（增量写入与重建共用，与 API 按日历日划分的时间轴一致）
+func dayStart(ts int64, loc *time.Location) int64 {
+	y, m, d := time.Unix(ts, 0).In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc).Unix()
+}
+
+// nextDayStart 下一个日历日的本地零点（夏令时切换日不是 24 小时）
+func nextDayStart(day int64, loc *time.Location) int64 {
+	y, m, d := time.Unix(day, 0).In(loc).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc).Unix()
+}
+
+// offsetDayExpr 固定时区偏移下计算本地零点的 SQL 表达式
+func offsetDayExpr(offset int) string {
+	return fmt.Sprintf("((timestamp + %d) / 86400) * 86400 - %d", offset, offset)
+}
+
+// dayBucketExpr 回填天聚合时计算 bucket 起始时间的 SQL 表达式，对 [from, to] 内的时间戳与 dayStart 结果一致
+// 偏移不变的连续 24 小时日合并为一个按偏移计算的分支，夏令时切换日等不规则日单独列出零点
+func dayBucketExpr(from, to int64, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	day := dayStart(from, loc)
+	var offset int
+	for day <= to {
+		next := nextDayStart(day, loc)
+		_, offset = time.Unix(day, 0).In(loc).Zone()
+		if next-day != 86400 || (day+int64(offset))%86400 != 0 {
+			fmt.Fprintf(&b, " WHEN timestamp < %d THEN %d", next, day)
+			day = next
+			continue
+		}
+		end := next
+		for end <= to {
+			n := nextDayStart(end, loc)
+			_, o := time.Unix(end, 0).In(loc).Zone()
+			if o != offset || n-end != 86400 {
+				break
+			}
+			end = n
+		}
+		fmt.Fprintf(&b, " WHEN timestamp < %d THEN %s", end, offsetDayExpr(offset))
+		day = end
+	}
+	fmt.Fprintf(&b, " ELSE %s END", offsetDayExpr(offset))
+	return b.String()
+}
+
+// rollupBucketExpr 回填聚合表时计算 bucket 起始时间的 SQL 表达式（from/to 为待回填记录的时间戳范围）
+func rollupBucketExpr(g RollupGranularity, from, to int64) string {
+	if g == RollupDaily {
+		return dayBucketExpr(from, to, time.Local)
+	}
+	return "(timestamp / 3600) * 3600"
+}
+
+// rollupSchema 聚合表结构（bigint 为时间戳与累加值的列类型）
+func rollupSchema(g RollupGranularity, bigint string) string {
+	var phases strings.Builder
+	for _, def := range rollupPhaseColumnDefs(bigint) {
+		fmt.Fprintf(&phases, "\t\t%s %s,\n", def.name, def.definition)
+	}
+	return fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		provider TEXT NOT NULL,
+		service TEXT NOT NULL,
+		channel TEXT NOT NULL DEFAULT '',
+		bucket_start %[2]s NOT NULL,
+		status INTEGER NOT NULL,
+		sub_status TEXT NOT NULL DEFAULT '',
+		probe_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum %[2]s NOT NULL DEFAULT 0,
+		latency_min INTEGER NOT NULL DEFAULT 0,
+		latency_max INTEGER NOT NULL DEFAULT 0,
+%[3]s		last_timestamp %[2]s NOT NULL DEFAULT 0,
+		PRIMARY KEY (provider, service, channel, bucket_start, status, sub_status)
+	);
+	`, rollupTable(g), bigint, phases.String())
+}
+
+// rollupColumns 聚合表查询列（顺序需与 scanRollupRecord 保持一致）
+var rollupColumns = strings.Join(append(append(
+	[]string{"bucket_start", "status", "sub_status", "probe_count", "latency_sum", "latency_min", "latency_max"},
+	rollupPhaseColumns()...), "last_timestamp"), ", ")
+
+// scanRollupRecord 按 rollupColumns 的顺序扫描一条聚合记录
+func scanRollupRecord(row rowScanner) (*RollupRecord, error) {
+	var r RollupRecord
+	var subStatusStr string
+	dest := []any{&r.BucketStart, &r.Status, &subStatusStr, &r.Count, &r.LatencySum, &r.LatencyMin, &r.LatencyMax}
+	for _, p := range rollupPhases {
+		phase := p.rollup(&r)
+		dest = append(dest, &phase.Sum, &phase.Count)
+	}
+	dest = append(dest, &r.LastTimestamp)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	r.SubStatus = SubStatus(subStatusStr)
+	return &r, nil
+}
+
+// rollupInsertColumns 聚合表写入列（顺序与 rollupUpsertArgs 及回填 SELECT 一致）
+var rollupInsertColumns = append(append(
+	[]string{"provider", "service", "channel", "bucket_start", "status", "sub_status", "probe_count",
+		"latency_sum", "latency_min", "latency_max"},
+	rollupPhaseColumns()...), "last_timestamp")
+
+// rollupUpsertSQL 将一条探测记录累加到聚合表（placeholder 生成第 n 个参数占位符）
+func rollupUpsertSQL(g RollupGranularity, placeholder func(n int) string) string {
+	t := rollupTable(g)
+	args := make([]string, len(rollupInsertColumns))
+	for i := range args {
+		args[i] = placeholder(i + 1)
+	}
+
+	updates := []string{
+		fmt.Sprintf("probe_count = %[1]s.probe_count + excluded.probe_count", t),
+		fmt.Sprintf("latency_sum = %[1]s.latency_sum + excluded.latency_sum", t),
+		fmt.Sprintf("latency_min = CASE WHEN excluded.latency_min < %[1]s.latency_min THEN excluded.latency_min ELSE %[1]s.latency_min END", t),
+		fmt.Sprintf("latency_max = CASE WHEN excluded.latency_max > %[1]s.latency_max THEN excluded.latency_max ELSE %[1]s.latency_max END", t),
+	}
+	for _, col := range rollupPhaseColumns() {
+		updates = append(updates, fmt.Sprintf("%[2]s = %[1]s.%[2]s + excluded.%[2]s", t, col))
+	}
+	updates = append(updates, fmt.Sprintf(
+		"last_timestamp = CASE WHEN excluded.last_timestamp > %[1]s.last_timestamp THEN excluded.last_timestamp ELSE %[1]s.last_timestamp END", t))
+
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (%s)
+		ON CONFLICT (provider, service, channel, bucket_start, status, sub_status) DO UPDATE SET
+			%s
+	`, t, strings.Join(rollupInsertColumns, ", "), strings.Join(args, ", "), strings.Join(updates, ",\n\t\t\t"))
+}
+
+// rollupUpsertArgs 探测记录对应的聚合参数（顺序与 rollupInsertColumns 一致）
+func rollupUpsertArgs(g RollupGranularity, record *ProbeRecord) []any {
+	args := []any{
+		record.Provider, record.Service, record.Channel,
+		RollupBucketStart(g, record.Timestamp),
+		record.Status, string(record.SubStatus), 1,
+		record.Latency, record.Latency, record.Latency,
+	}
+	for _, p := range rollupPhases {
+		if v := p.record(record); v > 0 {
+			args = append(args, v, 1)
+		} else {
+			args = append(args, 0, 0)
+		}
+	}
+	return append(args, record.Timestamp)
+}
+
+// rollupRebuildSQL 从 probe_history 重建聚合数据（filtered 为 true 时按 provider/service 过滤，from/to 为待回填记录的时间戳范围）
+func rollupRebuildSQL(g RollupGranularity, filtered bool, from, to int64, placeholder func(n int) string) (deleteSQL, insertSQL string) {
+	t := rollupTable(g)
+	where := rollupRebuildFilter(filtered, placeholder)
+
+	selects := []string{"provider", "service", "channel", "(" + rollupBucketExpr(g, from, to) + ") AS bucket_start", "status", "sub_status",
+		"COUNT(*)", "SUM(latency)", "MIN(latency)", "MAX(latency)"}
+	for _, p := range rollupPhases {
+		selects = append(selects,
+			fmt.Sprintf("SUM(CASE WHEN %[1]s > 0 THEN %[1]s ELSE 0 END)", p.source),
+			fmt.Sprintf("SUM(CASE WHEN %s > 0 THEN 1 ELSE 0 END)", p.source),
+		)
+	}
+	selects = append(selects, "MAX(timestamp)")
+
+	deleteSQL = "DELETE FROM " + t + where
+	insertSQL = fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		SELECT %s
+		FROM probe_history%s
+		GROUP BY provider, service, channel, bucket_start, status, sub_status
+	`, t, strings.Join(rollupInsertColumns, ", "), strings.Join(selects, ",\n\t\t\t"), where)
+	return deleteSQL, insertSQL
+}
+
+// rollupRebuildRangeSQL 查询待回填记录的时间戳范围（用于生成天粒度的桶边界，没有记录时返回 0）
+func rollupRebuildRangeSQL(filtered bool, placeholder func(n int) string) string {
+	return "SELECT COALESCE(MIN(timestamp), 0), COALESCE(MAX(timestamp), 0) FROM probe_history" + rollupRebuildFilter(filtered, placeholder)
+}
+
+// rollupRebuildFilter 重建时按 provider/service 过滤的 WHERE 子句
+func rollupRebuildFilter(filtered bool, placeholder func(n int) string) string {
+	if !filtered {
+		return ""
+	}
+	return fmt.Sprintf(" WHERE provider = %s AND service = %s", placeholder(1), placeholder(2))
+}
+
+// rollupQuerySQL 查询时间范围内的聚合记录（[since, until)，按 bucket 升序）
+func rollupQuerySQL(g RollupGranularity, placeholder func(n int) string) string {
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE provider = %s AND service = %s AND channel = %s AND bucket_start >= %s AND bucket_start < %s
+		ORDER BY bucket_start ASC
+	`, rollupColumns, rollupTable(g), placeholder(1), placeholder(2), placeholder(3), placeholder(4), placeholder(5))
+}
+
+// rollupCleanSQL 删除 bucket 起始时间早于截止时间的聚合记录
+func rollupCleanSQL(g RollupGranularity, placeholder func(n int) string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE bucket_start < %s", rollupTable(g), placeholder(1))
+}
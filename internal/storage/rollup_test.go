@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRollupsMaintainedOnSave(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	base := int64(1735689600) // 2025-01-01T00:00:00Z
+	records := []*ProbeRecord{
+		{Status: 1, Latency: 100, Timestamp: base + 60, FirstByteLatency: 50},
+		{Status: 1, Latency: 300, Timestamp: base + 120},
+		{Status: 0, SubStatus: SubStatusServerError, Latency: 900, Timestamp: base + 180},
+		{Status: 1, Latency: 200, Timestamp: base + 3600 + 60, FirstByteLatency: 70},
+	}
+	for _, r := range records {
+		r.Provider, r.Service, r.Channel = "demo", "cc", "vip"
+		if err := store.SaveRecord(r); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+	}
+
+	hourly, err := store.GetRollups("demo", "cc", "vip", RollupHourly, time.Unix(base, 0), time.Unix(base+7200, 0))
+	if err != nil {
+		t.Fatalf("get rollups: %v", err)
+	}
+	if len(hourly) != 3 {
+		t.Fatalf("expected 3 hourly rollup rows, got %d", len(hourly))
+	}
+	green := hourly[0]
+	if green.Status != 1 {
+		green = hourly[1]
+	}
+	if green.BucketStart != base || green.Count != 2 || green.LatencySum != 400 || green.LatencyMin != 100 ||
+		green.LatencyMax != 300 || green.FirstByte != (PhaseSum{Sum: 50, Count: 1}) || green.LastTimestamp != base+120 {
+		t.Fatalf("unexpected hourly rollup: %+v", green)
+	}
+
+	daily, err := store.GetRollups("demo", "cc", "vip", RollupDaily, time.Unix(base-86400, 0), time.Unix(base+86400, 0))
+	if err != nil {
+		t.Fatalf("get rollups: %v", err)
+	}
+	total := 0
+	for _, r := range daily {
+		total += r.Count
+		if r.BucketStart != RollupBucketStart(RollupDaily, base+60) {
+			t.Fatalf("unexpected daily bucket start: %d", r.BucketStart)
+		}
+	}
+	if total != 4 {
+		t.Fatalf("expected 4 probes in daily rollups, got %d", total)
+	}
+
+	// 重建（回填）结果应与增量维护一致
+	for _, g := range rollupGranularities {
+		before, _ := store.GetRollups("demo", "cc", "vip", g, time.Unix(0, 0), time.Unix(base+86400*2, 0))
+		if err := store.rebuildRollups(g, "", ""); err != nil {
+			t.Fatalf("rebuild rollups: %v", err)
+		}
+		after, _ := store.GetRollups("demo", "cc", "vip", g, time.Unix(0, 0), time.Unix(base+86400*2, 0))
+		if !reflect.DeepEqual(before, after) {
+			t.Fatalf("%s: rebuilt rollups differ from incremental ones:\n%+v\n%+v", g, before, after)
+		}
+	}
+}
+
+func TestRollupsMigrateChannel(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	if err := store.SaveRecord(&ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Latency: 100, Timestamp: 1735689660}); err != nil {
+		t.Fatalf("save record: %v", err)
+	}
+	if err := store.MigrateChannelData([]ChannelMigrationMapping{{Provider: "demo", Service: "cc", Channel: "vip"}}); err != nil {
+		t.Fatalf("migrate channel: %v", err)
+	}
+
+	rollups, err := store.GetRollups("demo", "cc", "vip", RollupHourly, time.Unix(0, 0), time.Unix(1735776000, 0))
+	if err != nil {
+		t.Fatalf("get rollups: %v", err)
+	}
+	if len(rollups) != 1 || rollups[0].Count != 1 {
+		t.Fatalf("expected migrated rollup, got %+v", rollups)
+	}
+}
+
+func TestDayBucketExprMatchesDayStartAcrossDST(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	store := NewTestSQLite(t)
+
+	// 覆盖 2025-03-09 和 2025-11-02 两次切换（切换日分别为 23 和 25 小时）
+	from := time.Date(2025, 3, 6, 0, 0, 0, 0, loc).Unix()
+	to := time.Date(2025, 11, 5, 0, 0, 0, 0, loc).Unix()
+	expr := dayBucketExpr(from, to, loc)
+	for ts := from; ts <= to; ts += 1800 {
+		var got int64
+		if err := store.db.QueryRow("SELECT "+expr+" FROM (SELECT ? AS timestamp)", ts).Scan(&got); err != nil {
+			t.Fatalf("evaluate day bucket expr: %v", err)
+		}
+		if want := dayStart(ts, loc); got != want {
+			t.Fatalf("ts %d (%s): bucket %d, want %d", ts, time.Unix(ts, 0).In(loc), got, want)
+		}
+	}
+}
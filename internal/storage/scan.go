@@ -0,0 +1,68 @@
+package storage
+
+// probeColumns probe_history 查询列（顺序需与 scanProbeRecord 保持一致）
+const probeColumns = `id, provider, service, channel, status, sub_status, latency, timestamp,
+		first_byte_latency, first_token_latency, stream_duration,
+		dns_duration, connect_duration, tls_duration, body_read_duration,
+		http_status, error_message, response_snippet, attempts, region`
+
+// rowScanner 兼容 database/sql 与 pgx 的行扫描接口
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProbeRecord 按 probeColumns 的顺序扫描一条探测记录
+func scanProbeRecord(row rowScanner) (*ProbeRecord, error) {
+	var record ProbeRecord
+	var subStatusStr string
+	if err := row.Scan(
+		&record.ID,
+		&record.Provider,
+		&record.Service,
+		&record.Channel,
+		&record.Status,
+		&subStatusStr,
+		&record.Latency,
+		&record.Timestamp,
+		&record.FirstByteLatency,
+		&record.FirstTokenLatency,
+		&record.StreamDuration,
+		&record.DNSDuration,
+		&record.ConnectDuration,
+		&record.TLSDuration,
+		&record.BodyReadDuration,
+		&record.HTTPStatus,
+		&record.ErrorMessage,
+		&record.ResponseSnippet,
+		&record.Attempts,
+		&record.Region,
+	); err != nil {
+		return nil, err
+	}
+	record.SubStatus = SubStatus(subStatusStr)
+	return &record, nil
+}
+
+// columnDef 表列定义（用于旧库补列）
+type columnDef struct {
+	name       string
+	definition string
+}
+
+// probeHistoryColumns 后续版本新增的 probe_history 列，Init 时逐一补齐
+var probeHistoryColumns = []columnDef{
+	{"sub_status", "TEXT NOT NULL DEFAULT ''"},
+	{"channel", "TEXT NOT NULL DEFAULT ''"},
+	{"first_byte_latency", "INTEGER NOT NULL DEFAULT 0"},
+	{"first_token_latency", "INTEGER NOT NULL DEFAULT 0"},
+	{"stream_duration", "INTEGER NOT NULL DEFAULT 0"},
+	{"dns_duration", "INTEGER NOT NULL DEFAULT 0"},
+	{"connect_duration", "INTEGER NOT NULL DEFAULT 0"},
+	{"tls_duration", "INTEGER NOT NULL DEFAULT 0"},
+	{"body_read_duration", "INTEGER NOT NULL DEFAULT 0"},
+	{"http_status", "INTEGER NOT NULL DEFAULT 0"},
+	{"error_message", "TEXT NOT NULL DEFAULT ''"},
+	{"response_snippet", "TEXT NOT NULL DEFAULT ''"},
+	{"attempts", "INTEGER NOT NULL DEFAULT 1"},
+	{"region", "TEXT NOT NULL DEFAULT '" + DefaultRegion + "'"},
+}
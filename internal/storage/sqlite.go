@@ -14,6 +14,9 @@ type SQLiteStorage struct {
 	db *sql.DB
 }
 
+// sqlitePlaceholder SQLite 参数占位符
+func sqlitePlaceholder(int) string { return "?" }
+
 // NewSQLiteStorage 创建SQLite存储
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	// 使用WAL模式和其他参数解决并发锁问题
@@ -42,7 +45,19 @@ func (s *SQLiteStorage) Init() error {
 		status INTEGER NOT NULL,
 		sub_status TEXT NOT NULL DEFAULT '',
 		latency INTEGER NOT NULL,
-		timestamp INTEGER NOT NULL
+		timestamp INTEGER NOT NULL,
+		first_byte_latency INTEGER NOT NULL DEFAULT 0,
+		first_token_latency INTEGER NOT NULL DEFAULT 0,
+		stream_duration INTEGER NOT NULL DEFAULT 0,
+		dns_duration INTEGER NOT NULL DEFAULT 0,
+		connect_duration INTEGER NOT NULL DEFAULT 0,
+		tls_duration INTEGER NOT NULL DEFAULT 0,
+		body_read_duration INTEGER NOT NULL DEFAULT 0,
+		http_status INTEGER NOT NULL DEFAULT 0,
+		error_message TEXT NOT NULL DEFAULT '',
+		response_snippet TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 1,
+		region TEXT NOT NULL DEFAULT 'local'
 	);
 	`
 
@@ -52,11 +67,10 @@ func (s *SQLiteStorage) Init() error {
 	}
 
 	// 兼容旧数据库：添加缺失的列
-	if err := s.ensureSubStatusColumn(); err != nil {
-		return err
-	}
-	if err := s.ensureChannelColumn(); err != nil {
-		return err
+	for _, col := range probeHistoryColumns {
+		if err := s.ensureColumn("probe_history", col.name, col.definition); err != nil {
+			return err
+		}
 	}
 
 	// 在列迁移完成后创建索引
@@ -68,56 +82,132 @@ func (s *SQLiteStorage) Init() error {
 		return fmt.Errorf("创建索引失败: %w", err)
 	}
 
+	// 故障事件表
+	incidentSQL := `
+	CREATE TABLE IF NOT EXISTS incidents (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		provider TEXT NOT NULL,
+		service TEXT NOT NULL,
+		channel TEXT NOT NULL DEFAULT '',
+		start_time INTEGER NOT NULL,
+		end_time INTEGER NOT NULL DEFAULT 0,
+		sub_status TEXT NOT NULL DEFAULT '',
+		peak_latency INTEGER NOT NULL DEFAULT 0,
+		probe_count INTEGER NOT NULL DEFAULT 0,
+		region TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_incidents_provider_start
+	ON incidents(provider, service, channel, start_time DESC);
+	CREATE INDEX IF NOT EXISTS idx_incidents_start ON incidents(start_time DESC);
+	`
+	if _, err := s.db.Exec(incidentSQL); err != nil {
+		return fmt.Errorf("创建故障事件表失败: %w", err)
+	}
+	for _, col := range incidentColumnDefs {
+		if err := s.ensureColumn("incidents", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	// TLS 证书表（每个监控项保留最近一次观察到的证书）
+	if _, err := s.db.Exec(certificateTableSQL("INTEGER")); err != nil {
+		return fmt.Errorf("创建证书表失败: %w", err)
+	}
+
+	// 租约表（多副本主节点选举）
+	if _, err := s.db.Exec(leaseTableSQL("INTEGER")); err != nil {
+		return fmt.Errorf("创建租约表失败: %w", err)
+	}
+
+	// 维护窗口表（通过 API 创建的窗口）
+	if _, err := s.db.Exec(maintenanceTableSQL("INTEGER PRIMARY KEY AUTOINCREMENT", "INTEGER")); err != nil {
+		return fmt.Errorf("创建维护窗口表失败: %w", err)
+	}
+
+	// 监控项表（monitor_store 模式）
+	if _, err := s.db.Exec(monitorTableSQL("INTEGER PRIMARY KEY AUTOINCREMENT", "INTEGER")); err != nil {
+		return fmt.Errorf("创建监控项表失败: %w", err)
+	}
+
+	// 小时/天聚合表（长时间范围查询直接读取聚合数据）
+	for _, g := range rollupGranularities {
+		if err := s.initRollupTable(g); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// ensureSubStatusColumn 在旧表上添加 sub_status 列（向后兼容）
-func (s *SQLiteStorage) ensureSubStatusColumn() error {
-	rows, err := s.db.Query(`PRAGMA table_info(probe_history)`)
-	if err != nil {
-		return fmt.Errorf("查询表结构失败: %w", err)
+// initRollupTable 创建聚合表，新建时从 probe_history 回填历史数据
+func (s *SQLiteStorage) initRollupTable(g RollupGranularity) error {
+	table := rollupTable(g)
+
+	var exists int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&exists); err != nil {
+		return fmt.Errorf("检查聚合表失败: %w", err)
 	}
-	defer rows.Close()
 
-	hasColumn := false
-	for rows.Next() {
-		var (
-			cid          int
-			name         string
-			colType      string
-			notNull      int
-			defaultValue sql.NullString
-			pk           int
-		)
-		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
-			return fmt.Errorf("扫描表结构失败: %w", err)
+	if _, err := s.db.Exec(rollupSchema(g, "INTEGER")); err != nil {
+		return fmt.Errorf("创建聚合表 %s 失败: %w", table, err)
+	}
+
+	// 兼容旧聚合表：添加后续版本新增的分阶段延迟列
+	for _, col := range rollupPhaseColumnDefs("INTEGER") {
+		if err := s.ensureColumn(table, col.name, col.definition); err != nil {
+			return err
 		}
-		if name == "sub_status" {
-			hasColumn = true
-			break
+	}
+
+	if exists == 0 {
+		if err := s.rebuildRollups(g, "", ""); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("遍历表结构失败: %w", err)
+// rebuildRollups 从 probe_history 重建聚合数据（provider 为空时重建全部）
+func (s *SQLiteStorage) rebuildRollups(g RollupGranularity, provider, service string) error {
+	filtered := provider != ""
+	var args []any
+	if filtered {
+		args = []any{provider, service}
 	}
 
-	if hasColumn {
-		return nil // 列已存在，无需添加
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
 	}
+	defer tx.Rollback()
 
-	// 添加列
-	if _, err := s.db.Exec(`ALTER TABLE probe_history ADD COLUMN sub_status TEXT NOT NULL DEFAULT ''`); err != nil {
-		return fmt.Errorf("添加 sub_status 列失败: %w", err)
+	// 天粒度的桶边界按记录时间范围内的每个本地日历日生成
+	var from, to int64
+	if err := tx.QueryRow(rollupRebuildRangeSQL(filtered, sqlitePlaceholder), args...).Scan(&from, &to); err != nil {
+		return fmt.Errorf("查询探测记录时间范围失败: %w", err)
+	}
+	deleteSQL, insertSQL := rollupRebuildSQL(g, filtered, from, to, sqlitePlaceholder)
+
+	if _, err := tx.Exec(deleteSQL, args...); err != nil {
+		return fmt.Errorf("清理聚合表 %s 失败: %w", rollupTable(g), err)
+	}
+	result, err := tx.Exec(insertSQL, args...)
+	if err != nil {
+		return fmt.Errorf("回填聚合表 %s 失败: %w", rollupTable(g), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交聚合表 %s 失败: %w", rollupTable(g), err)
 	}
 
-	fmt.Println("[Storage] 已为 probe_history 表添加 sub_status 列")
+	if rows, _ := result.RowsAffected(); rows > 0 {
+		log.Printf("[Storage] 已重建 %s 聚合数据 %d 行", rollupTable(g), rows)
+	}
 	return nil
 }
 
-// ensureChannelColumn 在旧表上添加 channel 列（向后兼容）
-func (s *SQLiteStorage) ensureChannelColumn() error {
-	rows, err := s.db.Query(`PRAGMA table_info(probe_history)`)
+// ensureColumn 在旧表上添加缺失的列（向后兼容）
+func (s *SQLiteStorage) ensureColumn(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
 	if err != nil {
 		return fmt.Errorf("查询表结构失败: %w", err)
 	}
@@ -136,7 +226,7 @@ func (s *SQLiteStorage) ensureChannelColumn() error {
 		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
 			return fmt.Errorf("扫描表结构失败: %w", err)
 		}
-		if name == "channel" {
+		if name == column {
 			hasColumn = true
 			break
 		}
@@ -151,11 +241,11 @@ func (s *SQLiteStorage) ensureChannelColumn() error {
 	}
 
 	// 添加列
-	if _, err := s.db.Exec(`ALTER TABLE probe_history ADD COLUMN channel TEXT NOT NULL DEFAULT ''`); err != nil {
-		return fmt.Errorf("添加 channel 列失败: %w", err)
+	if _, err := s.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return fmt.Errorf("添加 %s 列失败: %w", column, err)
 	}
 
-	fmt.Println("[Storage] 已为 probe_history 表添加 channel 列")
+	fmt.Printf("[Storage] 已为 %s 表添加 %s 列\n", table, column)
 	return nil
 }
 
@@ -202,6 +292,12 @@ func (s *SQLiteStorage) MigrateChannelData(mappings []ChannelMigrationMapping) e
 				"[Storage] 已迁移 %d 条记录 -> channel=%s (provider=%s, service=%s)",
 				affected, mapping.Channel, mapping.Provider, mapping.Service,
 			)
+			// 聚合数据同样需要迁移到新的 channel
+			for _, g := range rollupGranularities {
+				if err := s.rebuildRollups(g, mapping.Provider, mapping.Service); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -227,53 +323,77 @@ func (s *SQLiteStorage) Close() error {
 
 // SaveRecord 保存探测记录
 func (s *SQLiteStorage) SaveRecord(record *ProbeRecord) error {
+	return s.SaveRecords([]*ProbeRecord{record})
+}
+
+// SaveRecords 在同一事务中保存多条探测记录（任一失败时全部回滚）
+func (s *SQLiteStorage) SaveRecords(records []*ProbeRecord) error {
 	query := `
-		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp,
+			first_byte_latency, first_token_latency, stream_duration,
+			dns_duration, connect_duration, tls_duration, body_read_duration,
+			http_status, error_message, response_snippet, attempts, region)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := s.db.Exec(query,
-		record.Provider,
-		record.Service,
-		record.Channel,
-		record.Status,
-		string(record.SubStatus),
-		record.Latency,
-		record.Timestamp,
-	)
-
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("保存记录失败: %w", err)
+		return fmt.Errorf("开启事务失败: %w", err)
 	}
+	defer tx.Rollback()
+
+	ids := make([]int64, len(records))
+	for i, record := range records {
+		result, err := tx.Exec(query,
+			record.Provider,
+			record.Service,
+			record.Channel,
+			record.Status,
+			string(record.SubStatus),
+			record.Latency,
+			record.Timestamp,
+			record.FirstByteLatency,
+			record.FirstTokenLatency,
+			record.StreamDuration,
+			record.DNSDuration,
+			record.ConnectDuration,
+			record.TLSDuration,
+			record.BodyReadDuration,
+			record.HTTPStatus,
+			record.ErrorMessage,
+			record.ResponseSnippet,
+			record.Attempts,
+			recordRegion(record),
+		)
 
-	id, _ := result.LastInsertId()
-	record.ID = id
+		if err != nil {
+			return fmt.Errorf("保存记录失败: %w", err)
+		}
+
+		// 累加到聚合表
+		for _, g := range rollupGranularities {
+			if _, err := tx.Exec(rollupUpsertSQL(g, sqlitePlaceholder), rollupUpsertArgs(g, record)...); err != nil {
+				return fmt.Errorf("更新聚合表 %s 失败: %w", rollupTable(g), err)
+			}
+		}
+		ids[i], _ = result.LastInsertId()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交记录失败: %w", err)
+	}
+
+	for i, record := range records {
+		record.ID = ids[i]
+	}
 	return nil
 }
 
 // GetLatest 获取最新记录
-func (s *SQLiteStorage) GetLatest(provider, service, channel string) (*ProbeRecord, error) {
-	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
-		FROM probe_history
-		WHERE provider = ? AND service = ? AND channel = ?
-		ORDER BY timestamp DESC
-		LIMIT 1
-	`
-
-	var record ProbeRecord
-	var subStatusStr string
-	err := s.db.QueryRow(query, provider, service, channel).Scan(
-		&record.ID,
-		&record.Provider,
-		&record.Service,
-		&record.Channel,
-		&record.Status,
-		&subStatusStr,
-		&record.Latency,
-		&record.Timestamp,
-	)
+func (s *SQLiteStorage) GetLatest(provider, service, channel, region string) (*ProbeRecord, error) {
+	query, args := latestQuery(provider, service, channel, region, sqlitePlaceholder)
 
+	record, err := scanProbeRecord(s.db.QueryRow(query, args...))
 	if err == sql.ErrNoRows {
 		return nil, nil // 没有记录不算错误
 	}
@@ -282,44 +402,64 @@ func (s *SQLiteStorage) GetLatest(provider, service, channel string) (*ProbeReco
 		return nil, fmt.Errorf("查询最新记录失败: %w", err)
 	}
 
-	record.SubStatus = SubStatus(subStatusStr)
-	return &record, nil
+	return record, nil
 }
 
-// GetHistory 获取历史记录
-func (s *SQLiteStorage) GetHistory(provider, service, channel string, since time.Time) ([]*ProbeRecord, error) {
-	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
-		FROM probe_history
-		WHERE provider = ? AND service = ? AND channel = ? AND timestamp >= ?
-		ORDER BY timestamp ASC
-	`
+// GetLatestByRegion 获取各地域的最新记录
+func (s *SQLiteStorage) GetLatestByRegion(provider, service, channel string, since time.Time) ([]*ProbeRecord, error) {
+	query, args := latestByRegionQuery(provider, service, channel, since, sqlitePlaceholder)
+	records, err := s.queryRecords(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询各地域最新记录失败: %w", err)
+	}
+	return dedupeRegions(records), nil
+}
 
-	rows, err := s.db.Query(query, provider, service, channel, since.Unix())
+// GetHistory 获取 [since, until) 内的历史记录
+func (s *SQLiteStorage) GetHistory(provider, service, channel, region string, since, until time.Time) ([]*ProbeRecord, error) {
+	query, args := historyQuery(provider, service, channel, region, since, until, sqlitePlaceholder)
+	records, err := s.queryRecords(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("查询历史记录失败: %w", err)
 	}
+	return records, nil
+}
+
+// GetRecent 获取最近 limit 条探测记录（按时间升序）
+func (s *SQLiteStorage) GetRecent(provider, service, channel, region string, limit int) ([]*ProbeRecord, error) {
+	query, args := recentQuery(provider, service, channel, region, limit, sqlitePlaceholder)
+	records, err := s.queryRecords(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询最近记录失败: %w", err)
+	}
+	return reverseRecords(records), nil
+}
+
+// GetFailures 获取非绿色探测记录及诊断信息
+func (s *SQLiteStorage) GetFailures(provider, service, channel, region string, since, until time.Time, limit int) ([]*ProbeRecord, error) {
+	query, args := failuresQuery(provider, service, channel, region, since, until, limit, sqlitePlaceholder)
+	records, err := s.queryRecords(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询诊断记录失败: %w", err)
+	}
+	return records, nil
+}
+
+// queryRecords 执行查询并扫描全部探测记录
+func (s *SQLiteStorage) queryRecords(query string, args ...any) ([]*ProbeRecord, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var records []*ProbeRecord
 	for rows.Next() {
-		var record ProbeRecord
-		var subStatusStr string
-		err := rows.Scan(
-			&record.ID,
-			&record.Provider,
-			&record.Service,
-			&record.Channel,
-			&record.Status,
-			&subStatusStr,
-			&record.Latency,
-			&record.Timestamp,
-		)
+		record, err := scanProbeRecord(rows)
 		if err != nil {
 			return nil, fmt.Errorf("扫描记录失败: %w", err)
 		}
-		record.SubStatus = SubStatus(subStatusStr)
-		records = append(records, &record)
+		records = append(records, record)
 	}
 
 	// 检查迭代过程中是否发生错误
@@ -330,20 +470,281 @@ func (s *SQLiteStorage) GetHistory(provider, service, channel string, since time
 	return records, nil
 }
 
+// GetRollups 获取聚合记录
+func (s *SQLiteStorage) GetRollups(provider, service, channel string, granularity RollupGranularity, since, until time.Time) ([]*RollupRecord, error) {
+	rows, err := s.db.Query(rollupQuerySQL(granularity, sqlitePlaceholder), provider, service, channel, since.Unix(), until.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("查询聚合记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*RollupRecord
+	for rows.Next() {
+		record, err := scanRollupRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描聚合记录失败: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代聚合记录失败: %w", err)
+	}
+
+	return records, nil
+}
+
 // CleanOldRecords 清理旧记录
-func (s *SQLiteStorage) CleanOldRecords(days int) error {
+func (s *SQLiteStorage) CleanOldRecords(days int) (int64, error) {
 	cutoff := time.Now().AddDate(0, 0, -days).Unix()
 	query := `DELETE FROM probe_history WHERE timestamp < ?`
 
 	result, err := s.db.Exec(query, cutoff)
 	if err != nil {
-		return fmt.Errorf("清理旧记录失败: %w", err)
+		return 0, fmt.Errorf("清理旧记录失败: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
+
+// CleanOldRollups 清理旧聚合数据
+func (s *SQLiteStorage) CleanOldRollups(granularity RollupGranularity, days int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -days).Unix()
+
+	result, err := s.db.Exec(rollupCleanSQL(granularity, sqlitePlaceholder), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("清理 %s 聚合数据失败: %w", rollupTable(granularity), err)
 	}
 
 	deleted, _ := result.RowsAffected()
-	if deleted > 0 {
-		fmt.Printf("[Storage] 已清理 %d 条超过 %d 天的旧记录\n", deleted, days)
+	return deleted, nil
+}
+
+// Optimize 执行 VACUUM/ANALYZE（VACUUM 会重写整个数据库文件）
+func (s *SQLiteStorage) Optimize(vacuum, analyze bool) error {
+	if vacuum {
+		if _, err := s.db.Exec("VACUUM"); err != nil {
+			return fmt.Errorf("VACUUM 失败: %w", err)
+		}
+	}
+	if analyze {
+		if _, err := s.db.Exec("ANALYZE"); err != nil {
+			return fmt.Errorf("ANALYZE 失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// SaveIncident 保存故障事件
+func (s *SQLiteStorage) SaveIncident(incident *Incident) error {
+	if incident.ID == 0 {
+		result, err := s.db.Exec(`
+			INSERT INTO incidents (provider, service, channel, region, start_time, end_time, sub_status, peak_latency, probe_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			incident.Provider, incident.Service, incident.Channel, incident.Region,
+			incident.StartTime, incident.EndTime, string(incident.SubStatus),
+			incident.PeakLatency, incident.ProbeCount,
+		)
+		if err != nil {
+			return fmt.Errorf("保存故障事件失败: %w", err)
+		}
+		incident.ID, _ = result.LastInsertId()
+		return nil
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE incidents SET end_time = ?, sub_status = ?, peak_latency = ?, probe_count = ?
+		WHERE id = ?
+	`, incident.EndTime, string(incident.SubStatus), incident.PeakLatency, incident.ProbeCount, incident.ID)
+	if err != nil {
+		return fmt.Errorf("更新故障事件失败: %w", err)
+	}
+	return nil
+}
+
+// SaveCertificate 保存 TLS 证书信息
+func (s *SQLiteStorage) SaveCertificate(cert *Certificate) error {
+	if _, err := s.db.Exec(certificateUpsertSQL(sqlitePlaceholder), certificateUpsertArgs(cert)...); err != nil {
+		return fmt.Errorf("保存证书信息失败: %w", err)
+	}
+	return nil
+}
+
+// GetCertificate 获取 TLS 证书信息
+func (s *SQLiteStorage) GetCertificate(provider, service, channel string) (*Certificate, error) {
+	cert, err := scanCertificate(s.db.QueryRow(certificateQuerySQL(sqlitePlaceholder), provider, service, channel))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询证书信息失败: %w", err)
+	}
+	return cert, nil
+}
+
+// AcquireLease 获取或续期租约
+func (s *SQLiteStorage) AcquireLease(name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	result, err := s.db.Exec(leaseAcquireSQL(sqlitePlaceholder), leaseAcquireArgs(name, holder, now, ttl)...)
+	if err != nil {
+		return false, fmt.Errorf("获取租约失败: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("获取租约失败: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseLease 释放租约
+func (s *SQLiteStorage) ReleaseLease(name, holder string) error {
+	if _, err := s.db.Exec(leaseReleaseSQL(sqlitePlaceholder), name, holder); err != nil {
+		return fmt.Errorf("释放租约失败: %w", err)
+	}
+	return nil
+}
+
+// GetLease 查询租约
+func (s *SQLiteStorage) GetLease(name string) (*Lease, error) {
+	var lease Lease
+	err := s.db.QueryRow(leaseQuerySQL(sqlitePlaceholder), name).Scan(&lease.Name, &lease.Holder, &lease.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询租约失败: %w", err)
+	}
+	return &lease, nil
+}
+
+// SaveMaintenanceWindow 新建维护窗口
+func (s *SQLiteStorage) SaveMaintenanceWindow(w *MaintenanceWindow) error {
+	result, err := s.db.Exec(maintenanceInsertSQL(sqlitePlaceholder), maintenanceInsertArgs(w)...)
+	if err != nil {
+		return fmt.Errorf("保存维护窗口失败: %w", err)
+	}
+	w.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// GetMaintenanceWindows 查询全部维护窗口
+func (s *SQLiteStorage) GetMaintenanceWindows() ([]*MaintenanceWindow, error) {
+	rows, err := s.db.Query(maintenanceQuerySQL)
+	if err != nil {
+		return nil, fmt.Errorf("查询维护窗口失败: %w", err)
 	}
+	defer rows.Close()
 
+	var windows []*MaintenanceWindow
+	for rows.Next() {
+		w, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描维护窗口失败: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代维护窗口失败: %w", err)
+	}
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow 删除维护窗口
+func (s *SQLiteStorage) DeleteMaintenanceWindow(id int64) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("删除维护窗口失败: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("删除维护窗口失败: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SaveMonitor 新建或覆盖监控项定义
+func (s *SQLiteStorage) SaveMonitor(m *MonitorDefinition) error {
+	return s.SaveMonitors([]*MonitorDefinition{m})
+}
+
+// SaveMonitors 在同一事务中新建或覆盖多个监控项定义
+func (s *SQLiteStorage) SaveMonitors(monitors []*MonitorDefinition) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := monitorUpsertSQL(sqlitePlaceholder)
+	for _, m := range monitors {
+		if _, err := tx.Exec(query, monitorUpsertArgs(m)...); err != nil {
+			return fmt.Errorf("保存监控项失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交监控项失败: %w", err)
+	}
 	return nil
 }
+
+// GetMonitors 查询全部监控项定义
+func (s *SQLiteStorage) GetMonitors() ([]*MonitorDefinition, error) {
+	rows, err := s.db.Query(monitorQuerySQL)
+	if err != nil {
+		return nil, fmt.Errorf("查询监控项失败: %w", err)
+	}
+	defer rows.Close()
+
+	var monitors []*MonitorDefinition
+	for rows.Next() {
+		m, err := scanMonitorDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描监控项失败: %w", err)
+		}
+		monitors = append(monitors, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代监控项失败: %w", err)
+	}
+	return monitors, nil
+}
+
+// DeleteMonitor 删除监控项定义
+func (s *SQLiteStorage) DeleteMonitor(provider, service, channel string) (bool, error) {
+	result, err := s.db.Exec(monitorDeleteSQL(sqlitePlaceholder), provider, service, channel)
+	if err != nil {
+		return false, fmt.Errorf("删除监控项失败: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("删除监控项失败: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetIncidents 查询故障事件
+func (s *SQLiteStorage) GetIncidents(filter IncidentFilter) ([]*Incident, error) {
+	query, args := buildIncidentQuery(filter, sqlitePlaceholder)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询故障事件失败: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []*Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描故障事件失败: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代故障事件失败: %w", err)
+	}
+
+	return incidents, nil
+}
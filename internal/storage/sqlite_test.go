@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFailuresReturnsDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	records := []*ProbeRecord{
+		{Status: 1, Latency: 100, Timestamp: 1000},
+		{Status: 0, SubStatus: SubStatusInvalidRequest, Latency: 200, Timestamp: 1060,
+			HTTPStatus: 400, ResponseSnippet: `{"error":"model not found"}`},
+		{Status: 2, SubStatus: SubStatusRateLimit, Latency: 300, Timestamp: 1120,
+			HTTPStatus: 429, ResponseSnippet: `{"error":"quota exhausted"}`},
+		{Status: 0, SubStatus: SubStatusNetworkError, Latency: 5000, Timestamp: 1180,
+			ErrorMessage: "dial tcp: connection refused", Attempts: 3},
+	}
+	for _, r := range records {
+		r.Provider, r.Service = "demo", "cc"
+		if err := store.SaveRecord(r); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+	}
+
+	got, err := store.GetFailures("demo", "cc", "", "", time.Unix(1000, 0), time.Unix(2000, 0), 2)
+	if err != nil {
+		t.Fatalf("get failures: %v", err)
+	}
+	if len(got) != 2 || got[0].Timestamp != 1180 || got[1].Timestamp != 1120 {
+		t.Fatalf("unexpected failures: %+v", got)
+	}
+	if got[0].ErrorMessage != "dial tcp: connection refused" || got[0].Attempts != 3 || got[1].HTTPStatus != 429 ||
+		got[1].ResponseSnippet != `{"error":"quota exhausted"}` {
+		t.Fatalf("diagnostics not persisted: %+v %+v", got[0], got[1])
+	}
+
+	latest, err := store.GetLatest("demo", "cc", "", "")
+	if err != nil || latest == nil || latest.ErrorMessage != "dial tcp: connection refused" {
+		t.Fatalf("unexpected latest record: %+v, %v", latest, err)
+	}
+}
+
+func TestRegionFilters(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	records := []*ProbeRecord{
+		{Status: 1, Latency: 100, Timestamp: 1000},
+		{Status: 0, SubStatus: SubStatusNetworkError, Latency: 5000, Timestamp: 1010, Region: "shanghai"},
+		{Status: 1, Latency: 120, Timestamp: 1060},
+		{Status: 0, SubStatus: SubStatusNetworkError, Latency: 5000, Timestamp: 1070, Region: "shanghai"},
+		{Status: 1, Latency: 300, Timestamp: 1080, Region: "frankfurt"},
+	}
+	for _, r := range records {
+		r.Provider, r.Service = "demo", "cc"
+		if err := store.SaveRecord(r); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+	}
+
+	all, err := store.GetHistory("demo", "cc", "", "", time.Unix(0, 0), time.Now())
+	if err != nil || len(all) != 5 {
+		t.Fatalf("expected all regions, got %d (err=%v)", len(all), err)
+	}
+	shanghai, err := store.GetHistory("demo", "cc", "", "shanghai", time.Unix(0, 0), time.Now())
+	if err != nil || len(shanghai) != 2 || shanghai[0].Region != "shanghai" {
+		t.Fatalf("unexpected shanghai history: %+v (err=%v)", shanghai, err)
+	}
+
+	latest, err := store.GetLatest("demo", "cc", "", DefaultRegion)
+	if err != nil || latest == nil || latest.Timestamp != 1060 {
+		t.Fatalf("records without region should belong to %q: %+v (err=%v)", DefaultRegion, latest, err)
+	}
+	failures, err := store.GetFailures("demo", "cc", "", DefaultRegion, time.Unix(0, 0), time.Unix(2000, 0), 10)
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("expected no local failures, got %+v (err=%v)", failures, err)
+	}
+
+	byRegion, err := store.GetLatestByRegion("demo", "cc", "", time.Unix(1020, 0))
+	if err != nil {
+		t.Fatalf("get latest by region: %v", err)
+	}
+	var got []string
+	for _, r := range byRegion {
+		got = append(got, fmt.Sprintf("%s@%d", r.Region, r.Timestamp))
+	}
+	if want := "frankfurt@1080 local@1060 shanghai@1070"; strings.Join(got, " ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestSaveCertificateUpserts(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	if got, err := store.GetCertificate("demo", "cc", ""); err != nil || got != nil {
+		t.Fatalf("expected no certificate, got %+v (err=%v)", got, err)
+	}
+
+	cert := &Certificate{Provider: "demo", Service: "cc", Subject: "CN=old", Issuer: "CN=CA", NotAfter: 2000, CheckedAt: 1000}
+	if err := store.SaveCertificate(cert); err != nil {
+		t.Fatalf("save certificate: %v", err)
+	}
+	renewed := *cert
+	renewed.Subject, renewed.NotAfter, renewed.TLSVersion, renewed.CheckedAt = "CN=new", 5000, "TLS 1.3", 1060
+	if err := store.SaveCertificate(&renewed); err != nil {
+		t.Fatalf("save renewed certificate: %v", err)
+	}
+
+	got, err := store.GetCertificate("demo", "cc", "")
+	if err != nil {
+		t.Fatalf("get certificate: %v", err)
+	}
+	if *got != renewed {
+		t.Fatalf("expected %+v, got %+v", renewed, *got)
+	}
+}
+
+func TestMonitorDefinitionsCRUD(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	first := &MonitorDefinition{Provider: "demo", Service: "cc", Definition: "provider: demo", APIKey: "enc:v1:a", CreatedAt: 100, UpdatedAt: 100}
+	second := &MonitorDefinition{Provider: "demo", Service: "cc", Channel: "vip", Definition: "provider: demo", CreatedAt: 110, UpdatedAt: 110}
+	for _, m := range []*MonitorDefinition{first, second} {
+		if err := store.SaveMonitor(m); err != nil {
+			t.Fatalf("save monitor: %v", err)
+		}
+	}
+
+	// 覆盖时保留 id 和创建时间
+	updated := *first
+	updated.Definition, updated.APIKey, updated.CreatedAt, updated.UpdatedAt = "provider: demo\nservice: cc", "enc:v1:b", 200, 200
+	if err := store.SaveMonitor(&updated); err != nil {
+		t.Fatalf("update monitor: %v", err)
+	}
+
+	monitors, err := store.GetMonitors()
+	if err != nil || len(monitors) != 2 {
+		t.Fatalf("expected 2 monitors, got %d (err=%v)", len(monitors), err)
+	}
+	got := monitors[0]
+	if got.Channel != "" || got.Definition != updated.Definition || got.APIKey != "enc:v1:b" || got.CreatedAt != 100 || got.UpdatedAt != 200 {
+		t.Fatalf("unexpected updated monitor: %+v", got)
+	}
+
+	if found, err := store.DeleteMonitor("demo", "cc", "vip"); err != nil || !found {
+		t.Fatalf("delete monitor: found=%v err=%v", found, err)
+	}
+	if found, _ := store.DeleteMonitor("demo", "cc", "vip"); found {
+		t.Fatal("expected deleted monitor to be gone")
+	}
+}
+
+func TestSaveRecordsIsAtomic(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	batch := []*ProbeRecord{
+		{Provider: "demo", Service: "cc", Status: 1, Latency: 100, Timestamp: 1000},
+		{Provider: "demo", Service: "cc", Status: 0, Latency: 200, Timestamp: 1060},
+	}
+	if err := store.SaveRecords(batch); err != nil {
+		t.Fatalf("save records: %v", err)
+	}
+	if batch[0].ID == 0 || batch[1].ID <= batch[0].ID {
+		t.Fatalf("expected ids to be assigned: %d, %d", batch[0].ID, batch[1].ID)
+	}
+
+	// 聚合表写入失败时整批回滚，不留下部分记录
+	if _, err := store.db.Exec("DROP TABLE probe_rollup_daily"); err != nil {
+		t.Fatalf("drop rollup table: %v", err)
+	}
+	if err := store.SaveRecords([]*ProbeRecord{
+		{Provider: "demo", Service: "cc", Status: 1, Latency: 100, Timestamp: 1120},
+		{Provider: "demo", Service: "cc", Status: 1, Latency: 100, Timestamp: 1180},
+	}); err == nil {
+		t.Fatal("expected save to fail without the rollup table")
+	}
+	history, err := store.GetHistory("demo", "cc", "", "", time.Unix(0, 0), time.Now())
+	if err != nil || len(history) != 2 {
+		t.Fatalf("expected the failed batch to be rolled back, got %d records (err=%v)", len(history), err)
+	}
+}
+
+func TestSaveMonitorsIsAtomic(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	if _, err := store.db.Exec(`CREATE TRIGGER reject_bad BEFORE INSERT ON monitors WHEN NEW.provider = 'bad'
+		BEGIN SELECT RAISE(ABORT, 'rejected'); END`); err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+
+	// 任一监控项写入失败时整批回滚
+	if err := store.SaveMonitors([]*MonitorDefinition{
+		{Provider: "demo", Service: "cc", Definition: "provider: demo"},
+		{Provider: "bad", Service: "cc", Definition: "provider: bad"},
+	}); err == nil {
+		t.Fatal("expected save to fail")
+	}
+	if monitors, err := store.GetMonitors(); err != nil || len(monitors) != 0 {
+		t.Fatalf("expected the failed batch to be rolled back, got %d monitors (err=%v)", len(monitors), err)
+	}
+
+	if err := store.SaveMonitors([]*MonitorDefinition{
+		{Provider: "demo", Service: "cc", Definition: "provider: demo"},
+		{Provider: "demo", Service: "cc", Channel: "vip", Definition: "provider: demo"},
+	}); err != nil {
+		t.Fatalf("save monitors: %v", err)
+	}
+	if monitors, err := store.GetMonitors(); err != nil || len(monitors) != 2 {
+		t.Fatalf("expected 2 monitors, got %d (err=%v)", len(monitors), err)
+	}
+}
+
+func TestGetHistoryRange(t *testing.T) {
+	t.Parallel()
+
+	store := NewTestSQLite(t)
+	for _, ts := range []int64{1000, 1060, 1120, 1180} {
+		if err := store.SaveRecord(&ProbeRecord{Provider: "demo", Service: "cc", Status: 1, Latency: 100, Timestamp: ts}); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+	}
+
+	// 区间左闭右开
+	history, err := store.GetHistory("demo", "cc", "", "", time.Unix(1060, 0), time.Unix(1180, 0))
+	if err != nil || len(history) != 2 || history[0].Timestamp != 1060 || history[1].Timestamp != 1120 {
+		t.Fatalf("unexpected history in [1060, 1180): %+v (err=%v)", history, err)
+	}
+}
@@ -6,15 +6,25 @@ import "time"
 type SubStatus string
 
 const (
-	SubStatusNone            SubStatus = ""                   // 默认值（绿色或灰色无需细分）
-	SubStatusSlowLatency     SubStatus = "slow_latency"       // 响应慢
-	SubStatusRateLimit       SubStatus = "rate_limit"         // 限流（429）
-	SubStatusServerError     SubStatus = "server_error"       // 服务器错误（5xx）
-	SubStatusClientError     SubStatus = "client_error"       // 客户端错误（4xx）
-	SubStatusAuthError       SubStatus = "auth_error"         // 认证/权限失败（401/403）
-	SubStatusInvalidRequest  SubStatus = "invalid_request"    // 请求参数错误（400）
-	SubStatusNetworkError    SubStatus = "network_error"      // 网络错误（连接失败）
-	SubStatusContentMismatch SubStatus = "content_mismatch"   // 内容校验失败
+	SubStatusNone             SubStatus = ""                  // 默认值（绿色或灰色无需细分）
+	SubStatusSlowLatency      SubStatus = "slow_latency"      // 响应慢
+	SubStatusRateLimit        SubStatus = "rate_limit"        // 限流（429）
+	SubStatusServerError      SubStatus = "server_error"      // 服务器错误（5xx）
+	SubStatusClientError      SubStatus = "client_error"      // 客户端错误（4xx）
+	SubStatusAuthError        SubStatus = "auth_error"        // 认证/权限失败（401/403）
+	SubStatusInvalidRequest   SubStatus = "invalid_request"   // 请求参数错误（400）
+	SubStatusNetworkError     SubStatus = "network_error"     // 网络错误（连接失败）
+	SubStatusContentMismatch  SubStatus = "content_mismatch"  // 内容校验失败
+	SubStatusStreamError      SubStatus = "stream_error"      // 流式响应异常（非 SSE 或未正常结束）
+	SubStatusUnexpectedStatus SubStatus = "unexpected_status" // 状态码不在断言白名单内
+	SubStatusHeaderMismatch   SubStatus = "header_mismatch"   // 响应头断言失败
+	SubStatusFlaky            SubStatus = "flaky"             // 首次失败、重试后成功（需开启 retry.flaky_as_degraded）
+
+	// TLS 证书相关
+	SubStatusCertExpiring         SubStatus = "cert_expiring"          // 证书即将过期（黄色预警）
+	SubStatusCertExpired          SubStatus = "cert_expired"           // 证书已过期或尚未生效
+	SubStatusCertHostnameMismatch SubStatus = "cert_hostname_mismatch" // 证书域名不匹配
+	SubStatusCertUnknownAuthority SubStatus = "cert_unknown_authority" // 证书签发者不受信任
 )
 
 // ProbeRecord 探测记录
@@ -23,20 +33,52 @@ type ProbeRecord struct {
 	Provider  string
 	Service   string
 	Channel   string    // 业务通道标识
-	Status    int       // 1=绿, 0=红, 2=黄
-	SubStatus SubStatus // 细分状态（黄色/红色原因）
+	Status    int       // 1=绿, 0=红, 2=黄, 4=维护中
+	SubStatus SubStatus // 细分状态（黄色/红色原因，维护中时为探测的原始细分状态）
 	Latency   int       // ms
 	Timestamp int64     // Unix时间戳
+
+	FirstByteLatency  int // 首字节延迟（ms，从发出请求到收到首个响应字节）
+	FirstTokenLatency int // 首 token 延迟（ms，仅流式探测）
+	StreamDuration    int // 流式响应总耗时（ms，仅流式探测）
+
+	// 网络阶段耗时（ms，复用已有连接时 DNS/连接/TLS 为 0）
+	DNSDuration      int // DNS 解析
+	ConnectDuration  int // TCP 连接
+	TLSDuration      int // TLS 握手
+	BodyReadDuration int // 读取响应体（从收到响应头到读取完毕）
+
+	// 失败诊断信息（仅非绿色探测记录，已脱敏）
+	HTTPStatus      int    // HTTP 状态码（未收到响应时为 0）
+	ErrorMessage    string // 错误信息
+	ResponseSnippet string // 响应体开头片段
+
+	Attempts int // 本次探测的尝试次数（含重试，1 表示未重试）
+
+	Region string // 探测地域（本地探测为配置的 region，探测节点上报时为节点所属地域）
 }
 
 // TimePoint 时间轴数据点（用于前端展示）
 type TimePoint struct {
-	Time         string       `json:"time"`          // 格式化时间标签（如 "15:04" 或 "2006-01-02"）
-	Timestamp    int64        `json:"timestamp"`     // Unix 时间戳（秒），用于前端精确时间计算
-	Status       int          `json:"status"`        // 状态码：1=绿，0=红，2=黄，-1=缺失（bucket内最后一条记录）
-	Latency      int          `json:"latency"`       // 平均延迟（毫秒）
-	Availability float64      `json:"availability"`  // 可用率百分比（0-100），缺失时为 -1
-	StatusCounts StatusCounts `json:"status_counts"` // 各状态计数
+	Time         string            `json:"time"`                  // 格式化时间标签（如 "15:04" 或 "2006-01-02"）
+	Timestamp    int64             `json:"timestamp"`             // Unix 时间戳（秒），用于前端精确时间计算
+	Status       int               `json:"status"`                // 状态码：1=绿，0=红，2=黄，4=维护中，-1=缺失（bucket内最后一条记录）
+	Latency      int               `json:"latency"`               // 平均延迟（毫秒）
+	LatencyMin   int               `json:"latency_min"`           // 最小延迟（毫秒）
+	LatencyMax   int               `json:"latency_max"`           // 最大延迟（毫秒）
+	Availability float64           `json:"availability"`          // 可用率百分比（0-100，不含维护期间的探测），缺失或全部处于维护时为 -1
+	StatusCounts StatusCounts      `json:"status_counts"`         // 各状态计数
+	Maintenance  []MaintenanceNote `json:"maintenance,omitempty"` // 与 bucket 有交集的维护时段
+
+	FirstByteLatency  int `json:"first_byte_latency"`  // 平均首字节延迟（毫秒），无数据时为 0
+	FirstTokenLatency int `json:"first_token_latency"` // 平均首 token 延迟（毫秒，仅流式探测），无数据时为 0
+	StreamDuration    int `json:"stream_duration"`     // 平均流式总耗时（毫秒，仅流式探测），无数据时为 0
+
+	// 平均网络阶段耗时（毫秒，仅统计新建连接的探测），无数据时为 0
+	DNSDuration      int `json:"dns_duration"`       // DNS 解析
+	ConnectDuration  int `json:"connect_duration"`   // TCP 连接
+	TLSDuration      int `json:"tls_duration"`       // TLS 握手
+	BodyReadDuration int `json:"body_read_duration"` // 读取响应体
 }
 
 // StatusCounts 记录一个时间块内各状态出现次数
@@ -45,18 +87,29 @@ type StatusCounts struct {
 	Degraded    int `json:"degraded"`    // 黄色（波动/降级）次数
 	Unavailable int `json:"unavailable"` // 红色（不可用）次数
 	Missing     int `json:"missing"`     // 灰色（无数据/未配置）次数
+	Maintenance int `json:"maintenance"` // 维护期间的探测次数（不计入可用率）
 
 	// 细分统计（黄色波动细分）
 	SlowLatency int `json:"slow_latency"` // 黄色-响应慢次数
 	RateLimit   int `json:"rate_limit"`   // 黄色-限流次数
+	Flaky       int `json:"flaky"`        // 黄色-重试后成功次数
 
 	// 细分统计（红色不可用细分）
-	ServerError     int `json:"server_error"`     // 红色-服务器错误次数（5xx）
-	ClientError     int `json:"client_error"`     // 红色-客户端错误次数（4xx）
-	AuthError       int `json:"auth_error"`       // 红色-认证失败次数（401/403）
-	InvalidRequest  int `json:"invalid_request"`  // 红色-请求参数错误次数（400）
-	NetworkError    int `json:"network_error"`    // 红色-连接失败次数
-	ContentMismatch int `json:"content_mismatch"` // 红色-内容校验失败次数
+	ServerError      int `json:"server_error"`      // 红色-服务器错误次数（5xx）
+	ClientError      int `json:"client_error"`      // 红色-客户端错误次数（4xx）
+	AuthError        int `json:"auth_error"`        // 红色-认证失败次数（401/403）
+	InvalidRequest   int `json:"invalid_request"`   // 红色-请求参数错误次数（400）
+	NetworkError     int `json:"network_error"`     // 红色-连接失败次数
+	ContentMismatch  int `json:"content_mismatch"`  // 红色-内容校验失败次数
+	StreamError      int `json:"stream_error"`      // 红色-流式响应异常次数
+	UnexpectedStatus int `json:"unexpected_status"` // 红色-状态码断言失败次数
+	HeaderMismatch   int `json:"header_mismatch"`   // 红色-响应头断言失败次数
+
+	// TLS 证书细分统计
+	CertExpiring         int `json:"cert_expiring"`          // 黄色-证书即将过期次数
+	CertExpired          int `json:"cert_expired"`           // 红色-证书过期次数
+	CertHostnameMismatch int `json:"cert_hostname_mismatch"` // 红色-证书域名不匹配次数
+	CertUnknownAuthority int `json:"cert_unknown_authority"` // 红色-证书签发者不受信任次数
 }
 
 // ChannelMigrationMapping 表示 provider/service 对应的目标 channel
@@ -74,18 +127,81 @@ type Storage interface {
 	// Close 关闭存储
 	Close() error
 
-	// SaveRecord 保存探测记录
+	// SaveRecord 保存探测记录（同时累加到小时/天聚合表）
 	SaveRecord(record *ProbeRecord) error
 
-	// GetLatest 获取最新记录
-	GetLatest(provider, service, channel string) (*ProbeRecord, error)
+	// SaveRecords 在同一事务中保存多条探测记录（任一失败时全部不写入，用于探测节点批量上报）
+	SaveRecords(records []*ProbeRecord) error
+
+	// GetLatest 获取最新记录（region 为空时不区分地域）
+	GetLatest(provider, service, channel, region string) (*ProbeRecord, error)
+
+	// GetLatestByRegion 获取 since 之后各地域的最新记录（按地域排序）
+	GetLatestByRegion(provider, service, channel string, since time.Time) ([]*ProbeRecord, error)
 
-	// GetHistory 获取历史记录（时间范围）
-	GetHistory(provider, service, channel string, since time.Time) ([]*ProbeRecord, error)
+	// GetHistory 获取 [since, until) 内的历史记录（region 为空时包含全部地域）
+	GetHistory(provider, service, channel, region string, since, until time.Time) ([]*ProbeRecord, error)
 
-	// CleanOldRecords 清理旧记录（保留最近N天）
-	CleanOldRecords(days int) error
+	// GetRecent 获取最近 limit 条探测记录（按时间升序，region 为空时包含全部地域）
+	GetRecent(provider, service, channel, region string, limit int) ([]*ProbeRecord, error)
+
+	// GetFailures 获取非绿色探测记录及诊断信息（时间位于 [since, until)，按时间倒序，最多 limit 条，region 为空时包含全部地域）
+	GetFailures(provider, service, channel, region string, since, until time.Time, limit int) ([]*ProbeRecord, error)
+
+	// GetRollups 获取聚合记录（bucket 起始时间位于 [since, until)，按时间升序，聚合数据不区分地域）
+	GetRollups(provider, service, channel string, granularity RollupGranularity, since, until time.Time) ([]*RollupRecord, error)
+
+	// CleanOldRecords 清理旧记录（保留最近N天），返回删除的行数
+	CleanOldRecords(days int) (int64, error)
+
+	// CleanOldRollups 清理指定粒度的旧聚合数据（保留最近N天），返回删除的行数
+	CleanOldRollups(granularity RollupGranularity, days int) (int64, error)
+
+	// Optimize 清理后的数据库维护（VACUUM 回收空间、ANALYZE 更新统计信息）
+	Optimize(vacuum, analyze bool) error
 
 	// MigrateChannelData 将 channel 为空的历史记录迁移到最新配置
 	MigrateChannelData(mappings []ChannelMigrationMapping) error
+
+	// SaveCertificate 保存监控项最近一次观察到的 TLS 证书信息（覆盖旧值）
+	SaveCertificate(cert *Certificate) error
+
+	// GetCertificate 获取监控项的 TLS 证书信息（无记录时返回 nil）
+	GetCertificate(provider, service, channel string) (*Certificate, error)
+
+	// AcquireLease 获取或续期租约（租约不存在、已过期或已由 holder 持有时成功），有效期为 now+ttl
+	AcquireLease(name, holder string, now time.Time, ttl time.Duration) (bool, error)
+
+	// ReleaseLease 释放 holder 持有的租约
+	ReleaseLease(name, holder string) error
+
+	// GetLease 查询租约（不存在时返回 nil）
+	GetLease(name string) (*Lease, error)
+
+	// SaveIncident 保存故障事件（ID 为 0 时新建并回填 ID，否则更新）
+	SaveIncident(incident *Incident) error
+
+	// GetIncidents 按条件查询故障事件（按开始时间倒序）
+	GetIncidents(filter IncidentFilter) ([]*Incident, error)
+
+	// SaveMaintenanceWindow 新建维护窗口（回填 ID）
+	SaveMaintenanceWindow(w *MaintenanceWindow) error
+
+	// GetMaintenanceWindows 查询全部维护窗口（按 ID 升序）
+	GetMaintenanceWindows() ([]*MaintenanceWindow, error)
+
+	// DeleteMaintenanceWindow 删除维护窗口，返回是否存在
+	DeleteMaintenanceWindow(id int64) (bool, error)
+
+	// SaveMonitor 新建或覆盖监控项定义（按 provider/service/channel 匹配）
+	SaveMonitor(m *MonitorDefinition) error
+
+	// SaveMonitors 在同一事务中新建或覆盖多个监控项定义（任一失败时全部不写入，用于从配置文件导入）
+	SaveMonitors(monitors []*MonitorDefinition) error
+
+	// GetMonitors 查询全部监控项定义（按 ID 升序）
+	GetMonitors() ([]*MonitorDefinition, error)
+
+	// DeleteMonitor 删除监控项定义，返回是否存在
+	DeleteMonitor(provider, service, channel string) (bool, error)
 }
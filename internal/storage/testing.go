@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// NewTestSQLite 在测试临时目录中创建并初始化 SQLite 存储（测试结束时自动关闭），供各包的测试共用
+func NewTestSQLite(t testing.TB) *SQLiteStorage {
+	t.Helper()
+
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "monitor.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Init(); err != nil {
+		t.Fatalf("init sqlite: %v", err)
+	}
+	return store
+}